@@ -69,4 +69,10 @@ Create image name with tag
 {{- printf "%s:%s" .Values.image.repository $tag }}
 {{- end }}
 
- 
\ No newline at end of file
+ 
+{{/*
+Render a labels map as a TOML inline table
+*/}}
+{{- define "cosmos-wallets-exporter.labelsTable" -}}
+{ {{- range $i, $key := keys . | sortAlpha }}{{ if $i }},{{ end }} {{ $key }} = {{ index $ $key | quote }}{{ end }} }
+{{- end }}
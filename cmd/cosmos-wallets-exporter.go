@@ -20,17 +20,34 @@ func (fs *OsFS) ReadFile(name string) ([]byte, error) {
 	return os.ReadFile(name)
 }
 
-func ExecuteMain(configPath string) {
+func (fs *OsFS) ReadDir(name string) ([]os.DirEntry, error) {
+	return os.ReadDir(name)
+}
+
+func GetConfigFormat(format string) configPkg.Format {
+	if format == "" {
+		return ""
+	}
+
+	configFormat, err := configPkg.ParseFormat(format)
+	if err != nil {
+		logger.GetDefaultLogger().Panic().Err(err).Msg("Invalid config format!")
+	}
+
+	return configFormat
+}
+
+func ExecuteMain(configPaths []string, configFormat string) {
 	filesystem := &OsFS{}
 
-	app := pkg.NewApp(filesystem, configPath, version)
+	app := pkg.NewApp(filesystem, configPaths, GetConfigFormat(configFormat), version)
 	app.Start()
 }
 
-func ExecuteValidateConfig(configPath string) {
+func ExecuteValidateConfig(configPaths []string, configFormat string) {
 	filesystem := &OsFS{}
 
-	config, err := configPkg.GetConfig(configPath, filesystem)
+	config, err := configPkg.GetConfig(configPaths, GetConfigFormat(configFormat), filesystem)
 	if err != nil {
 		logger.GetDefaultLogger().Panic().Err(err).Msg("Could not load config!")
 	}
@@ -42,15 +59,61 @@ func ExecuteValidateConfig(configPath string) {
 	logger.GetDefaultLogger().Info().Msg("Provided config is valid.")
 }
 
+func ExecuteConvertConfig(inputPath string, inputFormat string, outputPath string, outputFormat string) {
+	from := GetConfigFormat(inputFormat)
+	if from == "" {
+		from = configPkg.GetFormat(inputPath)
+	}
+
+	to := GetConfigFormat(outputFormat)
+	if to == "" && outputPath != "" {
+		to = configPkg.GetFormat(outputPath)
+	}
+
+	if to == "" {
+		logger.GetDefaultLogger().Panic().Msg("Output format should be specified when writing to stdout!")
+	}
+
+	content, err := os.ReadFile(inputPath)
+	if err != nil {
+		logger.GetDefaultLogger().Panic().Err(err).Msg("Could not read config!")
+	}
+
+	converted, err := configPkg.Convert(content, from, to)
+	if err != nil {
+		logger.GetDefaultLogger().Panic().Err(err).Msg("Could not convert config!")
+	}
+
+	if outputPath == "" {
+		_, _ = os.Stdout.Write(converted)
+		return
+	}
+
+	if err := os.WriteFile(outputPath, converted, 0o600); err != nil {
+		logger.GetDefaultLogger().Panic().Err(err).Msg("Could not write config!")
+	}
+
+	logger.GetDefaultLogger().Info().
+		Str("input", inputPath).
+		Str("output", outputPath).
+		Msg("Config converted.")
+}
+
 func main() {
-	var ConfigPath string
+	var (
+		ConfigPaths  []string
+		ConfigFormat string
+		InputPath    string
+		OutputPath   string
+		OutputFormat string
+	)
 
 	rootCmd := &cobra.Command{
 		Use:     "cosmos-wallets-exporter --config [config path]",
 		Long:    "A Prometheus exporter that returns wallets balances on cosmos-sdk chains.",
 		Version: version,
 		Run: func(cmd *cobra.Command, args []string) {
-			ExecuteMain(ConfigPath)
+			ExecuteMain(ConfigPaths, ConfigFormat)
 		},
 	}
 
@@ -59,17 +122,51 @@ func main() {
 		Long:    "Validate config.",
 		Version: version,
 		Run: func(cmd *cobra.Command, args []string) {
-			ExecuteValidateConfig(ConfigPath)
+			ExecuteValidateConfig(ConfigPaths, ConfigFormat)
 		},
 	}
 
-	rootCmd.PersistentFlags().StringVar(&ConfigPath, "config", "", "Config file path")
-	_ = rootCmd.MarkPersistentFlagRequired("config")
+	convertConfigCmd := &cobra.Command{
+		Use:     "convert-config --config [config path] --output [output path]",
+		Long:    "Convert config between TOML, YAML and JSON formats.",
+		Version: version,
+		Run: func(cmd *cobra.Command, args []string) {
+			ExecuteConvertConfig(InputPath, ConfigFormat, OutputPath, OutputFormat)
+		},
+	}
+
+	for _, command := range []*cobra.Command{rootCmd, validateConfigCmd} {
+		command.PersistentFlags().StringArrayVar(
+			&ConfigPaths,
+			"config",
+			[]string{},
+			"Config file or directory path, can be specified multiple times",
+		)
+		_ = command.MarkPersistentFlagRequired("config")
+	}
+
+	convertConfigCmd.PersistentFlags().StringVar(&InputPath, "config", "", "Config file path")
+	_ = convertConfigCmd.MarkPersistentFlagRequired("config")
+
+	for _, command := range []*cobra.Command{rootCmd, validateConfigCmd, convertConfigCmd} {
+		command.PersistentFlags().StringVar(
+			&ConfigFormat,
+			"config-format",
+			"",
+			"Config format (toml, yaml or json), detected by file extension if not set",
+		)
+	}
 
-	validateConfigCmd.PersistentFlags().StringVar(&ConfigPath, "config", "", "Config file path")
-	_ = validateConfigCmd.MarkPersistentFlagRequired("config")
+	convertConfigCmd.PersistentFlags().StringVar(&OutputPath, "output", "", "Output file path, stdout if not set")
+	convertConfigCmd.PersistentFlags().StringVar(
+		&OutputFormat,
+		"format",
+		"",
+		"Output format (toml, yaml or json), detected by output file extension if not set",
+	)
 
 	rootCmd.AddCommand(validateConfigCmd)
+	rootCmd.AddCommand(convertConfigCmd)
 
 	if err := rootCmd.Execute(); err != nil {
 		logger.GetDefaultLogger().Panic().Err(err).Msg("Could not start application")
@@ -2,6 +2,7 @@ package main
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -51,6 +52,60 @@ func TestValidateConfigValid(t *testing.T) {
 	_ = t // Acknowledge unused parameter
 }
 
+//nolint:paralleltest // disabled
+func TestValidateConfigDirectoryValid(t *testing.T) {
+	os.Args = []string{
+		"cmd",
+		"validate-config",
+		"--config",
+		"../assets/config-split-extra.toml",
+		"--config",
+		"../assets/config-split",
+	}
+	main()
+}
+
+//nolint:paralleltest // disabled
+func TestValidateConfigYAMLValid(t *testing.T) {
+	os.Args = []string{"cmd", "validate-config", "--config", "../assets/config-valid.yaml"}
+	main()
+}
+
+//nolint:paralleltest // disabled
+func TestValidateConfigInvalidFormat(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			require.Fail(t, "Expected to have a panic here!")
+		}
+	}()
+
+	os.Args = []string{"cmd", "validate-config", "--config", "../assets/config-valid.toml", "--config-format", "xml"}
+	main()
+}
+
+//nolint:paralleltest // disabled
+func TestConvertConfig(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "config.yaml")
+
+	os.Args = []string{"cmd", "convert-config", "--config", "../assets/config-valid.toml", "--output", output}
+	main()
+
+	os.Args = []string{"cmd", "validate-config", "--config", output}
+	main()
+}
+
+//nolint:paralleltest // disabled
+func TestConvertConfigNoOutputFormat(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			require.Fail(t, "Expected to have a panic here!")
+		}
+	}()
+
+	os.Args = []string{"cmd", "convert-config", "--config", "../assets/config-valid.toml"}
+	main()
+}
+
 //nolint:paralleltest // disabled
 func TestStartNoConfigProvided(t *testing.T) {
 	defer func() {
@@ -0,0 +1,192 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"main/pkg/history"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const (
+	formatJSON = "json"
+	formatCSV  = "csv"
+)
+
+var csvHeader = []string{"time", "chain", "address", "name", "group", "denom", "amount"}
+
+// API serves the balances history recorded by the exporter, for reports that need
+// data older than what Prometheus keeps.
+type API struct {
+	History *history.Store
+	Logger  zerolog.Logger
+}
+
+func NewAPI(history *history.Store, logger zerolog.Logger) *API {
+	return &API{
+		History: history,
+		Logger:  logger.With().Str("component", "api").Logger(),
+	}
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+type balancesResponse struct {
+	Balances []history.Sample `json:"balances"`
+}
+
+// Balances returns the latest recorded balance of each wallet and denom,
+// optionally filtered by chain and address.
+func (a *API) Balances(w http.ResponseWriter, r *http.Request) {
+	format, err := getFormat(r)
+	if err != nil {
+		a.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	samples, err := a.History.Latest(getFilter(r))
+	if err != nil {
+		a.Logger.Error().Err(err).Msg("Error getting latest balances")
+		a.writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	a.writeSamples(w, format, "balances", samples)
+}
+
+// BalancesHistory returns the recorded balances within [from, to], optionally filtered by chain and address.
+// Both from and to accept either RFC3339 timestamps or dates (like 2024-01-31). A date in from
+// means the start of the day and a date in to means the end of it, both in UTC, so the whole day
+// is included. By default all the samples up to now are returned.
+func (a *API) BalancesHistory(w http.ResponseWriter, r *http.Request) {
+	format, err := getFormat(r)
+	if err != nil {
+		a.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	query := r.URL.Query()
+
+	from, err := parseTime(query.Get("from"), time.Unix(0, 0), false)
+	if err != nil {
+		a.writeError(w, http.StatusBadRequest, fmt.Errorf("invalid from: %s", err))
+		return
+	}
+
+	to, err := parseTime(query.Get("to"), time.Now(), true)
+	if err != nil {
+		a.writeError(w, http.StatusBadRequest, fmt.Errorf("invalid to: %s", err))
+		return
+	}
+
+	if to.Before(from) {
+		a.writeError(w, http.StatusBadRequest, errors.New("to should not be before from"))
+		return
+	}
+
+	samples, err := a.History.QueryAll(getFilter(r), from, to)
+	if err != nil {
+		a.Logger.Error().Err(err).Msg("Error querying balances history")
+		a.writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	a.writeSamples(w, format, "balances-history", samples)
+}
+
+func (a *API) writeSamples(w http.ResponseWriter, format string, filename string, samples []history.Sample) {
+	if format == formatJSON {
+		a.writeJSON(w, http.StatusOK, balancesResponse{Balances: samples})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+".csv"))
+
+	writer := csv.NewWriter(w)
+	_ = writer.Write(csvHeader)
+
+	for _, sample := range samples {
+		_ = writer.Write([]string{
+			sample.Time.UTC().Format(time.RFC3339),
+			sample.Chain,
+			sample.Address,
+			sample.Name,
+			sample.Group,
+			sample.Denom,
+			strconv.FormatFloat(sample.Amount, 'f', -1, 64),
+		})
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		a.Logger.Error().Err(err).Msg("Error writing CSV response")
+	}
+}
+
+func (a *API) writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		a.Logger.Error().Err(err).Msg("Error writing JSON response")
+	}
+}
+
+func (a *API) writeError(w http.ResponseWriter, status int, err error) {
+	a.writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+func getFormat(r *http.Request) (string, error) {
+	format := r.URL.Query().Get("format")
+
+	switch format {
+	case "", formatJSON:
+		return formatJSON, nil
+	case formatCSV:
+		return formatCSV, nil
+	default:
+		return "", fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
+// getFilter returns the chain and address filter from query params. Filtering by chain
+// is cheaper, as only the samples of that chain are read then.
+func getFilter(r *http.Request) history.Filter {
+	query := r.URL.Query()
+
+	return history.Filter{
+		Chain:   query.Get("chain"),
+		Address: query.Get("address"),
+	}
+}
+
+// parseTime parses either an RFC3339 timestamp or a date. With endOfDay, a date means
+// the last moment of that day, as the range is inclusive and the next day should not be included.
+func parseTime(value string, fallback time.Time, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return fallback, nil
+	}
+
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed, nil
+	}
+
+	parsed, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return parsed, err
+	}
+
+	if endOfDay {
+		return parsed.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+	}
+
+	return parsed, nil
+}
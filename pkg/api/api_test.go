@@ -0,0 +1,196 @@
+package api
+
+import (
+	"encoding/json"
+	"main/pkg/history"
+	loggerPkg "main/pkg/logger"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAPI(t *testing.T) *API {
+	t.Helper()
+
+	store, err := history.NewStore(filepath.Join(t.TempDir(), "history.db"), 10*365*24*time.Hour, time.Minute)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = store.Close()
+	})
+
+	err = store.Record([]history.Sample{
+		{
+			Time:    time.Date(2024, 1, 30, 0, 0, 0, 0, time.UTC),
+			Chain:   "chain",
+			Address: "address",
+			Name:    "name",
+			Group:   "group",
+			Denom:   "atom",
+			Amount:  10,
+		},
+		{
+			Time:    time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+			Chain:   "chain",
+			Address: "address",
+			Name:    "name",
+			Group:   "group",
+			Denom:   "atom",
+			Amount:  8.5,
+		},
+		{
+			Time:    time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+			Chain:   "chain",
+			Address: "address2",
+			Denom:   "atom",
+			Amount:  100,
+		},
+	})
+	require.NoError(t, err)
+
+	return NewAPI(store, *loggerPkg.GetNopLogger())
+}
+
+func TestAPIBalancesJSON(t *testing.T) {
+	t.Parallel()
+
+	api := newTestAPI(t)
+
+	recorder := httptest.NewRecorder()
+	api.Balances(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/balances", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+	var response balancesResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	require.Len(t, response.Balances, 2)
+	assert.Equal(t, "address", response.Balances[0].Address)
+	assert.Equal(t, "name", response.Balances[0].Name)
+	assert.Equal(t, "group", response.Balances[0].Group)
+	assert.InDelta(t, 8.5, response.Balances[0].Amount, 0.001)
+	assert.Equal(t, "address2", response.Balances[1].Address)
+}
+
+func TestAPIBalancesFiltered(t *testing.T) {
+	t.Parallel()
+
+	api := newTestAPI(t)
+
+	recorder := httptest.NewRecorder()
+	api.Balances(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/balances?chain=chain&address=address2", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var response balancesResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	require.Len(t, response.Balances, 1)
+	assert.InDelta(t, 100, response.Balances[0].Amount, 0.001)
+
+	recorder = httptest.NewRecorder()
+	api.Balances(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/balances?chain=other", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	assert.Empty(t, response.Balances)
+}
+
+func TestAPIBalancesInvalidFormat(t *testing.T) {
+	t.Parallel()
+
+	api := newTestAPI(t)
+
+	recorder := httptest.NewRecorder()
+	api.Balances(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/balances?format=xml", nil))
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "unsupported format")
+}
+
+func TestAPIBalancesHistoryCSV(t *testing.T) {
+	t.Parallel()
+
+	api := newTestAPI(t)
+
+	recorder := httptest.NewRecorder()
+	api.BalancesHistory(recorder, httptest.NewRequest(
+		http.MethodGet,
+		"/api/v1/balances/history?address=address&from=2024-01-01&to=2024-02-01T00:00:00Z&format=csv",
+		nil,
+	))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "text/csv", recorder.Header().Get("Content-Type"))
+
+	lines := strings.Split(strings.TrimSpace(recorder.Body.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, "time,chain,address,name,group,denom,amount", lines[0])
+	assert.Equal(t, "2024-01-30T00:00:00Z,chain,address,name,group,atom,10", lines[1])
+	assert.Equal(t, "2024-01-31T00:00:00Z,chain,address,name,group,atom,8.5", lines[2])
+}
+
+func TestAPIBalancesHistoryRange(t *testing.T) {
+	t.Parallel()
+
+	api := newTestAPI(t)
+
+	recorder := httptest.NewRecorder()
+	api.BalancesHistory(recorder, httptest.NewRequest(
+		http.MethodGet,
+		"/api/v1/balances/history?from=2024-01-31",
+		nil,
+	))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var response balancesResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	require.Len(t, response.Balances, 2)
+	assert.InDelta(t, 8.5, response.Balances[0].Amount, 0.001)
+	assert.InDelta(t, 100, response.Balances[1].Amount, 0.001)
+}
+
+func TestAPIBalancesHistoryToDate(t *testing.T) {
+	t.Parallel()
+
+	api := newTestAPI(t)
+	require.NoError(t, api.History.Record([]history.Sample{{
+		Time:    time.Date(2024, 1, 30, 12, 0, 0, 0, time.UTC),
+		Chain:   "chain",
+		Address: "address",
+		Denom:   "atom",
+		Amount:  9,
+	}}))
+
+	// a date in to includes the whole day, but not the next one
+	recorder := httptest.NewRecorder()
+	api.BalancesHistory(recorder, httptest.NewRequest(
+		http.MethodGet,
+		"/api/v1/balances/history?address=address&from=2024-01-30&to=2024-01-30",
+		nil,
+	))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var response balancesResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	require.Len(t, response.Balances, 2)
+	assert.InDelta(t, 10, response.Balances[0].Amount, 0.001)
+	assert.InDelta(t, 9, response.Balances[1].Amount, 0.001)
+}
+
+func TestAPIBalancesHistoryInvalidParams(t *testing.T) {
+	t.Parallel()
+
+	api := newTestAPI(t)
+
+	for query, expected := range map[string]string{
+		"from=invalid":                  "invalid from",
+		"to=invalid":                    "invalid to",
+		"from=2024-02-01&to=2024-01-01": "to should not be before from",
+		"format=xml":                    "unsupported format",
+	} {
+		recorder := httptest.NewRecorder()
+		api.BalancesHistory(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/balances/history?"+query, nil))
+		require.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), expected)
+	}
+}
@@ -2,14 +2,30 @@ package pkg
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	apiPkg "main/pkg/api"
 	coingeckoPkg "main/pkg/coingecko"
 	"main/pkg/config"
+	"main/pkg/constants"
+	"main/pkg/dashboard"
 	"main/pkg/fs"
+	"main/pkg/history"
 	"main/pkg/logger"
+	metricsPkg "main/pkg/metrics"
+	"main/pkg/probe"
 	queriersPkg "main/pkg/queriers"
+	"main/pkg/sources"
+	"main/pkg/state"
+	"main/pkg/status"
+	"main/pkg/tendermint"
 	"main/pkg/tracing"
+	"main/pkg/transfers"
 	"main/pkg/types"
 	"net/http"
+	"net/url"
+	"strings"
 	"sync"
 	"time"
 
@@ -24,16 +40,35 @@ import (
 	"go.opentelemetry.io/otel/trace"
 )
 
+const (
+	stateKeyHeights = "heights"
+
+	// how often the state is written during scrapes at most, it's always written on shutdown
+	stateSaveInterval = time.Minute
+)
+
 type App struct {
-	Config   *config.Config
-	Logger   zerolog.Logger
-	Queriers []types.Querier
-	Server   *http.Server
-	Tracer   trace.Tracer
+	Config    *config.Config
+	Coingecko *coingeckoPkg.Coingecko
+	Dashboard *dashboard.Dashboard
+	Prober    *probe.Prober
+	Logger    zerolog.Logger
+	RPCs      *tendermint.Registry
+	Sources   *sources.Manager
+	Transfers *transfers.Indexer
+	History   *history.Store
+	State     *state.Manager
+	Server    *http.Server
+	Tracer    trace.Tracer
+
+	UptimeQuerier *queriersPkg.UptimeQuerier
+	LastSnapshot  *status.Snapshot
+	ChainScrapes  map[string]status.ChainScrape
+	SnapshotMutex sync.Mutex
 }
 
-func NewApp(filesystem fs.FS, configPath string, version string) *App {
-	appConfig, err := config.GetConfig(configPath, filesystem)
+func NewApp(filesystem fs.FS, configPaths []string, configFormat config.Format, version string) *App {
+	appConfig, err := config.GetConfig(configPaths, configFormat, filesystem)
 	if err != nil {
 		logger.GetDefaultLogger().Panic().Err(err).Msg("Could not load config")
 	}
@@ -45,23 +80,84 @@ func NewApp(filesystem fs.FS, configPath string, version string) *App {
 	tracer := tracing.InitTracer(appConfig.TracingConfig, version)
 	log := logger.GetLogger(appConfig.LogConfig)
 	coingecko := coingeckoPkg.NewCoingecko(appConfig, log, tracer)
+	rpcs := tendermint.NewRegistry(appConfig, log, tracer)
+
+	stateManager := state.NewManager(appConfig.StateFile)
+	if err := stateManager.Load(); err != nil {
+		log.Warn().Err(err).Msg("Could not load state file, starting from scratch")
+	}
+
+	var heights map[string]map[string]tendermint.EndpointHeights
+	if _, err := stateManager.Get(stateKeyHeights, &heights); err != nil {
+		log.Warn().Err(err).Msg("Could not load heights from state file")
+	}
+	rpcs.RestoreHeights(heights)
+
+	var historyStore *history.Store
+	if appConfig.HistoryConfig.Enabled.Bool {
+		historyStore, err = history.NewStore(
+			appConfig.HistoryConfig.Path,
+			appConfig.HistoryConfig.GetRetention(),
+			appConfig.HistoryConfig.GetInterval(),
+		)
+		if err != nil {
+			log.Panic().Err(err).Msg("Could not open balances history store")
+		}
+	}
+
+	walletSources := sources.NewManager(appConfig, filesystem, log, tracer)
+	walletSources.Load(context.Background())
 
-	queriers := []types.Querier{
-		queriersPkg.NewPriceQuerier(appConfig, coingecko, tracer),
-		queriersPkg.NewBalanceQuerier(appConfig, log, tracer),
-		queriersPkg.NewApplicationQuerier(appConfig, log, tracer),
-		queriersPkg.NewSupplierQuerier(appConfig, log, tracer),
-		queriersPkg.NewUptimeQuerier(tracer),
+	transfersIndexer := transfers.NewIndexer(walletSources.GetConfig, rpcs, stateManager, log, tracer)
+	if err := transfersIndexer.LoadCursors(); err != nil {
+		log.Warn().Err(err).Msg("Could not load transfers cursors from state file")
 	}
 
 	server := &http.Server{Addr: appConfig.ListenAddress, Handler: nil}
 
-	return &App{
-		Config:   appConfig,
-		Logger:   log,
-		Queriers: queriers,
-		Tracer:   tracer,
-		Server:   server,
+	app := &App{
+		Config:        appConfig,
+		Coingecko:     coingecko,
+		Dashboard:     dashboard.NewDashboard(),
+		Prober:        probe.NewProber(appConfig, rpcs, log, tracer),
+		Logger:        log,
+		RPCs:          rpcs,
+		Sources:       walletSources,
+		Transfers:     transfersIndexer,
+		History:       historyStore,
+		State:         stateManager,
+		Tracer:        tracer,
+		Server:        server,
+		UptimeQuerier: queriersPkg.NewUptimeQuerier(tracer),
+		ChainScrapes:  make(map[string]status.ChainScrape),
+	}
+
+	return app
+}
+
+// GetConfig returns the app config with wallets loaded from wallet sources,
+// which can change between scrapes.
+func (a *App) GetConfig() *config.Config {
+	return a.Sources.GetConfig()
+}
+
+// GetQueriers builds all queriers for the given config, which can be either the app config
+// or its filtered copy. All of them share the same RPCs, history, uptime, wallet sources and transfers,
+// but only export the metrics of the config chains and wallets.
+func (a *App) GetQueriers(appConfig *config.Config) []types.Querier {
+	return []types.Querier{
+		queriersPkg.NewPriceQuerier(appConfig, a.Coingecko, a.Tracer),
+		queriersPkg.NewBalanceQuerier(appConfig, a.RPCs, a.History, a.Logger, a.Tracer),
+		queriersPkg.NewApplicationQuerier(appConfig, a.RPCs, a.Logger, a.Tracer),
+		queriersPkg.NewSupplierQuerier(appConfig, a.RPCs, a.Logger, a.Tracer),
+		queriersPkg.NewPocketParamsQuerier(appConfig, a.RPCs, a.Logger, a.Tracer),
+		queriersPkg.NewPocketClaimsQuerier(appConfig, a.RPCs, a.Logger, a.Tracer),
+		queriersPkg.NewVestingQuerier(appConfig, a.RPCs, a.Logger, a.Tracer),
+		queriersPkg.NewActivityQuerier(appConfig, a.RPCs, a.Logger, a.Tracer),
+		queriersPkg.NewNodeStatusQuerier(appConfig, a.RPCs, a.Logger, a.Tracer),
+		a.UptimeQuerier,
+		sources.NewQuerier(appConfig, a.Sources, a.Tracer),
+		transfers.NewQuerier(appConfig, a.Transfers, a.Tracer),
 	}
 }
 
@@ -69,9 +165,24 @@ func (a *App) Start() {
 	otelHandler := otelhttp.NewHandler(http.HandlerFunc(a.Handler), "prometheus")
 	handler := http.NewServeMux()
 	handler.Handle("/metrics", otelHandler)
+	handler.Handle("/probe", otelhttp.NewHandler(http.HandlerFunc(a.ProbeHandler), "probe"))
 	handler.HandleFunc("/healthcheck", a.Healthcheck)
+	handler.HandleFunc("/livez", a.Healthcheck)
+	handler.HandleFunc("/readyz", a.Readiness)
+	handler.HandleFunc("/api/v1/status", a.Status)
+	handler.HandleFunc("/", a.DashboardHandler)
+
+	if a.History != nil {
+		api := apiPkg.NewAPI(a.History, a.Logger)
+		handler.HandleFunc("/api/v1/balances", api.Balances)
+		handler.HandleFunc("/api/v1/balances/history", api.BalancesHistory)
+	}
+
 	a.Server.Handler = handler
 
+	a.Sources.Start()
+	a.Transfers.Start()
+
 	a.Logger.Info().Str("addr", a.Config.ListenAddress).Msg("Listening")
 
 	err := a.Server.ListenAndServe()
@@ -85,6 +196,15 @@ func (a *App) Stop() {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	_ = a.Server.Shutdown(ctx)
+	a.Sources.Stop()
+	a.Transfers.Stop()
+	a.SaveState()
+
+	if a.History != nil {
+		if err := a.History.Close(); err != nil {
+			a.Logger.Error().Err(err).Msg("Could not close balances history store")
+		}
+	}
 }
 
 func (a *App) Handler(w http.ResponseWriter, r *http.Request) {
@@ -97,46 +217,392 @@ func (a *App) Handler(w http.ResponseWriter, r *http.Request) {
 
 	span := trace.SpanFromContext(r.Context())
 	span.SetAttributes(attribute.String("request-id", requestID))
-	rootSpanCtx := r.Context()
 
 	defer span.End()
 
+	ctx := r.Context()
+	if timeout := a.Config.GetScrapeTimeout(r.Header.Get(constants.HeaderScrapeTimeout)); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var registry *prometheus.Registry
+
+	query := r.URL.Query()
+	if query.Has("chain") || query.Has("group") || query.Has("querier") {
+		filteredConfig, queriers, err := a.GetFilteredQueriers(
+			getQueryValues(query, "chain"),
+			getQueryValues(query, "group"),
+			getQueryValues(query, "querier"),
+		)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var snapshot *status.Snapshot
+		registry, snapshot = a.ScrapeWith(ctx, filteredConfig, queriers)
+		a.MergeSnapshot(snapshot)
+	} else {
+		registry, _ = a.Scrape(ctx)
+	}
+
+	a.ServeMetrics(w, r, registry)
+
+	sublogger.Info().
+		Str("method", http.MethodGet).
+		Str("endpoint", "/metrics").
+		Str("query", r.URL.RawQuery).
+		Float64("request-time", time.Since(requestStart).Seconds()).
+		Msg("Request processed")
+}
+
+// GetFilteredQueriers returns a config with only the given chains and groups,
+// and queriers with the given names built for it, so scrape jobs can be split
+// per chain, group or querier. Empty lists match everything.
+func (a *App) GetFilteredQueriers(
+	chains []string,
+	groups []string,
+	querierNames []string,
+) (*config.Config, []types.Querier, error) {
+	for _, chainName := range chains {
+		found := false
+		for _, chain := range a.Config.Chains {
+			if chain.Name == chainName {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, nil, fmt.Errorf("chain %s is not found in config", chainName)
+		}
+	}
+
+	filteredConfig := a.GetConfig().Filter(chains, groups)
+	queriers := a.GetQueriers(filteredConfig)
+
+	if len(querierNames) == 0 {
+		return filteredConfig, queriers, nil
+	}
+
+	filteredQueriers := make([]types.Querier, 0, len(querierNames))
+
+	for _, querierName := range querierNames {
+		found := false
+		for _, querier := range queriers {
+			if querier.Name() == querierName {
+				filteredQueriers = append(filteredQueriers, querier)
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, nil, fmt.Errorf("querier %s is not found", querierName)
+		}
+	}
+
+	return filteredConfig, filteredQueriers, nil
+}
+
+// ServeMetrics writes the metrics from the registry, with the namespace and labels
+// changed as configured in the metrics config.
+func (a *App) ServeMetrics(w http.ResponseWriter, r *http.Request, registry *prometheus.Registry) {
+	gatherer := metricsPkg.NewGatherer(registry, a.Config.MetricsConfig)
+	h := promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{})
+	h.ServeHTTP(w, r)
+}
+
+// ProbeHandler queries a single address passed in query params, see probe.Prober.
+func (a *App) ProbeHandler(w http.ResponseWriter, r *http.Request) {
+	requestStart := time.Now()
+
+	probeConfig, err := a.Prober.GetConfig(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	if timeout := probeConfig.GetScrapeTimeout(r.Header.Get(constants.HeaderScrapeTimeout)); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	registry := a.Prober.Probe(ctx, probeConfig)
+
+	a.ServeMetrics(w, r, registry)
+
+	a.Logger.Info().
+		Str("method", http.MethodGet).
+		Str("endpoint", "/probe").
+		Str("chain", r.URL.Query().Get("chain")).
+		Str("address", r.URL.Query().Get("address")).
+		Float64("request-time", time.Since(requestStart).Seconds()).
+		Msg("Request processed")
+}
+
+// Scrape runs all queriers and returns a registry with their metrics,
+// also keeping the results as the latest snapshot for the status API.
+func (a *App) Scrape(ctx context.Context) (*prometheus.Registry, *status.Snapshot) {
+	appConfig := a.GetConfig()
+	registry, snapshot := a.ScrapeWith(ctx, appConfig, a.GetQueriers(appConfig))
+
+	a.SnapshotMutex.Lock()
+	a.LastSnapshot = snapshot
+	a.SnapshotMutex.Unlock()
+
+	return registry, snapshot
+}
+
+// MergeSnapshot merges the results of a filtered scrape into the latest snapshot,
+// so deployments with split scrape jobs keep the web UI and the status API up to date.
+// If there's no snapshot yet, nothing is merged, so the first status request
+// does a full scrape instead of showing the filtered results only.
+func (a *App) MergeSnapshot(snapshot *status.Snapshot) {
+	a.SnapshotMutex.Lock()
+	defer a.SnapshotMutex.Unlock()
+
+	if a.LastSnapshot != nil {
+		a.LastSnapshot = a.LastSnapshot.Merge(snapshot)
+	}
+}
+
+// ScrapeWith runs the given queriers and returns a registry with their metrics.
+// Unlike Scrape, it does not replace the latest snapshot, as the results may be partial
+// (see MergeSnapshot), but it updates the results of the scraped chains used by the readiness check.
+func (a *App) ScrapeWith(
+	ctx context.Context,
+	appConfig *config.Config,
+	queriers []types.Querier,
+) (*prometheus.Registry, *status.Snapshot) {
+	// a fresh cache per scrape, so the same address isn't queried twice by different queriers
+	rootSpanCtx := tendermint.ContextWithQueryCache(ctx, tendermint.NewQueryCache())
+
 	registry := prometheus.NewRegistry()
 
 	var wg sync.WaitGroup
 	var mutex sync.Mutex
 
 	var queryInfos []types.QueryInfo
+	finished := make(map[string]bool, len(queriers))
+	timedOut := make(map[string]bool, len(queriers))
+	returned := false
 
-	for _, querier := range a.Queriers {
+	for _, querier := range queriers {
 		wg.Add(1)
 		go func(querier types.Querier, ctx context.Context) {
+			defer wg.Done()
+
+			// a querier deadline, so a slow querier does not use up the whole scrape deadline
+			if timeout := appConfig.GetQuerierTimeout(querier.Name()); timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
+
 			metrics, querierQueryInfos := querier.GetMetrics(ctx)
 
 			mutex.Lock()
+			defer mutex.Unlock()
+
+			// the scrape has already returned partial results, dropping the late ones
+			if returned {
+				return
+			}
+
+			// partial results of a querier that reached its deadline are still returned
 			registry.MustRegister(metrics...)
 			queryInfos = append(queryInfos, querierQueryInfos...)
-			mutex.Unlock()
-			wg.Done()
+			finished[querier.Name()] = true
+			timedOut[querier.Name()] = errors.Is(ctx.Err(), context.DeadlineExceeded)
 		}(querier, rootSpanCtx)
 	}
 
-	wg.Wait()
+	allFinished := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(allFinished)
+	}()
+
+	// all queries use the context, so queriers should return shortly after the deadline,
+	// but if some are stuck, returning what we have instead of making the scrape fail
+	select {
+	case <-allFinished:
+	case <-ctx.Done():
+		a.Logger.Warn().Err(ctx.Err()).Msg("Scrape deadline reached, returning partial results")
+	}
+
+	querierTimeoutGauge := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cosmos_wallets_exporter_querier_timeout",
+			Help: "Whether a querier did not finish before the scrape deadline or reached its own deadline",
+		},
+		config.QuerierLabelNames,
+	)
+
+	mutex.Lock()
+	returned = true
+	for _, querier := range queriers {
+		value := 0.0
+		if !finished[querier.Name()] || timedOut[querier.Name()] {
+			value = 1
+		}
 
-	queriersQuerier := queriersPkg.NewQueriesQuerier(a.Config, queryInfos)
+		querierTimeoutGauge.With(prometheus.Labels{"querier": querier.Name()}).Set(value)
+	}
+	mutex.Unlock()
+
+	registry.MustRegister(querierTimeoutGauge)
+
+	queriersQuerier := queriersPkg.NewQueriesQuerier(appConfig, queryInfos)
 	metrics, _ := queriersQuerier.GetMetrics()
 	registry.MustRegister(metrics...)
 
-	h := promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
-	h.ServeHTTP(w, r)
+	heightsQuerier := queriersPkg.NewHeightsQuerier(appConfig, a.RPCs)
+	heightsMetrics, _ := heightsQuerier.GetMetrics()
+	registry.MustRegister(heightsMetrics...)
 
-	sublogger.Info().
-		Str("method", http.MethodGet).
-		Str("endpoint", "/metrics").
-		Float64("request-time", time.Since(requestStart).Seconds()).
-		Msg("Request processed")
+	a.SaveStateIfOlderThan(stateSaveInterval)
+
+	families, err := registry.Gather()
+	if err != nil {
+		a.Logger.Error().Err(err).Msg("Could not gather metrics for status")
+	}
+
+	snapshot := &status.Snapshot{
+		Time:       time.Now(),
+		Families:   families,
+		QueryInfos: queryInfos,
+		ChainTimes: make(map[string]time.Time),
+	}
+
+	a.SnapshotMutex.Lock()
+	for chain, chainScrape := range status.GetChainScrapes(snapshot) {
+		a.ChainScrapes[chain] = chainScrape
+		snapshot.ChainTimes[chain] = chainScrape.Time
+	}
+	a.SnapshotMutex.Unlock()
+
+	return registry, snapshot
 }
 
+// GetLastSnapshot returns the latest scrape results, doing a scrape if there were none yet.
+func (a *App) GetLastSnapshot(ctx context.Context) *status.Snapshot {
+	a.SnapshotMutex.Lock()
+	snapshot := a.LastSnapshot
+	a.SnapshotMutex.Unlock()
+
+	if snapshot == nil {
+		if timeout := a.Config.GetScrapeTimeout(""); timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		_, snapshot = a.Scrape(ctx)
+	}
+
+	return snapshot
+}
+
+// Status returns the latest scrape results as JSON, optionally filtered by chain, group or address.
+// If nothing was scraped yet, it does a scrape itself.
+func (a *App) Status(w http.ResponseWriter, r *http.Request) {
+	snapshot := a.GetLastSnapshot(r.Context())
+
+	query := r.URL.Query()
+	response := status.NewResponse(snapshot, status.Filter{
+		Chain:   query.Get("chain"),
+		Group:   query.Get("group"),
+		Address: query.Get("address"),
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		a.Logger.Error().Err(err).Msg("Could not write status response")
+	}
+}
+
+// getQueryValues returns all values of a query param, which can be either repeated
+// (like chain=a&chain=b, that's how Prometheus passes params lists) or comma-separated (like chain=a,b).
+func getQueryValues(query url.Values, key string) []string {
+	values := []string{}
+
+	for _, value := range query[key] {
+		for _, part := range strings.Split(value, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				values = append(values, part)
+			}
+		}
+	}
+
+	return values
+}
+
+// SaveState writes the state file if anything has changed since the last save.
+func (a *App) SaveState() {
+	a.SaveStateIfOlderThan(0)
+}
+
+// SaveStateIfOlderThan writes the state file if anything has changed since the last save
+// and it was saved longer than the interval ago.
+func (a *App) SaveStateIfOlderThan(interval time.Duration) {
+	if err := a.State.Set(stateKeyHeights, a.RPCs.GetHeights()); err != nil {
+		a.Logger.Error().Err(err).Msg("Could not serialize heights")
+		return
+	}
+
+	if err := a.State.Set(transfers.StateKey, a.Transfers.GetCursors()); err != nil {
+		a.Logger.Error().Err(err).Msg("Could not serialize transfers cursors")
+		return
+	}
+
+	if err := a.State.SaveIfOlderThan(interval); err != nil {
+		a.Logger.Error().Err(err).Msg("Could not save state file")
+	}
+}
+
+// DashboardHandler renders a web page with an overview of the latest scrape results.
+func (a *App) DashboardHandler(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := a.Dashboard.Render(w, a.GetConfig(), a.GetLastSnapshot(r.Context())); err != nil {
+		a.Logger.Error().Err(err).Msg("Could not render dashboard")
+	}
+}
+
+// Healthcheck is a liveness check, it only shows that the HTTP server is up.
 func (a *App) Healthcheck(w http.ResponseWriter, r *http.Request) {
 	_, _ = w.Write([]byte("ok"))
 }
+
+// Readiness is a readiness check: it succeeds only if enough chains succeeded in their
+// latest scrape, full or filtered, done recently enough, listing failing chains otherwise.
+// It does not do a scrape itself, so it stays fast even if LCD endpoints are slow.
+func (a *App) Readiness(w http.ResponseWriter, r *http.Request) {
+	a.SnapshotMutex.Lock()
+	chainScrapes := make(map[string]status.ChainScrape, len(a.ChainScrapes))
+	for chain, chainScrape := range a.ChainScrapes {
+		chainScrapes[chain] = chainScrape
+	}
+	a.SnapshotMutex.Unlock()
+
+	readiness := status.GetReadiness(chainScrapes, a.Config.Chains, a.Config.ReadinessConfig, time.Now())
+
+	w.Header().Set("Content-Type", "application/json")
+	if !readiness.Ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	if err := json.NewEncoder(w).Encode(readiness); err != nil {
+		a.Logger.Error().Err(err).Msg("Could not write readiness response")
+	}
+}
@@ -1,14 +1,20 @@
 package pkg
 
 import (
+	"context"
+	"encoding/json"
 	"io"
 	"main/assets"
 	"main/pkg/fs"
+	"main/pkg/types"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"testing"
 	"time"
 
 	"github.com/jarcoal/httpmock"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/require"
 )
 
@@ -22,7 +28,7 @@ func TestAppLoadConfigError(t *testing.T) {
 
 	filesystem := &fs.TestFS{}
 
-	app := NewApp(filesystem, "not-found-config.toml", "1.2.3")
+	app := NewApp(filesystem, []string{"not-found-config.toml"}, "", "1.2.3")
 	app.Start()
 }
 
@@ -36,7 +42,7 @@ func TestAppLoadConfigInvalid(t *testing.T) {
 
 	filesystem := &fs.TestFS{}
 
-	app := NewApp(filesystem, "config-invalid.toml", "1.2.3")
+	app := NewApp(filesystem, []string{"config-invalid.toml"}, "", "1.2.3")
 	app.Start()
 }
 
@@ -50,7 +56,7 @@ func TestAppFailToStart(t *testing.T) {
 
 	filesystem := &fs.TestFS{}
 
-	app := NewApp(filesystem, "config-invalid-listen-address.toml", "1.2.3")
+	app := NewApp(filesystem, []string{"config-invalid-listen-address.toml"}, "", "1.2.3")
 	app.Start()
 }
 
@@ -58,7 +64,7 @@ func TestAppFailToStart(t *testing.T) {
 func TestAppStopOperation(t *testing.T) {
 	filesystem := &fs.TestFS{}
 
-	app := NewApp(filesystem, "config-valid.toml", "1.2.3")
+	app := NewApp(filesystem, []string{"config-valid.toml"}, "", "1.2.3")
 	app.Stop()
 	// Test passes if no panic occurs
 }
@@ -67,13 +73,13 @@ func TestAppStopOperation(t *testing.T) {
 func TestAppLoadConfigOk(t *testing.T) {
 	filesystem := &fs.TestFS{}
 
-	app := NewApp(filesystem, "config-valid.toml", "1.2.3")
+	app := NewApp(filesystem, []string{"config-valid.toml"}, "", "1.2.3")
 	go app.Start()
 
 	for {
 		request, err := http.Get("http://localhost:9550/healthcheck")
-		_ = request.Body.Close()
 		if err == nil {
+			_ = request.Body.Close()
 			break
 		}
 
@@ -89,6 +95,18 @@ func TestAppLoadConfigOk(t *testing.T) {
 		httpmock.NewBytesResponder(200, assets.GetBytesOrPanic("balance.json")),
 	)
 
+	httpmock.RegisterResponder(
+		"GET",
+		"https://example.com/cosmos/base/tendermint/v1beta1/blocks/latest",
+		httpmock.NewBytesResponder(200, assets.GetBytesOrPanic("latest-block.json")),
+	)
+
+	httpmock.RegisterResponder(
+		"GET",
+		"https://example.com/cosmos/base/tendermint/v1beta1/syncing",
+		httpmock.NewBytesResponder(200, assets.GetBytesOrPanic("syncing.json")),
+	)
+
 	httpmock.RegisterResponder(
 		"GET",
 		"https://api.coingecko.com/api/v3/simple/price?ids=cosmos&vs_currencies=usd",
@@ -97,6 +115,15 @@ func TestAppLoadConfigOk(t *testing.T) {
 
 	httpmock.RegisterResponder("GET", "http://localhost:9550/healthcheck", httpmock.InitialTransport.RoundTrip)
 	httpmock.RegisterResponder("GET", "http://localhost:9550/metrics", httpmock.InitialTransport.RoundTrip)
+	httpmock.RegisterResponder("GET", "http://localhost:9550/api/v1/status?chain=chain", httpmock.InitialTransport.RoundTrip)
+	httpmock.RegisterResponder("GET", "http://localhost:9550/", httpmock.InitialTransport.RoundTrip)
+	httpmock.RegisterResponder("GET", "http://localhost:9550/readyz", httpmock.InitialTransport.RoundTrip)
+
+	readyResponse, err := http.Get("http://localhost:9550/readyz")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusServiceUnavailable, readyResponse.StatusCode)
+	err = readyResponse.Body.Close()
+	require.NoError(t, err)
 
 	response, err := http.Get("http://localhost:9550/metrics")
 	require.NoError(t, err)
@@ -108,4 +135,244 @@ func TestAppLoadConfigOk(t *testing.T) {
 
 	err = response.Body.Close()
 	require.NoError(t, err)
+
+	statusResponse, err := http.Get("http://localhost:9550/api/v1/status?chain=chain")
+	require.NoError(t, err)
+
+	var status struct {
+		Metrics map[string][]json.RawMessage `json:"metrics"`
+	}
+	err = json.NewDecoder(statusResponse.Body).Decode(&status)
+	require.NoError(t, err)
+	require.Len(t, status.Metrics["balance"], 2)
+
+	err = statusResponse.Body.Close()
+	require.NoError(t, err)
+
+	dashboardResponse, err := http.Get("http://localhost:9550/")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, dashboardResponse.StatusCode)
+
+	dashboardBody, err := io.ReadAll(dashboardResponse.Body)
+	require.NoError(t, err)
+	require.Contains(t, string(dashboardBody), "address")
+
+	err = dashboardResponse.Body.Close()
+	require.NoError(t, err)
+
+	readyResponse, err = http.Get("http://localhost:9550/readyz")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, readyResponse.StatusCode)
+	err = readyResponse.Body.Close()
+	require.NoError(t, err)
+}
+
+//nolint:paralleltest // disabled due to httpmock usage
+func TestAppReadinessFilteredScrape(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder(
+		"GET",
+		"https://example.com/cosmos/bank/v1beta1/balances/address",
+		httpmock.NewBytesResponder(200, assets.GetBytesOrPanic("balance.json")),
+	)
+
+	filesystem := &fs.TestFS{}
+
+	app := NewApp(filesystem, []string{"config-valid.toml"}, "", "1.2.3")
+
+	recorder := httptest.NewRecorder()
+	app.Readiness(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+
+	// a deployment only doing filtered scrapes should become ready too
+	filteredConfig, queriers, err := app.GetFilteredQueriers([]string{"chain"}, []string{}, []string{"balance"})
+	require.NoError(t, err)
+	app.ScrapeWith(context.Background(), filteredConfig, queriers)
+
+	recorder = httptest.NewRecorder()
+	app.Readiness(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
+
+//nolint:paralleltest // disabled due to httpmock usage
+func TestAppMergeSnapshot(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder(
+		"GET",
+		"https://example.com/cosmos/bank/v1beta1/balances/address",
+		httpmock.NewBytesResponder(200, assets.GetBytesOrPanic("balance.json")),
+	)
+
+	app := NewApp(&fs.TestFS{}, []string{"config-valid.toml"}, "", "1.2.3")
+
+	filteredConfig, queriers, err := app.GetFilteredQueriers([]string{"chain"}, []string{}, []string{"balance"})
+	require.NoError(t, err)
+
+	// without a full scrape there is nothing to merge into
+	_, snapshot := app.ScrapeWith(context.Background(), filteredConfig, queriers)
+	app.MergeSnapshot(snapshot)
+	require.Nil(t, app.LastSnapshot)
+
+	_, fullSnapshot := app.Scrape(context.Background())
+
+	_, snapshot = app.ScrapeWith(context.Background(), filteredConfig, queriers)
+	app.MergeSnapshot(snapshot)
+
+	require.NotNil(t, app.LastSnapshot)
+	require.Equal(t, snapshot.Time, app.LastSnapshot.Time)
+	require.Equal(t, snapshot.Time, app.LastSnapshot.ChainTimes["chain"])
+	require.Len(t, app.LastSnapshot.Families, len(fullSnapshot.Families))
+}
+
+//nolint:paralleltest // disabled
+func TestAppGetFilteredQueriers(t *testing.T) {
+	filesystem := &fs.TestFS{}
+
+	app := NewApp(filesystem, []string{"config-valid.toml"}, "", "1.2.3")
+
+	_, _, err := app.GetFilteredQueriers([]string{"unknown"}, []string{}, []string{})
+	require.ErrorContains(t, err, "chain unknown is not found in config")
+
+	_, _, err = app.GetFilteredQueriers([]string{}, []string{}, []string{"unknown"})
+	require.ErrorContains(t, err, "querier unknown is not found")
+
+	filteredConfig, queriers, err := app.GetFilteredQueriers(
+		[]string{"chain"},
+		[]string{"other-group"},
+		[]string{"balance", "price"},
+	)
+	require.NoError(t, err)
+	require.Empty(t, filteredConfig.Chains)
+	require.Len(t, queriers, 2)
+	require.Equal(t, "balance", queriers[0].Name())
+	require.Equal(t, "price", queriers[1].Name())
+
+	filteredConfig, queriers, err = app.GetFilteredQueriers([]string{}, []string{"group"}, []string{})
+	require.NoError(t, err)
+	require.Len(t, filteredConfig.Chains, 1)
+	require.Len(t, queriers, len(app.GetQueriers(app.Config)))
+}
+
+func TestGetQueryValues(t *testing.T) {
+	t.Parallel()
+
+	query, err := url.ParseQuery("chain=a,b&chain=c&chain=&group=")
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"a", "b", "c"}, getQueryValues(query, "chain"))
+	require.Empty(t, getQueryValues(query, "group"))
+	require.Empty(t, getQueryValues(query, "querier"))
+}
+
+type stuckQuerier struct {
+	Delay time.Duration
+}
+
+func (q *stuckQuerier) Name() string {
+	return "stuck"
+}
+
+func (q *stuckQuerier) GetMetrics(ctx context.Context) ([]prometheus.Collector, []types.QueryInfo) {
+	// ignores the context on purpose, like a querier stuck on something
+	time.Sleep(q.Delay)
+
+	return []prometheus.Collector{}, []types.QueryInfo{}
+}
+
+//nolint:paralleltest // disabled
+func TestAppScrapeDeadline(t *testing.T) {
+	filesystem := &fs.TestFS{}
+
+	app := NewApp(filesystem, []string{"config-valid.toml"}, "", "1.2.3")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	registry, _ := app.ScrapeWith(ctx, app.Config, []types.Querier{
+		&stuckQuerier{Delay: time.Second},
+		app.UptimeQuerier,
+	})
+	require.Less(t, time.Since(start), time.Second)
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	timeouts := map[string]float64{}
+	for _, family := range families {
+		if family.GetName() != "cosmos_wallets_exporter_querier_timeout" {
+			continue
+		}
+
+		for _, metric := range family.GetMetric() {
+			timeouts[metric.GetLabel()[0].GetValue()] = metric.GetGauge().GetValue()
+		}
+	}
+
+	require.InDelta(t, 1, timeouts["stuck"], 0.001)
+	require.Zero(t, timeouts["uptime"])
+}
+
+type slowQuerier struct{}
+
+func (q *slowQuerier) Name() string {
+	return "slow"
+}
+
+func (q *slowQuerier) GetMetrics(ctx context.Context) ([]prometheus.Collector, []types.QueryInfo) {
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "slow_partial", Help: "Partial result"})
+	gauge.Set(1)
+
+	// waits for its deadline, like a querier with a slow LCD endpoint
+	<-ctx.Done()
+
+	return []prometheus.Collector{gauge}, []types.QueryInfo{}
+}
+
+//nolint:paralleltest // disabled
+func TestAppScrapeQuerierDeadline(t *testing.T) {
+	filesystem := &fs.TestFS{}
+
+	app := NewApp(filesystem, []string{"config-valid.toml"}, "", "1.2.3")
+
+	scrapeConfig := *app.Config
+	scrapeConfig.QuerierTimeouts = map[string]string{"slow": "50ms"}
+
+	// the scrape deadline is much longer than the querier one
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	registry, _ := app.ScrapeWith(ctx, &scrapeConfig, []types.Querier{
+		&slowQuerier{},
+		app.UptimeQuerier,
+	})
+	require.Less(t, time.Since(start), time.Second)
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	timeouts := map[string]float64{}
+	partial := false
+	for _, family := range families {
+		if family.GetName() == "slow_partial" {
+			partial = true
+		}
+
+		if family.GetName() != "cosmos_wallets_exporter_querier_timeout" {
+			continue
+		}
+
+		for _, metric := range family.GetMetric() {
+			timeouts[metric.GetLabel()[0].GetValue()] = metric.GetGauge().GetValue()
+		}
+	}
+
+	require.True(t, partial)
+	require.InDelta(t, 1, timeouts["slow"], 0.001)
+	require.Zero(t, timeouts["uptime"])
 }
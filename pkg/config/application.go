@@ -5,9 +5,10 @@ import (
 )
 
 type Application struct {
-	Address string `toml:"address"`
-	Name    string `toml:"name"`
-	Group   string `toml:"group"`
+	Address string            `json:"address" toml:"address" yaml:"address"`
+	Name    string            `json:"name"    toml:"name"    yaml:"name"`
+	Group   string            `json:"group"   toml:"group"   yaml:"group"`
+	Labels  map[string]string `json:"labels"  toml:"labels"  yaml:"labels"`
 }
 
 func (a Application) Validate() error {
@@ -15,5 +16,9 @@ func (a Application) Validate() error {
 		return errors.New("address for application is not specified")
 	}
 
+	if err := ValidateLabels(a.Labels); err != nil {
+		return err
+	}
+
 	return nil
 }
@@ -3,16 +3,33 @@ package config
 import (
 	"errors"
 	"fmt"
+	"main/pkg/utils"
+	"strings"
 )
 
 type Chain struct {
-	Name                    string        `toml:"name"`
-	LCDEndpoint             string        `toml:"lcd-endpoint"`
-	Denoms                  []DenomInfo   `toml:"denoms"`
-	Wallets                 []Wallet      `toml:"wallets"`
-	Applications            []Application `toml:"applications"`
-	Suppliers               []Supplier    `toml:"suppliers"`
-	RevShareDetailedMetrics *bool         `toml:"rev-share-detailed-metrics"`
+	Name                    string            `json:"name"                       toml:"name"                       yaml:"name"`
+	LCDEndpoint             string            `json:"lcd-endpoint"               toml:"lcd-endpoint"               yaml:"lcd-endpoint"`
+	LCDEndpointFile         string            `json:"lcd-endpoint-file"          toml:"lcd-endpoint-file"          yaml:"lcd-endpoint-file"`
+	Denoms                  []DenomInfo       `json:"denoms"                     toml:"denoms"                     yaml:"denoms"`
+	WalletSources           []WalletSource    `json:"wallet-sources"             toml:"wallet-sources"             yaml:"wallet-sources"`
+	Wallets                 []Wallet          `json:"wallets"                    toml:"wallets"                    yaml:"wallets"`
+	Applications            []Application     `json:"applications"               toml:"applications"               yaml:"applications"`
+	Suppliers               []Supplier        `json:"suppliers"                  toml:"suppliers"                  yaml:"suppliers"`
+	RevShareDetailedMetrics *bool             `json:"rev-share-detailed-metrics" toml:"rev-share-detailed-metrics" yaml:"rev-share-detailed-metrics"`
+	PocketParams            bool              `json:"pocket-params"              toml:"pocket-params"              yaml:"pocket-params"`
+	PocketServices          []string          `json:"pocket-services"            toml:"pocket-services"            yaml:"pocket-services"`
+	Labels                  map[string]string `json:"labels"                     toml:"labels"                     yaml:"labels"`
+	PocketClaims            bool              `json:"pocket-claims"              toml:"pocket-claims"              yaml:"pocket-claims"`
+	VestingMetrics          bool              `json:"vesting-metrics"            toml:"vesting-metrics"            yaml:"vesting-metrics"`
+	SequenceMetrics         bool              `json:"sequence-metrics"           toml:"sequence-metrics"           yaml:"sequence-metrics"`
+	LastTxMetrics           bool              `json:"last-tx-metrics"            toml:"last-tx-metrics"            yaml:"last-tx-metrics"`
+	TransferMetrics         bool              `json:"transfer-metrics"           toml:"transfer-metrics"           yaml:"transfer-metrics"`
+	// set when the LCD endpoint is read from a file or an environment variable,
+	// as it's likely to have an API key in it
+	SecretLCDEndpoint bool `json:"-" toml:"-" yaml:"-"`
+	// config files the chain is defined in, set by the loader, so errors can point to them
+	Files []string `json:"-" toml:"-" yaml:"-"`
 }
 
 func (c *Chain) Validate() error {
@@ -24,16 +41,58 @@ func (c *Chain) Validate() error {
 		return errors.New("no LCD endpoint provided")
 	}
 
-	if len(c.Wallets) == 0 && len(c.Applications) == 0 && len(c.Suppliers) == 0 {
+	if len(c.Wallets) == 0 &&
+		len(c.WalletSources) == 0 &&
+		len(c.Applications) == 0 &&
+		len(c.Suppliers) == 0 &&
+		!c.PocketParams {
 		return errors.New("no wallets, applications, or suppliers provided")
 	}
 
+	if err := ValidateLabels(c.Labels); err != nil {
+		return err
+	}
+
+	if len(c.PocketServices) > 0 && !c.PocketParams {
+		return errors.New("pocket services are provided, but pocket params are disabled")
+	}
+
+	if c.PocketClaims && len(c.Suppliers) == 0 {
+		return errors.New("pocket claims are enabled, but no suppliers provided")
+	}
+
+	if len(c.Wallets) == 0 && len(c.WalletSources) == 0 {
+		if c.VestingMetrics {
+			return errors.New("vesting metrics are enabled, but no wallets provided")
+		}
+
+		if c.SequenceMetrics || c.LastTxMetrics {
+			return errors.New("account activity metrics are enabled, but no wallets provided")
+		}
+
+		if c.TransferMetrics {
+			return errors.New("transfer metrics are enabled, but no wallets provided")
+		}
+	}
+
+	for index, denom := range c.Denoms {
+		if err := denom.Validate(); err != nil {
+			return fmt.Errorf("error in denom %d: %s", index, err)
+		}
+	}
+
 	for index, wallet := range c.Wallets {
 		if err := wallet.Validate(); err != nil {
 			return fmt.Errorf("error in wallet %d: %s", index, err)
 		}
 	}
 
+	for index, source := range c.WalletSources {
+		if err := source.Validate(); err != nil {
+			return fmt.Errorf("error in wallet source %d: %s", index, err)
+		}
+	}
+
 	for index, application := range c.Applications {
 		if err := application.Validate(); err != nil {
 			return fmt.Errorf("error in application %d: %s", index, err)
@@ -49,9 +108,34 @@ func (c *Chain) Validate() error {
 	return nil
 }
 
+// GetFilesDescription returns the config files the chain is defined in, to be added to errors.
+func (c *Chain) GetFilesDescription() string {
+	if len(c.Files) == 0 {
+		return ""
+	}
+
+	return " (" + strings.Join(c.Files, ", ") + ")"
+}
+
+// IsPartial returns true if the chain has no LCD endpoint, meaning it only adds wallets,
+// applications or suppliers to the chain with the same name defined in another config file.
+func (c *Chain) IsPartial() bool {
+	return c.LCDEndpoint == "" && c.LCDEndpointFile == ""
+}
+
+// GetDisplayLCDEndpoint returns the LCD endpoint to show in metric labels, logs and
+// the status pages. Secret endpoints are redacted to their scheme and host.
+func (c *Chain) GetDisplayLCDEndpoint() string {
+	if !c.SecretLCDEndpoint {
+		return c.LCDEndpoint
+	}
+
+	return utils.RedactURL(c.LCDEndpoint)
+}
+
 func (c *Chain) FindDenomByName(denom string) (*DenomInfo, bool) {
 	for _, denomIterated := range c.Denoms {
-		if denomIterated.Denom == denom {
+		if denomIterated.GetDenom() == denom {
 			return &denomIterated, true
 		}
 	}
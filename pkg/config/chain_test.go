@@ -72,3 +72,103 @@ func TestChainFindDenomByName(t *testing.T) {
 	require.Nil(t, denom2)
 	assert.False(t, found2)
 }
+
+func TestChainPocketServicesWithoutParams(t *testing.T) {
+	t.Parallel()
+
+	chain := &Chain{
+		Name:           "chain",
+		LCDEndpoint:    "test",
+		Wallets:        []Wallet{{Address: "address"}},
+		PocketServices: []string{"anvil"},
+	}
+	err := chain.Validate()
+	require.Error(t, err)
+	require.ErrorContains(t, err, "pocket services are provided, but pocket params are disabled")
+}
+
+func TestChainValidPocketParamsOnly(t *testing.T) {
+	t.Parallel()
+
+	chain := &Chain{
+		Name:           "chain",
+		LCDEndpoint:    "test",
+		PocketParams:   true,
+		PocketServices: []string{"anvil"},
+	}
+	err := chain.Validate()
+	require.NoError(t, err)
+}
+
+func TestChainPocketClaimsWithoutSuppliers(t *testing.T) {
+	t.Parallel()
+
+	chain := &Chain{
+		Name:         "chain",
+		LCDEndpoint:  "test",
+		Wallets:      []Wallet{{Address: "address"}},
+		PocketClaims: true,
+	}
+	err := chain.Validate()
+	require.Error(t, err)
+	require.ErrorContains(t, err, "pocket claims are enabled, but no suppliers provided")
+}
+
+func TestChainVestingMetricsWithoutWallets(t *testing.T) {
+	t.Parallel()
+
+	chain := &Chain{
+		Name:           "chain",
+		LCDEndpoint:    "test",
+		Suppliers:      []Supplier{{Address: "supplier"}},
+		VestingMetrics: true,
+	}
+	err := chain.Validate()
+	require.Error(t, err)
+	require.ErrorContains(t, err, "vesting metrics are enabled, but no wallets provided")
+}
+
+func TestChainActivityMetricsWithoutWallets(t *testing.T) {
+	t.Parallel()
+
+	chain := &Chain{
+		Name:          "chain",
+		LCDEndpoint:   "test",
+		Suppliers:     []Supplier{{Address: "supplier"}},
+		LastTxMetrics: true,
+	}
+	err := chain.Validate()
+	require.Error(t, err)
+	require.ErrorContains(t, err, "account activity metrics are enabled, but no wallets provided")
+}
+
+func TestChainDuplicateAddresses(t *testing.T) {
+	t.Parallel()
+
+	// duplicates within a file are allowed, only the ones across files are rejected, see MergeChains
+	chain := &Chain{
+		Name:        "chain",
+		LCDEndpoint: "test",
+		Wallets:     []Wallet{{Address: "address"}, {Address: "address"}},
+	}
+	require.NoError(t, chain.Validate())
+
+	// the same address can be both a wallet and a supplier
+	chain = &Chain{
+		Name:        "chain",
+		LCDEndpoint: "test",
+		Wallets:     []Wallet{{Address: "address"}},
+		Suppliers:   []Supplier{{Address: "address"}},
+	}
+	require.NoError(t, chain.Validate())
+}
+
+func TestChainValidWalletSourcesOnly(t *testing.T) {
+	t.Parallel()
+
+	chain := &Chain{Name: "chain", LCDEndpoint: "test", WalletSources: []WalletSource{{File: "wallets.csv"}}}
+	require.NoError(t, chain.Validate())
+
+	chain.WalletSources = []WalletSource{{}}
+	require.ErrorContains(t, chain.Validate(), "error in wallet source 0")
+}
@@ -4,32 +4,170 @@ import (
 	"errors"
 	"fmt"
 	"main/pkg/fs"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/BurntSushi/toml"
 	"github.com/creasty/defaults"
 )
 
 type Config struct {
-	TracingConfig TracingConfig `toml:"tracing"`
-	LogConfig     LogConfig     `toml:"log"`
-	ListenAddress string        `default:":9550" toml:"listen-address"`
-	Chains        []Chain       `toml:"chains"`
+	TracingConfig       TracingConfig     `json:"tracing"          toml:"tracing"               yaml:"tracing"`
+	LogConfig           LogConfig         `json:"log"              toml:"log"                   yaml:"log"`
+	HistoryConfig       HistoryConfig     `json:"history"          toml:"history"               yaml:"history"`
+	ReadinessConfig     ReadinessConfig   `json:"readiness"        toml:"readiness"             yaml:"readiness"`
+	MetricsConfig       MetricsConfig     `json:"metrics"          toml:"metrics"               yaml:"metrics"`
+	TransfersConfig     TransfersConfig   `json:"transfers"        toml:"transfers"             yaml:"transfers"`
+	ListenAddress       string            `default:":9550"         json:"listen-address"        toml:"listen-address"        yaml:"listen-address"`
+	StateFile           string            `json:"state-file"       toml:"state-file"            yaml:"state-file"`
+	ScrapeTimeout       string            `json:"scrape-timeout"   toml:"scrape-timeout"        yaml:"scrape-timeout"`
+	ScrapeTimeoutMargin string            `default:"500ms"         json:"scrape-timeout-margin" toml:"scrape-timeout-margin" yaml:"scrape-timeout-margin"`
+	QuerierTimeout      string            `json:"querier-timeout"  toml:"querier-timeout"       yaml:"querier-timeout"`
+	QuerierTimeouts     map[string]string `json:"querier-timeouts" toml:"querier-timeouts"      yaml:"querier-timeouts"`
+	Chains              []Chain           `json:"chains"           toml:"chains"                yaml:"chains"`
+	Include             []string          `json:"include"          toml:"include"               yaml:"include"`
+
+	// names of ${NAME} references in the config that could not be resolved
+	UnresolvedEnvVariables []string `json:"-" toml:"-" yaml:"-"`
 }
 
 func (c *Config) Validate() error {
+	if len(c.UnresolvedEnvVariables) > 0 {
+		return fmt.Errorf(
+			"environment variables are referenced but not set: %s",
+			strings.Join(c.UnresolvedEnvVariables, ", "),
+		)
+	}
+
 	if len(c.Chains) == 0 {
 		return errors.New("no chains provided")
 	}
 
 	for index, chain := range c.Chains {
 		if err := chain.Validate(); err != nil {
-			return fmt.Errorf("error in chain %d: %s", index, err)
+			return fmt.Errorf("error in chain %d%s: %s", index, chain.GetFilesDescription(), err)
+		}
+	}
+
+	chainNames := make(map[string]Chain, len(c.Chains))
+	for _, chain := range c.Chains {
+		if existing, ok := chainNames[chain.Name]; ok {
+			return fmt.Errorf(
+				"duplicate chain name: %s%s%s",
+				chain.Name,
+				existing.GetFilesDescription(),
+				chain.GetFilesDescription(),
+			)
+		}
+
+		chainNames[chain.Name] = chain
+	}
+
+	if err := c.HistoryConfig.Validate(); err != nil {
+		return fmt.Errorf("error in history config: %s", err)
+	}
+
+	if err := c.ReadinessConfig.Validate(); err != nil {
+		return fmt.Errorf("error in readiness config: %s", err)
+	}
+
+	if c.HasTransferMetrics() {
+		if err := c.TransfersConfig.Validate(); err != nil {
+			return fmt.Errorf("error in transfers config: %s", err)
+		}
+	}
+
+	if err := c.MetricsConfig.Validate(); err != nil {
+		return fmt.Errorf("error in metrics config: %s", err)
+	}
+
+	for _, name := range c.GetLabelNames() {
+		if err := c.MetricsConfig.ValidateCustomLabelName(name); err != nil {
+			return err
+		}
+	}
+
+	if c.ScrapeTimeout != "" {
+		if _, err := time.ParseDuration(c.ScrapeTimeout); err != nil {
+			return fmt.Errorf("invalid scrape timeout: %s", err)
+		}
+	}
+
+	if c.ScrapeTimeoutMargin != "" {
+		if _, err := time.ParseDuration(c.ScrapeTimeoutMargin); err != nil {
+			return fmt.Errorf("invalid scrape timeout margin: %s", err)
+		}
+	}
+
+	if c.QuerierTimeout != "" {
+		if timeout, err := time.ParseDuration(c.QuerierTimeout); err != nil {
+			return fmt.Errorf("invalid querier timeout: %s", err)
+		} else if timeout <= 0 {
+			return errors.New("querier timeout should be positive")
+		}
+	}
+
+	for querier, value := range c.QuerierTimeouts {
+		if timeout, err := time.ParseDuration(value); err != nil {
+			return fmt.Errorf("invalid timeout of querier %s: %s", querier, err)
+		} else if timeout <= 0 {
+			return fmt.Errorf("timeout of querier %s should be positive", querier)
 		}
 	}
 
 	return nil
 }
 
+// GetQuerierTimeout returns how long a querier can take within a scrape, so a slow one
+// does not use up the whole scrape deadline. Returns 0 if there's no timeout, then
+// only the scrape deadline applies.
+func (c *Config) GetQuerierTimeout(querier string) time.Duration {
+	if value, ok := c.QuerierTimeouts[querier]; ok {
+		timeout, _ := time.ParseDuration(value)
+		return timeout
+	}
+
+	timeout, _ := time.ParseDuration(c.QuerierTimeout)
+	return timeout
+}
+
+// GetScrapeTimeout returns how long a scrape can take. If Prometheus passed its scrape timeout
+// (in seconds, as a string), it's used minus the configured margin, leaving time to send
+// the response, otherwise the configured scrape timeout is used. Returns 0 if there's no timeout.
+func (c *Config) GetScrapeTimeout(prometheusTimeout string) time.Duration {
+	if prometheusTimeout == "" {
+		timeout, _ := time.ParseDuration(c.ScrapeTimeout)
+		return timeout
+	}
+
+	seconds, err := strconv.ParseFloat(prometheusTimeout, 64)
+	if err != nil || seconds <= 0 {
+		timeout, _ := time.ParseDuration(c.ScrapeTimeout)
+		return timeout
+	}
+
+	timeout := time.Duration(seconds * float64(time.Second))
+	margin, _ := time.ParseDuration(c.ScrapeTimeoutMargin)
+
+	// if the margin is too big, better use the whole timeout than to fail right away
+	if timeout-margin <= 0 {
+		return timeout
+	}
+
+	return timeout - margin
+}
+
+// HasTransferMetrics returns true if any chain has transfer metrics enabled,
+// so the transfers indexer needs to run.
+func (c *Config) HasTransferMetrics() bool {
+	return slices.ContainsFunc(c.Chains, func(chain Chain) bool {
+		return chain.TransferMetrics
+	})
+}
+
 func (c *Config) GetCoingeckoCurrencies() []string {
 	currencies := []string{}
 
@@ -44,16 +182,39 @@ func (c *Config) GetCoingeckoCurrencies() []string {
 	return currencies
 }
 
-func GetConfig(path string, filesystem fs.FS) (*Config, error) {
-	configBytes, err := filesystem.ReadFile(path)
-	if err != nil {
-		return nil, err
+// GetConfig loads the config from the given paths, each being a file or a directory
+// with config files. Files can be in TOML, YAML or JSON, the format is detected by
+// the file extension, unless it's passed explicitly. Files are applied in order: settings in later files override earlier ones,
+// and chains with the same name are merged (see MergeChains). Each file can also
+// include other files or directories with the include directive.
+func GetConfig(paths []string, format Format, filesystem fs.FS) (*Config, error) {
+	configLoader := &loader{
+		Filesystem: filesystem,
+		Format:     format,
+		Loaded:     make(map[string]bool),
+	}
+
+	for _, path := range paths {
+		if err := configLoader.LoadPath(path); err != nil {
+			return nil, err
+		}
 	}
 
-	configString := string(configBytes)
+	configStruct := configLoader.Config
+
+	// before loading secret files, so their contents are never interpolated
+	unresolved := configStruct.InterpolateEnv(os.LookupEnv)
+	for _, name := range configLoader.Unresolved {
+		if !slices.Contains(unresolved, name) {
+			unresolved = append(unresolved, name)
+		}
+	}
+
+	sort.Strings(unresolved)
+
+	configStruct.UnresolvedEnvVariables = unresolved
 
-	configStruct := Config{}
-	if _, err = toml.Decode(configString, &configStruct); err != nil {
+	if err := configStruct.LoadSecretFiles(filesystem); err != nil {
 		return nil, err
 	}
 
@@ -3,7 +3,9 @@ package config
 import (
 	"main/pkg/fs"
 	"testing"
+	"time"
 
+	"github.com/guregu/null/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -59,7 +61,7 @@ func TestLoadConfigFailedToLoad(t *testing.T) {
 	t.Parallel()
 
 	filesystem := &fs.TestFS{}
-	config, err := GetConfig("not-found", filesystem)
+	config, err := GetConfig([]string{"not-found"}, "", filesystem)
 	require.Nil(t, config)
 	require.Error(t, err)
 }
@@ -68,7 +70,7 @@ func TestLoadConfigFailedToDecode(t *testing.T) {
 	t.Parallel()
 
 	filesystem := &fs.TestFS{}
-	config, err := GetConfig("invalid.toml", filesystem)
+	config, err := GetConfig([]string{"invalid.toml"}, "", filesystem)
 	require.Nil(t, config)
 	require.Error(t, err)
 }
@@ -77,7 +79,161 @@ func TestLoadConfigValid(t *testing.T) {
 	t.Parallel()
 
 	filesystem := &fs.TestFS{}
-	config, err := GetConfig("config-valid.toml", filesystem)
+	config, err := GetConfig([]string{"config-valid.toml"}, "", filesystem)
 	require.NotNil(t, config)
 	require.NoError(t, err)
 }
+
+func TestConfigInvalidHistory(t *testing.T) {
+	t.Parallel()
+
+	chain := &Config{
+		Chains: []Chain{{
+			Name:        "chain",
+			LCDEndpoint: "test",
+			Wallets:     []Wallet{{Address: "address"}},
+		}},
+		HistoryConfig: HistoryConfig{Enabled: null.BoolFrom(true), Path: "history.db", Retention: "invalid"},
+	}
+	err := chain.Validate()
+	require.Error(t, err)
+	require.ErrorContains(t, err, "error in history config")
+}
+
+func TestConfigInvalidReadiness(t *testing.T) {
+	t.Parallel()
+
+	chain := &Config{
+		Chains: []Chain{{
+			Name:        "chain",
+			LCDEndpoint: "test",
+			Wallets:     []Wallet{{Address: "address"}},
+		}},
+		ReadinessConfig: ReadinessConfig{MinSuccessRatio: 2},
+	}
+	err := chain.Validate()
+	require.Error(t, err)
+	require.ErrorContains(t, err, "error in readiness config")
+}
+
+func TestReadinessConfigMaxAge(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, ReadinessConfig{MaxAge: "5m"}.Validate())
+	require.Equal(t, 5*time.Minute, ReadinessConfig{MaxAge: "5m"}.GetMaxAge())
+	require.ErrorContains(t, ReadinessConfig{MaxAge: "invalid"}.Validate(), "invalid readiness max age")
+	require.ErrorContains(t, ReadinessConfig{MaxAge: "-1m"}.Validate(), "should not be negative")
+}
+
+func TestConfigInvalidScrapeTimeout(t *testing.T) {
+	t.Parallel()
+
+	config := &Config{
+		Chains: []Chain{{
+			Name:        "chain",
+			LCDEndpoint: "test",
+			Wallets:     []Wallet{{Address: "address"}},
+		}},
+		ScrapeTimeout: "invalid",
+	}
+	err := config.Validate()
+	require.Error(t, err)
+	require.ErrorContains(t, err, "invalid scrape timeout")
+
+	config.ScrapeTimeout = "10s"
+	config.ScrapeTimeoutMargin = "invalid"
+	err = config.Validate()
+	require.Error(t, err)
+	require.ErrorContains(t, err, "invalid scrape timeout margin")
+}
+
+func TestConfigInvalidQuerierTimeouts(t *testing.T) {
+	t.Parallel()
+
+	config := &Config{
+		Chains: []Chain{{
+			Name:        "chain",
+			LCDEndpoint: "test",
+			Wallets:     []Wallet{{Address: "address"}},
+		}},
+		QuerierTimeout: "invalid",
+	}
+	require.ErrorContains(t, config.Validate(), "invalid querier timeout")
+
+	config.QuerierTimeout = "0s"
+	require.ErrorContains(t, config.Validate(), "querier timeout should be positive")
+
+	config.QuerierTimeout = "5s"
+	config.QuerierTimeouts = map[string]string{"price": "invalid"}
+	require.ErrorContains(t, config.Validate(), "invalid timeout of querier price")
+
+	config.QuerierTimeouts = map[string]string{"price": "-1s"}
+	require.ErrorContains(t, config.Validate(), "timeout of querier price should be positive")
+
+	config.QuerierTimeouts = map[string]string{"price": "2s"}
+	require.NoError(t, config.Validate())
+}
+
+func TestConfigGetQuerierTimeout(t *testing.T) {
+	t.Parallel()
+
+	config := &Config{}
+	assert.Zero(t, config.GetQuerierTimeout("price"))
+
+	config.QuerierTimeout = "5s"
+	config.QuerierTimeouts = map[string]string{"price": "2s"}
+	assert.Equal(t, 2*time.Second, config.GetQuerierTimeout("price"))
+	assert.Equal(t, 5*time.Second, config.GetQuerierTimeout("balance"))
+}
+
+func TestConfigGetScrapeTimeout(t *testing.T) {
+	t.Parallel()
+
+	config := &Config{ScrapeTimeoutMargin: "500ms"}
+	assert.Zero(t, config.GetScrapeTimeout(""))
+	assert.Zero(t, config.GetScrapeTimeout("invalid"))
+	assert.Equal(t, 9500*time.Millisecond, config.GetScrapeTimeout("10"))
+	assert.Equal(t, 1500*time.Millisecond, config.GetScrapeTimeout("2.0"))
+	// margin is bigger than the timeout
+	assert.Equal(t, 300*time.Millisecond, config.GetScrapeTimeout("0.3"))
+
+	config.ScrapeTimeout = "30s"
+	assert.Equal(t, 30*time.Second, config.GetScrapeTimeout(""))
+	assert.Equal(t, 30*time.Second, config.GetScrapeTimeout("-1"))
+	assert.Equal(t, 9500*time.Millisecond, config.GetScrapeTimeout("10"))
+}
+
+//nolint:paralleltest // disabled due to environment variables usage
+func TestLoadConfigEnvInterpolation(t *testing.T) {
+	t.Setenv("COSMOS_WALLETS_EXPORTER_TEST_API_KEY", "api-key")
+
+	config, err := GetConfig([]string{"config-env.toml"}, "", &fs.TestFS{})
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/api-key", config.Chains[0].LCDEndpoint)
+	assert.Equal(t, []string{"COSMOS_WALLETS_EXPORTER_TEST_PASSWORD"}, config.UnresolvedEnvVariables)
+
+	err = config.Validate()
+	require.Error(t, err)
+	require.ErrorContains(t, err, "environment variables are referenced but not set: COSMOS_WALLETS_EXPORTER_TEST_PASSWORD")
+
+	t.Setenv("COSMOS_WALLETS_EXPORTER_TEST_PASSWORD", "password")
+
+	config, err = GetConfig([]string{"config-env.toml"}, "", &fs.TestFS{})
+	require.NoError(t, err)
+	require.NoError(t, config.Validate())
+	assert.Equal(t, "password", config.TracingConfig.OpenTelemetryHTTPPassword)
+}
+
+//nolint:paralleltest // disabled due to environment variables usage
+func TestLoadConfigEnvInterpolationSpecialCharacters(t *testing.T) {
+	t.Setenv("COSMOS_WALLETS_EXPORTER_TEST_API_KEY", "key\"\nname = \"injected\"")
+	t.Setenv("COSMOS_WALLETS_EXPORTER_TEST_PASSWORD", "pass'word\\")
+
+	// values with quotes and newlines neither break the config nor add settings to it
+	config, err := GetConfig([]string{"config-env.toml"}, "", &fs.TestFS{})
+	require.NoError(t, err)
+	require.NoError(t, config.Validate())
+	assert.Equal(t, "chain", config.Chains[0].Name)
+	assert.Equal(t, "https://example.com/key\"\nname = \"injected\"", config.Chains[0].LCDEndpoint)
+	assert.Equal(t, "pass'word\\", config.TracingConfig.OpenTelemetryHTTPPassword)
+}
@@ -1,10 +1,34 @@
 package config
 
+import (
+	"errors"
+)
+
+// DenomInfo describes how to display a denom. Denoms with a CW20 contract are CW20 tokens,
+// their balances are queried from the contract instead of the bank module.
 type DenomInfo struct {
-	Denom             string `toml:"denom"`
-	DisplayDenom      string `toml:"display-denom"`
-	DenomExponent     int    `default:"6"               toml:"denom-exponent"`
-	CoingeckoCurrency string `toml:"coingecko-currency"`
+	Denom             string `json:"denom"              toml:"denom"              yaml:"denom"`
+	DisplayDenom      string `json:"display-denom"      toml:"display-denom"      yaml:"display-denom"`
+	DenomExponent     int    `default:"6"               json:"denom-exponent"     toml:"denom-exponent"     yaml:"denom-exponent"`
+	CoingeckoCurrency string `json:"coingecko-currency" toml:"coingecko-currency" yaml:"coingecko-currency"`
+	CW20Contract      string `json:"cw20-contract"      toml:"cw20-contract"      yaml:"cw20-contract"`
+}
+
+func (d DenomInfo) Validate() error {
+	if d.Denom == "" && d.CW20Contract == "" {
+		return errors.New("neither denom nor CW20 contract is specified")
+	}
+
+	return nil
+}
+
+// GetDenom returns the denom, for CW20 tokens without one it's "cw20:" followed by the contract address.
+func (d DenomInfo) GetDenom() string {
+	if d.Denom == "" && d.CW20Contract != "" {
+		return "cw20:" + d.CW20Contract
+	}
+
+	return d.Denom
 }
 
 func (d DenomInfo) GetName() string {
@@ -12,5 +36,9 @@ func (d DenomInfo) GetName() string {
 		return d.DisplayDenom
 	}
 
-	return d.Denom
+	return d.GetDenom()
+}
+
+func (d DenomInfo) IsCW20() bool {
+	return d.CW20Contract != ""
 }
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestDenomInfoGetName(t *testing.T) {
@@ -11,4 +12,16 @@ func TestDenomInfoGetName(t *testing.T) {
 
 	assert.Equal(t, "denom", DenomInfo{Denom: "denom"}.GetName())
 	assert.Equal(t, "display", DenomInfo{Denom: "denom", DisplayDenom: "display"}.GetName())
+	assert.Equal(t, "cw20:contract", DenomInfo{CW20Contract: "contract"}.GetName())
+	assert.Equal(t, "token", DenomInfo{CW20Contract: "contract", DisplayDenom: "token"}.GetName())
+}
+
+func TestDenomInfoValidate(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, DenomInfo{Denom: "denom"}.Validate())
+	require.NoError(t, DenomInfo{CW20Contract: "contract"}.Validate())
+	require.ErrorContains(t, DenomInfo{DisplayDenom: "display"}.Validate(), "neither denom nor CW20 contract")
+	assert.True(t, DenomInfo{CW20Contract: "contract"}.IsCW20())
+	assert.False(t, DenomInfo{Denom: "denom"}.IsCW20())
 }
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+)
+
+var envReferenceRegexp = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// envInterpolator replaces ${NAME} references with environment variables values returned
+// by lookup, collecting the names of references that could not be resolved, so they
+// can be reported without printing any of the values.
+type envInterpolator struct {
+	Lookup     func(string) (string, bool)
+	Unresolved []string
+}
+
+func (i *envInterpolator) Interpolate(value string) string {
+	return envReferenceRegexp.ReplaceAllStringFunc(value, func(reference string) string {
+		name := envReferenceRegexp.FindStringSubmatch(reference)[1]
+
+		resolved, found := i.Lookup(name)
+		if !found {
+			if !slices.Contains(i.Unresolved, name) {
+				i.Unresolved = append(i.Unresolved, name)
+			}
+
+			return reference
+		}
+
+		return resolved
+	})
+}
+
+// InterpolateEnv replaces ${NAME} references in the config string values with environment
+// variables values, returned by lookup. It's done on decoded values rather than on the file
+// content, so values with quotes, backslashes or newlines can't break the config or add
+// settings to it, and comments are never resolved. Returns the sorted names of references
+// that could not be resolved. LCD endpoints with references are marked as secret.
+func (c *Config) InterpolateEnv(lookup func(string) (string, bool)) []string {
+	for index := range c.Chains {
+		if envReferenceRegexp.MatchString(c.Chains[index].LCDEndpoint) {
+			c.Chains[index].SecretLCDEndpoint = true
+		}
+	}
+
+	interpolator := &envInterpolator{Lookup: lookup, Unresolved: []string{}}
+	interpolateValue(reflect.ValueOf(c).Elem(), interpolator.Interpolate)
+
+	sort.Strings(interpolator.Unresolved)
+	return interpolator.Unresolved
+}
+
+// interpolateValue calls interpolate on all the exported string fields, and strings
+// in slices, pointers and map values, of the value.
+func interpolateValue(value reflect.Value, interpolate func(string) string) {
+	switch value.Kind() {
+	case reflect.String:
+		if value.CanSet() {
+			value.SetString(interpolate(value.String()))
+		}
+	case reflect.Struct:
+		for index := 0; index < value.NumField(); index++ {
+			if value.Type().Field(index).IsExported() {
+				interpolateValue(value.Field(index), interpolate)
+			}
+		}
+	case reflect.Slice:
+		for index := 0; index < value.Len(); index++ {
+			interpolateValue(value.Index(index), interpolate)
+		}
+	case reflect.Pointer:
+		if !value.IsNil() {
+			interpolateValue(value.Elem(), interpolate)
+		}
+	case reflect.Map:
+		if value.Type().Elem().Kind() != reflect.String {
+			return
+		}
+
+		for _, key := range value.MapKeys() {
+			interpolated := interpolate(value.MapIndex(key).String())
+			value.SetMapIndex(key, reflect.ValueOf(interpolated).Convert(value.Type().Elem()))
+		}
+	default:
+	}
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterpolateEnv(t *testing.T) {
+	t.Parallel()
+
+	lookup := func(name string) (string, bool) {
+		if name == "API_KEY" {
+			return "secret", true
+		}
+
+		return "", false
+	}
+
+	config := &Config{
+		TracingConfig: TracingConfig{OpenTelemetryHTTPPassword: "${PASSWORD}${PASSWORD}"},
+		MetricsConfig: MetricsConfig{ConstLabels: map[string]string{"key": "${API_KEY}"}},
+		Chains: []Chain{{
+			Name:        "$NOT_A_REFERENCE",
+			LCDEndpoint: "https://example.com/${API_KEY}",
+			Wallets:     []Wallet{{Address: "address", Labels: map[string]string{"team": "${TEAM}"}}},
+		}},
+	}
+
+	unresolved := config.InterpolateEnv(lookup)
+
+	assert.Equal(t, "https://example.com/secret", config.Chains[0].LCDEndpoint)
+	assert.True(t, config.Chains[0].SecretLCDEndpoint)
+	assert.Equal(t, "https://example.com/<redacted>", config.Chains[0].GetDisplayLCDEndpoint())
+	assert.Equal(t, "$NOT_A_REFERENCE", config.Chains[0].Name)
+	assert.Equal(t, "${PASSWORD}${PASSWORD}", config.TracingConfig.OpenTelemetryHTTPPassword)
+	assert.Equal(t, "secret", config.MetricsConfig.ConstLabels["key"])
+	assert.Equal(t, "${TEAM}", config.Chains[0].Wallets[0].Labels["team"])
+	assert.Equal(t, []string{"PASSWORD", "TEAM"}, unresolved)
+}
+
+func TestInterpolateEnvSpecialCharacters(t *testing.T) {
+	t.Parallel()
+
+	value := "pass\"word'\\\nnext = \"injected\""
+	lookup := func(name string) (string, bool) {
+		return value, true
+	}
+
+	config := &Config{
+		TracingConfig: TracingConfig{OpenTelemetryHTTPPassword: "${PASSWORD}"},
+	}
+
+	// the value is used as is, without being parsed as a part of the config
+	unresolved := config.InterpolateEnv(lookup)
+	assert.Empty(t, unresolved)
+	assert.Equal(t, value, config.TracingConfig.OpenTelemetryHTTPPassword)
+}
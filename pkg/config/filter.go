@@ -0,0 +1,58 @@
+package config
+
+import "slices"
+
+// Filter returns a copy of the config with only the given chains, and only wallets,
+// applications and suppliers in the given groups. Empty lists match everything.
+// When filtering by group, chain-level Pocket Network params are not queried,
+// as they do not belong to any group, and chains without matching entries are omitted.
+func (c *Config) Filter(chains []string, groups []string) *Config {
+	filtered := *c
+	filtered.Chains = []Chain{}
+
+	for _, chain := range c.Chains {
+		if len(chains) > 0 && !slices.Contains(chains, chain.Name) {
+			continue
+		}
+
+		if len(groups) == 0 {
+			filtered.Chains = append(filtered.Chains, chain)
+			continue
+		}
+
+		filteredChain := chain
+		filteredChain.Wallets = []Wallet{}
+		filteredChain.Applications = []Application{}
+		filteredChain.Suppliers = []Supplier{}
+		filteredChain.PocketParams = false
+		filteredChain.PocketServices = []string{}
+
+		for _, wallet := range chain.Wallets {
+			if slices.Contains(groups, wallet.Group) {
+				filteredChain.Wallets = append(filteredChain.Wallets, wallet)
+			}
+		}
+
+		for _, application := range chain.Applications {
+			if slices.Contains(groups, application.Group) {
+				filteredChain.Applications = append(filteredChain.Applications, application)
+			}
+		}
+
+		for _, supplier := range chain.Suppliers {
+			if slices.Contains(groups, supplier.Group) {
+				filteredChain.Suppliers = append(filteredChain.Suppliers, supplier)
+			}
+		}
+
+		if len(filteredChain.Wallets) == 0 &&
+			len(filteredChain.Applications) == 0 &&
+			len(filteredChain.Suppliers) == 0 {
+			continue
+		}
+
+		filtered.Chains = append(filtered.Chains, filteredChain)
+	}
+
+	return &filtered
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getFilterTestConfig() *Config {
+	return &Config{
+		ListenAddress: ":9550",
+		Chains: []Chain{
+			{
+				Name:           "chain1",
+				Wallets:        []Wallet{{Address: "wallet1", Group: "group1"}, {Address: "wallet2", Group: "group2"}},
+				Applications:   []Application{{Address: "application1", Group: "group1"}},
+				Suppliers:      []Supplier{{Address: "supplier2", Group: "group2"}},
+				PocketParams:   true,
+				PocketServices: []string{"anvil"},
+			},
+			{
+				Name:    "chain2",
+				Wallets: []Wallet{{Address: "wallet3", Group: "group2"}},
+			},
+		},
+	}
+}
+
+func TestConfigFilterNoFilters(t *testing.T) {
+	t.Parallel()
+
+	config := getFilterTestConfig()
+	filtered := config.Filter([]string{}, []string{})
+	assert.Equal(t, config.Chains, filtered.Chains)
+	assert.Equal(t, ":9550", filtered.ListenAddress)
+}
+
+func TestConfigFilterByChain(t *testing.T) {
+	t.Parallel()
+
+	filtered := getFilterTestConfig().Filter([]string{"chain2"}, []string{})
+	require.Len(t, filtered.Chains, 1)
+	assert.Equal(t, "chain2", filtered.Chains[0].Name)
+}
+
+func TestConfigFilterByGroup(t *testing.T) {
+	t.Parallel()
+
+	config := getFilterTestConfig()
+	filtered := config.Filter([]string{}, []string{"group1"})
+	require.Len(t, filtered.Chains, 1)
+
+	chain := filtered.Chains[0]
+	assert.Equal(t, "chain1", chain.Name)
+	require.Len(t, chain.Wallets, 1)
+	assert.Equal(t, "wallet1", chain.Wallets[0].Address)
+	require.Len(t, chain.Applications, 1)
+	assert.Empty(t, chain.Suppliers)
+	assert.False(t, chain.PocketParams)
+
+	// the original config is not modified
+	assert.Len(t, config.Chains[0].Wallets, 2)
+	assert.True(t, config.Chains[0].PocketParams)
+}
+
+func TestConfigFilterByChainAndGroup(t *testing.T) {
+	t.Parallel()
+
+	filtered := getFilterTestConfig().Filter([]string{"chain1"}, []string{"group2"})
+	require.Len(t, filtered.Chains, 1)
+	require.Len(t, filtered.Chains[0].Wallets, 1)
+	assert.Equal(t, "wallet2", filtered.Chains[0].Wallets[0].Address)
+	require.Len(t, filtered.Chains[0].Suppliers, 1)
+}
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+type Format string
+
+const (
+	FormatTOML Format = "toml"
+	FormatYAML Format = "yaml"
+	FormatJSON Format = "json"
+)
+
+// GetFormat returns the config format by the file extension, defaulting to TOML.
+func GetFormat(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".json":
+		return FormatJSON
+	default:
+		return FormatTOML
+	}
+}
+
+// IsConfigFile returns true if the file has an extension of one of the supported formats.
+func IsConfigFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml", ".yaml", ".yml", ".json":
+		return true
+	default:
+		return false
+	}
+}
+
+func ParseFormat(format string) (Format, error) {
+	switch Format(strings.ToLower(format)) {
+	case FormatTOML:
+		return FormatTOML, nil
+	case FormatYAML, "yml":
+		return FormatYAML, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	default:
+		return "", fmt.Errorf("unsupported config format: %s", format)
+	}
+}
+
+// Decode decodes the content in the given format into target. Fields missing
+// in the content are left as is, so it can be used to apply files one on top of another.
+func Decode(content string, format Format, target interface{}) error {
+	switch format {
+	case FormatYAML:
+		return yaml.Unmarshal([]byte(content), target)
+	case FormatJSON:
+		decoder := json.NewDecoder(strings.NewReader(content))
+		decoder.UseNumber()
+		return decoder.Decode(target)
+	default:
+		_, err := toml.Decode(content, target)
+		return err
+	}
+}
+
+// Convert translates a config from one format to another. The config is converted as written,
+// without applying defaults, env variables or secret files, so they do not end up
+// in the result, and is checked to be decodable as a config beforehand.
+func Convert(content []byte, from Format, to Format) ([]byte, error) {
+	if err := Decode(string(content), from, &Config{}); err != nil {
+		return nil, err
+	}
+
+	var values map[string]interface{}
+	if err := Decode(string(content), from, &values); err != nil {
+		return nil, err
+	}
+
+	normalized, err := normalizeValue(values)
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+
+	switch to {
+	case FormatYAML:
+		encoder := yaml.NewEncoder(&buffer)
+		encoder.SetIndent(2)
+		err = encoder.Encode(normalized)
+	case FormatJSON:
+		encoder := json.NewEncoder(&buffer)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		err = encoder.Encode(normalized)
+	default:
+		encoder := toml.NewEncoder(&buffer)
+		encoder.Indent = ""
+		err = encoder.Encode(normalized)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// normalizeValue converts the values decoded from any format into the types
+// every encoder handles the same way: JSON numbers are converted into int64 or float64,
+// and lists of tables into []map[string]interface{}, so TOML writes them as [[tables]].
+func normalizeValue(value interface{}) (interface{}, error) {
+	switch typed := value.(type) {
+	case json.Number:
+		if integer, err := typed.Int64(); err == nil {
+			return integer, nil
+		}
+
+		return typed.Float64()
+	case int:
+		return int64(typed), nil
+	case map[string]interface{}:
+		normalized := make(map[string]interface{}, len(typed))
+		for key, item := range typed {
+			normalizedItem, err := normalizeValue(item)
+			if err != nil {
+				return nil, err
+			}
+
+			normalized[key] = normalizedItem
+		}
+
+		return normalized, nil
+	case []map[string]interface{}:
+		items := make([]interface{}, len(typed))
+		for index, item := range typed {
+			items[index] = item
+		}
+
+		return normalizeValue(items)
+	case []interface{}:
+		normalized := make([]interface{}, len(typed))
+		tables := make([]map[string]interface{}, 0, len(typed))
+
+		for index, item := range typed {
+			normalizedItem, err := normalizeValue(item)
+			if err != nil {
+				return nil, err
+			}
+
+			normalized[index] = normalizedItem
+			if table, ok := normalizedItem.(map[string]interface{}); ok {
+				tables = append(tables, table)
+			}
+		}
+
+		if len(typed) > 0 && len(tables) == len(typed) {
+			return tables, nil
+		}
+
+		return normalized, nil
+	default:
+		return value, nil
+	}
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"main/assets"
+	"main/pkg/fs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetFormat(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, FormatTOML, GetFormat("config.toml"))
+	assert.Equal(t, FormatTOML, GetFormat("config"))
+	assert.Equal(t, FormatYAML, GetFormat("config.yaml"))
+	assert.Equal(t, FormatYAML, GetFormat("config.YML"))
+	assert.Equal(t, FormatJSON, GetFormat("config.json"))
+}
+
+func TestParseFormat(t *testing.T) {
+	t.Parallel()
+
+	format, err := ParseFormat("yml")
+	require.NoError(t, err)
+	assert.Equal(t, FormatYAML, format)
+
+	_, err = ParseFormat("xml")
+	require.Error(t, err)
+	require.ErrorContains(t, err, "unsupported config format: xml")
+}
+
+func TestLoadConfigYAML(t *testing.T) {
+	t.Parallel()
+
+	config, err := GetConfig([]string{"config-valid.yaml"}, "", &fs.TestFS{})
+	require.NoError(t, err)
+	require.NoError(t, config.Validate())
+
+	assert.Equal(t, "debug", config.LogConfig.LogLevel)
+	assert.Equal(t, ":9550", config.ListenAddress)
+	assert.False(t, config.TracingConfig.OpenTelemetryHTTPInsecure.Bool)
+	assert.Equal(t, "https://example.com", config.Chains[0].LCDEndpoint)
+	assert.Equal(t, 6, config.Chains[0].Denoms[0].DenomExponent)
+	assert.Equal(t, []Wallet{{Address: "address", Group: "group", Name: "name"}}, config.Chains[0].Wallets)
+}
+
+func TestLoadConfigJSON(t *testing.T) {
+	t.Parallel()
+
+	config, err := GetConfig([]string{"config-valid.json"}, "", &fs.TestFS{})
+	require.NoError(t, err)
+	require.NoError(t, config.Validate())
+
+	assert.Equal(t, "debug", config.LogConfig.LogLevel)
+	assert.False(t, config.TracingConfig.OpenTelemetryHTTPInsecure.Bool)
+	assert.Equal(t, 18, config.Chains[0].Denoms[0].DenomExponent)
+	assert.Equal(t, []Wallet{{Address: "address", Group: "group", Name: "name"}}, config.Chains[0].Wallets)
+}
+
+func TestLoadConfigExplicitFormat(t *testing.T) {
+	t.Parallel()
+
+	_, err := GetConfig([]string{"config-valid.yaml"}, FormatJSON, &fs.TestFS{})
+	require.Error(t, err)
+}
+
+func TestConvertConfig(t *testing.T) {
+	t.Parallel()
+
+	for _, from := range []string{"config-valid.toml", "config-valid.yaml", "config-valid.json"} {
+		for _, to := range []Format{FormatTOML, FormatYAML, FormatJSON} {
+			content := assets.GetBytesOrPanic(from)
+
+			converted, err := Convert(content, GetFormat(from), to)
+			require.NoError(t, err, from, to)
+
+			expected := &Config{}
+			require.NoError(t, Decode(string(content), GetFormat(from), expected))
+
+			actual := &Config{}
+			require.NoError(t, Decode(string(converted), to, actual), from, to)
+			assert.Equal(t, expected, actual, from, to)
+		}
+	}
+}
+
+func TestConvertConfigKeepsReferences(t *testing.T) {
+	t.Parallel()
+
+	converted, err := Convert(assets.GetBytesOrPanic("config-env.toml"), FormatTOML, FormatYAML)
+	require.NoError(t, err)
+	assert.Contains(t, string(converted), "${COSMOS_WALLETS_EXPORTER_TEST_PASSWORD}")
+}
+
+func TestConvertConfigInvalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := Convert([]byte("chains = 1"), FormatTOML, FormatYAML)
+	require.Error(t, err)
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/guregu/null/v5"
+)
+
+type HistoryConfig struct {
+	Enabled   null.Bool `default:"false"       json:"enabled"   toml:"enabled"   yaml:"enabled"`
+	Path      string    `default:"balances.db" json:"path"      toml:"path"      yaml:"path"`
+	Retention string    `default:"2160h"       json:"retention" toml:"retention" yaml:"retention"`
+	Window    string    `default:"24h"         json:"window"    toml:"window"    yaml:"window"`
+	Interval  string    `default:"5m"          json:"interval"  toml:"interval"  yaml:"interval"`
+}
+
+func (c HistoryConfig) Validate() error {
+	if !c.Enabled.Bool {
+		return nil
+	}
+
+	if c.Path == "" {
+		return errors.New("history path is not specified")
+	}
+
+	retention, err := time.ParseDuration(c.Retention)
+	if err != nil {
+		return fmt.Errorf("invalid history retention: %s", err)
+	}
+
+	window, err := time.ParseDuration(c.Window)
+	if err != nil {
+		return fmt.Errorf("invalid history window: %s", err)
+	}
+
+	if window <= 0 || window > retention {
+		return errors.New("history window should be positive and not longer than retention")
+	}
+
+	interval, err := time.ParseDuration(c.Interval)
+	if err != nil {
+		return fmt.Errorf("invalid history interval: %s", err)
+	}
+
+	if interval <= 0 || interval >= window {
+		return errors.New("history interval should be positive and shorter than window")
+	}
+
+	return nil
+}
+
+func (c HistoryConfig) GetRetention() time.Duration {
+	retention, _ := time.ParseDuration(c.Retention)
+	return retention
+}
+
+func (c HistoryConfig) GetWindow() time.Duration {
+	window, _ := time.ParseDuration(c.Window)
+	return window
+}
+
+func (c HistoryConfig) GetInterval() time.Duration {
+	interval, _ := time.ParseDuration(c.Interval)
+	return interval
+}
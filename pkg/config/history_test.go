@@ -0,0 +1,77 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/guregu/null/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistoryConfigDisabled(t *testing.T) {
+	t.Parallel()
+
+	config := HistoryConfig{Retention: "invalid"}
+	require.NoError(t, config.Validate())
+}
+
+func TestHistoryConfigNoPath(t *testing.T) {
+	t.Parallel()
+
+	config := HistoryConfig{Enabled: null.BoolFrom(true), Retention: "24h", Window: "1h", Interval: "5m"}
+	err := config.Validate()
+	require.Error(t, err)
+	require.ErrorContains(t, err, "history path is not specified")
+}
+
+func TestHistoryConfigInvalidRetention(t *testing.T) {
+	t.Parallel()
+
+	config := HistoryConfig{Enabled: null.BoolFrom(true), Path: "history.db", Retention: "invalid", Window: "1h", Interval: "5m"}
+	err := config.Validate()
+	require.Error(t, err)
+	require.ErrorContains(t, err, "invalid history retention")
+}
+
+func TestHistoryConfigInvalidWindow(t *testing.T) {
+	t.Parallel()
+
+	config := HistoryConfig{Enabled: null.BoolFrom(true), Path: "history.db", Retention: "24h", Window: "invalid", Interval: "5m"}
+	err := config.Validate()
+	require.Error(t, err)
+	require.ErrorContains(t, err, "invalid history window")
+}
+
+func TestHistoryConfigWindowLongerThanRetention(t *testing.T) {
+	t.Parallel()
+
+	config := HistoryConfig{Enabled: null.BoolFrom(true), Path: "history.db", Retention: "1h", Window: "24h", Interval: "5m"}
+	err := config.Validate()
+	require.Error(t, err)
+	require.ErrorContains(t, err, "not longer than retention")
+}
+
+func TestHistoryConfigInvalidInterval(t *testing.T) {
+	t.Parallel()
+
+	config := HistoryConfig{Enabled: null.BoolFrom(true), Path: "history.db", Retention: "24h", Window: "1h", Interval: "invalid"}
+	err := config.Validate()
+	require.Error(t, err)
+	require.ErrorContains(t, err, "invalid history interval")
+
+	config.Interval = "1h"
+	err = config.Validate()
+	require.Error(t, err)
+	require.ErrorContains(t, err, "shorter than window")
+}
+
+func TestHistoryConfigValid(t *testing.T) {
+	t.Parallel()
+
+	config := HistoryConfig{Enabled: null.BoolFrom(true), Path: "history.db", Retention: "24h", Window: "1h", Interval: "5m"}
+	require.NoError(t, config.Validate())
+	assert.Equal(t, 24*time.Hour, config.GetRetention())
+	assert.Equal(t, time.Hour, config.GetWindow())
+	assert.Equal(t, 5*time.Minute, config.GetInterval())
+}
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+var labelNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// Label names of the exporter metrics. Metrics are created with these sets, and the names
+// from all of them are reserved, so custom, const and renamed labels cannot clash with them.
+var (
+	ChainLabelNames                   = []string{"chain"}
+	ChainDenomLabelNames              = []string{"chain", "denom"}
+	ChainParamLabelNames              = []string{"chain", "param"}
+	ChainServiceLabelNames            = []string{"chain", "service_id"}
+	QueryLabelNames                   = []string{"chain", "url"}
+	EndpointLabelNames                = []string{"chain", "endpoint"}
+	EndpointQueryLabelNames           = []string{"chain", "endpoint", "query"}
+	WalletLabelNames                  = []string{"chain", "address", "name", "group"}
+	WalletDenomLabelNames             = []string{"chain", "address", "name", "group", "denom"}
+	TransferLabelNames                = []string{"chain", "address", "name", "group", "denom", "counterparty_group"}
+	SupplierServiceLabelNames         = []string{"chain", "supplier_operator_address", "supplier_owner_address", "supplier_name", "service_id"}
+	RevSharePercentageLabelNames      = []string{"chain", "supplier_operator_address", "supplier_owner_address", "supplier_name", "service_id", "rev_share_address"}
+	RevShareBalanceLabelNames         = []string{"chain", "supplier_operator_address", "supplier_owner_address", "supplier_name", "rev_share_address", "denom"}
+	RevShareBalanceDetailedLabelNames = []string{"chain", "supplier_operator_address", "supplier_owner_address", "supplier_name", "rev_share_address", "service_id", "denom"}
+	ClaimLabelNames                   = []string{"chain", "supplier_operator_address", "supplier_name", "service_id"}
+	SourceLabelNames                  = []string{"chain", "source"}
+	QuerierLabelNames                 = []string{"querier"}
+)
+
+var builtInLabelNameSets = [][]string{
+	ChainLabelNames,
+	ChainDenomLabelNames,
+	ChainParamLabelNames,
+	ChainServiceLabelNames,
+	QueryLabelNames,
+	EndpointLabelNames,
+	EndpointQueryLabelNames,
+	WalletLabelNames,
+	WalletDenomLabelNames,
+	TransferLabelNames,
+	SupplierServiceLabelNames,
+	RevSharePercentageLabelNames,
+	RevShareBalanceLabelNames,
+	RevShareBalanceDetailedLabelNames,
+	ClaimLabelNames,
+	SourceLabelNames,
+	QuerierLabelNames,
+}
+
+// GetBuiltInLabelNames returns the sorted names of all the labels the exporter sets itself.
+func GetBuiltInLabelNames() []string {
+	names := []string{}
+
+	for _, labelNames := range builtInLabelNameSets {
+		for _, name := range labelNames {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// ValidateLabels checks that custom labels names are valid Prometheus label names
+// and do not clash with the labels the exporter sets itself.
+func ValidateLabels(labels map[string]string) error {
+	for name := range labels {
+		if !labelNameRegexp.MatchString(name) || strings.HasPrefix(name, "__") {
+			return fmt.Errorf("invalid label name: %s", name)
+		}
+
+		if slices.Contains(GetBuiltInLabelNames(), name) {
+			return fmt.Errorf("label name is reserved: %s", name)
+		}
+	}
+
+	return nil
+}
+
+// MergeLabels returns chain labels overridden by entry labels.
+func MergeLabels(chainLabels map[string]string, entryLabels map[string]string) map[string]string {
+	merged := make(map[string]string, len(chainLabels)+len(entryLabels))
+
+	for name, value := range chainLabels {
+		merged[name] = value
+	}
+
+	for name, value := range entryLabels {
+		merged[name] = value
+	}
+
+	return merged
+}
+
+// GetLabelNames returns the sorted names of all custom labels used by any chain, wallet,
+// application or supplier. Every metric derived from an entry gets all of them,
+// with empty values for labels the entry does not have, so all the metrics
+// of the same name have the same labels set.
+func (c *Config) GetLabelNames() []string {
+	names := []string{}
+
+	addNames := func(labels map[string]string) {
+		for name := range labels {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	for _, chain := range c.Chains {
+		addNames(chain.Labels)
+
+		for _, wallet := range chain.Wallets {
+			addNames(wallet.Labels)
+		}
+
+		for _, application := range chain.Applications {
+			addNames(application.Labels)
+		}
+
+		for _, supplier := range chain.Suppliers {
+			addNames(supplier.Labels)
+		}
+	}
+
+	sort.Strings(names)
+	return names
+}
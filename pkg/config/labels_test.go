@@ -0,0 +1,84 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateLabels(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, ValidateLabels(nil))
+	require.NoError(t, ValidateLabels(map[string]string{"team": "infra", "_purpose": ""}))
+	require.ErrorContains(t, ValidateLabels(map[string]string{"team-name": "infra"}), "invalid label name: team-name")
+	require.ErrorContains(t, ValidateLabels(map[string]string{"__team": "infra"}), "invalid label name: __team")
+	require.ErrorContains(t, ValidateLabels(map[string]string{"group": "infra"}), "label name is reserved: group")
+	require.ErrorContains(t, ValidateLabels(map[string]string{"url": ""}), "label name is reserved: url")
+	require.ErrorContains(t, ValidateLabels(map[string]string{"querier": ""}), "label name is reserved: querier")
+}
+
+func TestGetBuiltInLabelNames(t *testing.T) {
+	t.Parallel()
+
+	names := GetBuiltInLabelNames()
+	for _, name := range []string{"endpoint", "url", "query", "param", "querier", "source", "rev_share_address"} {
+		assert.Contains(t, names, name)
+	}
+
+	assert.IsNonDecreasing(t, names)
+}
+
+func TestChainInvalidLabels(t *testing.T) {
+	t.Parallel()
+
+	chain := &Chain{
+		Name:        "chain",
+		LCDEndpoint: "test",
+		Wallets:     []Wallet{{Address: "address", Labels: map[string]string{"denom": "atom"}}},
+	}
+	require.ErrorContains(t, chain.Validate(), "error in wallet 0: label name is reserved: denom")
+
+	chain = &Chain{
+		Name:        "chain",
+		LCDEndpoint: "test",
+		Labels:      map[string]string{"1team": "infra"},
+		Wallets:     []Wallet{{Address: "address"}},
+	}
+	require.ErrorContains(t, chain.Validate(), "invalid label name: 1team")
+}
+
+func TestMergeLabels(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(
+		t,
+		map[string]string{"environment": "production", "team": "relayers"},
+		MergeLabels(
+			map[string]string{"environment": "production", "team": "infra"},
+			map[string]string{"team": "relayers"},
+		),
+	)
+	assert.Empty(t, MergeLabels(nil, nil))
+}
+
+func TestGetLabelNames(t *testing.T) {
+	t.Parallel()
+
+	config := &Config{Chains: []Chain{
+		{
+			Name:      "chain1",
+			Labels:    map[string]string{"environment": "production"},
+			Wallets:   []Wallet{{Address: "wallet", Labels: map[string]string{"team": "relayers"}}},
+			Suppliers: []Supplier{{Address: "supplier", Labels: map[string]string{"purpose": "relays"}}},
+		},
+		{
+			Name:         "chain2",
+			Applications: []Application{{Address: "application", Labels: map[string]string{"team": "gateway"}}},
+		},
+	}}
+
+	assert.Equal(t, []string{"environment", "purpose", "team"}, config.GetLabelNames())
+	assert.Empty(t, (&Config{}).GetLabelNames())
+}
@@ -0,0 +1,244 @@
+package config
+
+import (
+	"fmt"
+	"main/pkg/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// loader reads config files, directories and files they include into a single config.
+type loader struct {
+	Filesystem fs.FS
+	// if empty, the format is detected by the file extension
+	Format     Format
+	Config     Config
+	Unresolved []string
+	Loaded     map[string]bool
+}
+
+// LoadPath loads a config file, or all the config files in a directory, in alphabetical order.
+func (l *loader) LoadPath(path string) error {
+	entries, err := l.Filesystem.ReadDir(path)
+	if err != nil {
+		// not a directory, reading it as a file
+		return l.LoadFile(path)
+	}
+
+	names := []string{}
+	for _, entry := range entries {
+		if !entry.IsDir() && IsConfigFile(entry.Name()) {
+			names = append(names, entry.Name())
+		}
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := l.LoadFile(filepath.Join(path, name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// LoadFile decodes a config file on top of the already loaded config: the settings it has
+// override the previous ones, and its chains are merged with the previous ones,
+// then the files it includes are loaded, relative to its directory.
+func (l *loader) LoadFile(path string) error {
+	path = filepath.Clean(path)
+	if l.Loaded[path] {
+		return nil
+	}
+
+	l.Loaded[path] = true
+
+	configBytes, err := l.Filesystem.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	chains := l.Config.Chains
+	l.Config.Chains = nil
+	l.Config.Include = nil
+
+	format := l.Format
+	if format == "" {
+		format = GetFormat(path)
+	}
+
+	if err = Decode(string(configBytes), format, &l.Config); err != nil {
+		return fmt.Errorf("error decoding %s: %s", path, err)
+	}
+
+	l.Config.Chains, err = MergeChains(chains, l.Config.Chains, path)
+	if err != nil {
+		return err
+	}
+
+	includes := l.Config.Include
+	l.Config.Include = nil
+
+	// other values are interpolated once all files are loaded, but includes are needed right away
+	interpolator := &envInterpolator{Lookup: os.LookupEnv, Unresolved: l.Unresolved}
+
+	for _, include := range includes {
+		include = interpolator.Interpolate(include)
+		l.Unresolved = interpolator.Unresolved
+
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(path), include)
+		}
+
+		if err := l.LoadPath(include); err != nil {
+			return fmt.Errorf("error including %s from %s: %s", include, path, err)
+		}
+	}
+
+	return nil
+}
+
+// MergeChains appends chains from a config file to existing ones. A chain without an LCD endpoint
+// is treated as a part of the chain with the same name defined elsewhere, and its denoms, wallets,
+// wallet sources, applications, suppliers, pocket services and labels are added to that chain,
+// so teams can keep their wallets in separate files. Other chain settings can only be set
+// where the LCD endpoint is, so a partial chain having them is rejected instead of them being
+// silently dropped. Denoms and addresses already defined for the chain in other files are
+// rejected too. Chains with the same name both having an LCD endpoint are kept as is,
+// so Validate can report them.
+func MergeChains(existing []Chain, chains []Chain, path string) ([]Chain, error) {
+	for _, chain := range chains {
+		chain.Files = []string{path}
+
+		if chain.IsPartial() {
+			if settings := getChainSettings(chain); len(settings) > 0 {
+				return nil, fmt.Errorf(
+					"chain %s in %s has no LCD endpoint, so it cannot set %s, as they can only be set where the LCD endpoint is",
+					chain.Name,
+					path,
+					strings.Join(settings, ", "),
+				)
+			}
+		}
+
+		merged := false
+
+		for index := range existing {
+			target := &existing[index]
+			if target.Name != chain.Name || !chain.IsPartial() && !target.IsPartial() {
+				continue
+			}
+
+			// entries of the same chain within a file can have duplicates, as they always could
+			if !slices.Contains(target.Files, path) {
+				if err := checkDuplicates(*target, chain); err != nil {
+					return nil, fmt.Errorf(
+						"error merging chain %s from %s into %s: %s",
+						chain.Name,
+						path,
+						strings.Join(target.Files, ", "),
+						err,
+					)
+				}
+
+				target.Files = append(target.Files, path)
+			}
+
+			if target.IsPartial() && !chain.IsPartial() {
+				target.LCDEndpoint = chain.LCDEndpoint
+				target.LCDEndpointFile = chain.LCDEndpointFile
+				target.RevShareDetailedMetrics = chain.RevShareDetailedMetrics
+				target.PocketParams = chain.PocketParams
+				target.PocketClaims = chain.PocketClaims
+				target.VestingMetrics = chain.VestingMetrics
+				target.SequenceMetrics = chain.SequenceMetrics
+				target.LastTxMetrics = chain.LastTxMetrics
+				target.TransferMetrics = chain.TransferMetrics
+			}
+
+			if len(chain.Labels) > 0 {
+				target.Labels = MergeLabels(target.Labels, chain.Labels)
+			}
+
+			target.Denoms = append(target.Denoms, chain.Denoms...)
+			target.Wallets = append(target.Wallets, chain.Wallets...)
+			target.WalletSources = append(target.WalletSources, chain.WalletSources...)
+			target.Applications = append(target.Applications, chain.Applications...)
+			target.Suppliers = append(target.Suppliers, chain.Suppliers...)
+			target.PocketServices = append(target.PocketServices, chain.PocketServices...)
+			merged = true
+			break
+		}
+
+		if !merged {
+			existing = append(existing, chain)
+		}
+	}
+
+	return existing, nil
+}
+
+// getChainSettings returns the names of the settings of a chain that can only be set
+// where its LCD endpoint is.
+func getChainSettings(chain Chain) []string {
+	settings := []string{}
+
+	for name, set := range map[string]bool{
+		"rev-share-detailed-metrics": chain.RevShareDetailedMetrics != nil,
+		"pocket-params":              chain.PocketParams,
+		"pocket-claims":              chain.PocketClaims,
+		"vesting-metrics":            chain.VestingMetrics,
+		"sequence-metrics":           chain.SequenceMetrics,
+		"last-tx-metrics":            chain.LastTxMetrics,
+		"transfer-metrics":           chain.TransferMetrics,
+	} {
+		if set {
+			settings = append(settings, name)
+		}
+	}
+
+	sort.Strings(settings)
+	return settings
+}
+
+// checkDuplicates returns an error if a chain part has denoms or addresses
+// the chain it's merged into already has.
+func checkDuplicates(target Chain, chain Chain) error {
+	for _, denom := range chain.Denoms {
+		for _, existing := range target.Denoms {
+			if existing.Denom == denom.Denom {
+				return fmt.Errorf("duplicate denom: %s", denom.Denom)
+			}
+		}
+	}
+
+	for _, wallet := range chain.Wallets {
+		for _, existing := range target.Wallets {
+			if existing.Address == wallet.Address {
+				return fmt.Errorf("duplicate wallet address: %s", wallet.Address)
+			}
+		}
+	}
+
+	for _, application := range chain.Applications {
+		for _, existing := range target.Applications {
+			if existing.Address == application.Address {
+				return fmt.Errorf("duplicate application address: %s", application.Address)
+			}
+		}
+	}
+
+	for _, supplier := range chain.Suppliers {
+		for _, existing := range target.Suppliers {
+			if existing.Address == supplier.Address {
+				return fmt.Errorf("duplicate supplier address: %s", supplier.Address)
+			}
+		}
+	}
+
+	return nil
+}
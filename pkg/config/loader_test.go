@@ -0,0 +1,124 @@
+package config
+
+import (
+	"main/pkg/fs"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfigDirectory(t *testing.T) {
+	t.Parallel()
+
+	config, err := GetConfig([]string{"config-split-extra.toml", "config-split"}, "", &fs.TestFS{})
+	require.NoError(t, err)
+	require.NoError(t, config.Validate())
+
+	assert.Equal(t, ":9560", config.ListenAddress)
+	assert.Equal(t, "info", config.LogConfig.LogLevel)
+	assert.True(t, config.LogConfig.JSONOutput)
+	assert.Empty(t, config.Include)
+
+	require.Len(t, config.Chains, 2)
+
+	assert.Equal(t, "sentinel", config.Chains[0].Name)
+	assert.Equal(t, "https://lcd-sentinel-app.cosmostation.io", config.Chains[0].LCDEndpoint)
+	assert.Equal(t, []Wallet{
+		{Address: "sent1extra", Group: "extra"},
+		{Address: "sent1team-b", Group: "team-b"},
+	}, config.Chains[0].Wallets)
+
+	assert.Equal(t, "bitsong", config.Chains[1].Name)
+	assert.Equal(t, "https://lcd-bitsong-app.cosmostation.io", config.Chains[1].LCDEndpoint)
+	assert.Len(t, config.Chains[1].Denoms, 1)
+	assert.Equal(t, []Wallet{
+		{Address: "bitsong1team-a", Group: "team-a"},
+		{Address: "bitsong1team-b", Group: "team-b"},
+	}, config.Chains[1].Wallets)
+}
+
+func TestLoadConfigDuplicateChain(t *testing.T) {
+	t.Parallel()
+
+	config, err := GetConfig([]string{"config-split", "config-split-duplicate.toml"}, "", &fs.TestFS{})
+	require.NoError(t, err)
+
+	err = config.Validate()
+	require.Error(t, err)
+	require.ErrorContains(t, err, "duplicate chain name: bitsong (config-split/00-main.toml, config-split/10-team-a.toml, config-split/20-team-b.toml) (config-split-duplicate.toml)")
+}
+
+func TestLoadConfigIncludeNotFound(t *testing.T) {
+	t.Parallel()
+
+	_, err := GetConfig([]string{"config-split-include-not-found.toml"}, "", &fs.TestFS{})
+	require.Error(t, err)
+	require.ErrorContains(t, err, "error including config-not-found.toml from config-split-include-not-found.toml")
+}
+
+func TestMergeChainsPartialFirst(t *testing.T) {
+	t.Parallel()
+
+	chains, err := MergeChains(
+		[]Chain{{Name: "chain", Wallets: []Wallet{{Address: "address1"}}, Files: []string{"a.toml"}}},
+		[]Chain{{Name: "chain", LCDEndpoint: "https://example.com", PocketParams: true, Wallets: []Wallet{{Address: "address2"}}}},
+		"b.toml",
+	)
+	require.NoError(t, err)
+
+	require.Len(t, chains, 1)
+	assert.Equal(t, []string{"a.toml", "b.toml"}, chains[0].Files)
+	assert.Equal(t, "https://example.com", chains[0].LCDEndpoint)
+	assert.True(t, chains[0].PocketParams)
+	assert.Len(t, chains[0].Wallets, 2)
+}
+
+func TestMergeChainsPartialSettings(t *testing.T) {
+	t.Parallel()
+
+	_, err := MergeChains(
+		[]Chain{{Name: "chain", LCDEndpoint: "https://example.com", Files: []string{"a.toml"}}},
+		[]Chain{{Name: "chain", VestingMetrics: true, TransferMetrics: true, Wallets: []Wallet{{Address: "address"}}}},
+		"b.toml",
+	)
+	require.Error(t, err)
+	require.ErrorContains(
+		t,
+		err,
+		"chain chain in b.toml has no LCD endpoint, so it cannot set transfer-metrics, vesting-metrics",
+	)
+}
+
+func TestMergeChainsDuplicates(t *testing.T) {
+	t.Parallel()
+
+	existing := []Chain{{
+		Name:        "chain",
+		LCDEndpoint: "https://example.com",
+		Denoms:      []DenomInfo{{Denom: "uatom"}},
+		Wallets:     []Wallet{{Address: "wallet"}},
+		Suppliers:   []Supplier{{Address: "supplier"}},
+		Files:       []string{"a.toml"},
+	}}
+
+	for expected, chain := range map[string]Chain{
+		"duplicate denom: uatom":               {Name: "chain", Denoms: []DenomInfo{{Denom: "uatom"}}},
+		"duplicate wallet address: wallet":     {Name: "chain", Wallets: []Wallet{{Address: "wallet"}}},
+		"duplicate supplier address: supplier": {Name: "chain", Suppliers: []Supplier{{Address: "supplier"}}},
+	} {
+		_, err := MergeChains(slices.Clone(existing), []Chain{chain}, "b.toml")
+		require.Error(t, err)
+		require.ErrorContains(t, err, "error merging chain chain from b.toml into a.toml: "+expected)
+	}
+
+	// duplicates within the same file are allowed
+	chains, err := MergeChains(
+		slices.Clone(existing),
+		[]Chain{{Name: "chain", Wallets: []Wallet{{Address: "wallet"}}}},
+		"a.toml",
+	)
+	require.NoError(t, err)
+	require.Len(t, chains[0].Wallets, 2)
+}
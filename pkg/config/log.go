@@ -1,6 +1,6 @@
 package config
 
 type LogConfig struct {
-	LogLevel   string `default:"info"  toml:"level"`
-	JSONOutput bool   `default:"false" toml:"json"`
+	LogLevel   string `default:"info"  json:"level" toml:"level" yaml:"level"`
+	JSONOutput bool   `default:"false" json:"json"  toml:"json"  yaml:"json"`
 }
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var metricNamespaceRegexp = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+
+// MetricsConfig changes how metrics are exposed: their namespace (the prefix
+// of all metric names), constant labels added to all of them, and built-in label names.
+type MetricsConfig struct {
+	Namespace    string            `default:"cosmos_wallets_exporter" json:"namespace"     toml:"namespace"     yaml:"namespace"`
+	ConstLabels  map[string]string `json:"const-labels"               toml:"const-labels"  yaml:"const-labels"`
+	RenameLabels map[string]string `json:"rename-labels"              toml:"rename-labels" yaml:"rename-labels"`
+}
+
+func (c MetricsConfig) Validate() error {
+	if c.Namespace != "" && !metricNamespaceRegexp.MatchString(c.Namespace) {
+		return fmt.Errorf("invalid namespace: %s", c.Namespace)
+	}
+
+	if err := ValidateLabels(c.ConstLabels); err != nil {
+		return fmt.Errorf("error in const labels: %s", err)
+	}
+
+	renamed := make([]string, 0, len(c.RenameLabels))
+
+	for from, to := range c.RenameLabels {
+		if !labelNameRegexp.MatchString(from) {
+			return fmt.Errorf("invalid label name: %s", from)
+		}
+
+		if to == "" {
+			return fmt.Errorf("empty new name for label %s", from)
+		}
+
+		if !labelNameRegexp.MatchString(to) || strings.HasPrefix(to, "__") {
+			return fmt.Errorf("invalid label name: %s", to)
+		}
+
+		// checked against the labels of all the metrics, as a metric having both
+		// the renamed label and the one with its new name would get the same label twice
+		if slices.Contains(GetBuiltInLabelNames(), to) {
+			return fmt.Errorf("label %s cannot be renamed to %s, as it's a built-in label", from, to)
+		}
+
+		if _, ok := c.ConstLabels[to]; ok {
+			return fmt.Errorf("label %s cannot be renamed to %s, as it's a const label", from, to)
+		}
+
+		if slices.Contains(renamed, to) {
+			return fmt.Errorf("several labels are renamed to %s", to)
+		}
+
+		renamed = append(renamed, to)
+	}
+
+	return nil
+}
+
+// GetRenamedLabels returns the new names of renamed labels.
+func (c MetricsConfig) GetRenamedLabels() []string {
+	renamed := make([]string, 0, len(c.RenameLabels))
+	for _, to := range c.RenameLabels {
+		renamed = append(renamed, to)
+	}
+
+	return renamed
+}
+
+// ValidateCustomLabelName checks that a custom label does not clash with const labels
+// or renamed built-in labels, so no metric gets the same label twice.
+func (c MetricsConfig) ValidateCustomLabelName(name string) error {
+	if _, ok := c.ConstLabels[name]; ok {
+		return fmt.Errorf("label %s is used both as a custom and a const label", name)
+	}
+
+	if to, ok := c.RenameLabels[name]; ok {
+		return fmt.Errorf("custom label %s cannot be renamed to %s, rename it in the config instead", name, to)
+	}
+
+	if slices.Contains(c.GetRenamedLabels(), name) {
+		return fmt.Errorf("custom label %s clashes with a renamed built-in label", name)
+	}
+
+	return nil
+}
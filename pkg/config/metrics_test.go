@@ -0,0 +1,108 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, MetricsConfig{}.Validate())
+	require.NoError(t, MetricsConfig{
+		Namespace:    "wallets",
+		ConstLabels:  map[string]string{"cluster": "eu-1"},
+		RenameLabels: map[string]string{"chain": "network", "group": "wallet_group"},
+	}.Validate())
+
+	require.ErrorContains(t, MetricsConfig{Namespace: "wallets-exporter"}.Validate(), "invalid namespace")
+	require.ErrorContains(
+		t,
+		MetricsConfig{ConstLabels: map[string]string{"chain": "cosmos"}}.Validate(),
+		"error in const labels: label name is reserved: chain",
+	)
+	require.ErrorContains(
+		t,
+		MetricsConfig{RenameLabels: map[string]string{"chain-name": "network"}}.Validate(),
+		"invalid label name: chain-name",
+	)
+	require.ErrorContains(
+		t,
+		MetricsConfig{RenameLabels: map[string]string{"chain": ""}}.Validate(),
+		"empty new name for label chain",
+	)
+	require.ErrorContains(
+		t,
+		MetricsConfig{RenameLabels: map[string]string{"chain": "1network"}}.Validate(),
+		"invalid label name: 1network",
+	)
+	require.ErrorContains(
+		t,
+		MetricsConfig{RenameLabels: map[string]string{"chain": "group"}}.Validate(),
+		"label chain cannot be renamed to group, as it's a built-in label",
+	)
+	for _, name := range []string{"endpoint", "url", "query", "param", "querier", "source"} {
+		require.ErrorContains(
+			t,
+			MetricsConfig{RenameLabels: map[string]string{"chain": name}}.Validate(),
+			"label chain cannot be renamed to "+name+", as it's a built-in label",
+		)
+	}
+	require.ErrorContains(
+		t,
+		MetricsConfig{RenameLabels: map[string]string{"chain": "__network"}}.Validate(),
+		"invalid label name: __network",
+	)
+	require.ErrorContains(
+		t,
+		MetricsConfig{
+			ConstLabels:  map[string]string{"network": "mainnet"},
+			RenameLabels: map[string]string{"chain": "network"},
+		}.Validate(),
+		"label chain cannot be renamed to network, as it's a const label",
+	)
+	require.ErrorContains(
+		t,
+		MetricsConfig{RenameLabels: map[string]string{"chain": "network", "denom": "network"}}.Validate(),
+		"several labels are renamed to network",
+	)
+}
+
+func TestConfigMetricsLabelsClash(t *testing.T) {
+	t.Parallel()
+
+	getConfig := func(metricsConfig MetricsConfig) *Config {
+		return &Config{
+			Chains: []Chain{{
+				Name:        "chain",
+				LCDEndpoint: "test",
+				Labels:      map[string]string{"team": "infra"},
+				Wallets:     []Wallet{{Address: "address"}},
+			}},
+			MetricsConfig: metricsConfig,
+		}
+	}
+
+	require.NoError(t, getConfig(MetricsConfig{ConstLabels: map[string]string{"cluster": "eu-1"}}).Validate())
+	require.ErrorContains(
+		t,
+		getConfig(MetricsConfig{Namespace: "-"}).Validate(),
+		"error in metrics config: invalid namespace",
+	)
+	require.ErrorContains(
+		t,
+		getConfig(MetricsConfig{ConstLabels: map[string]string{"team": "infra"}}).Validate(),
+		"label team is used both as a custom and a const label",
+	)
+	require.ErrorContains(
+		t,
+		getConfig(MetricsConfig{RenameLabels: map[string]string{"team": "owner"}}).Validate(),
+		"custom label team cannot be renamed to owner",
+	)
+	require.ErrorContains(
+		t,
+		getConfig(MetricsConfig{RenameLabels: map[string]string{"group": "team"}}).Validate(),
+		"custom label team clashes with a renamed built-in label",
+	)
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+type ReadinessConfig struct {
+	MinSuccessRatio float64 `default:"0.5" json:"min-success-ratio" toml:"min-success-ratio" yaml:"min-success-ratio"`
+	MaxAge          string  `default:"5m"  json:"max-age"           toml:"max-age"           yaml:"max-age"`
+}
+
+func (c ReadinessConfig) Validate() error {
+	if c.MinSuccessRatio < 0 || c.MinSuccessRatio > 1 {
+		return errors.New("min success ratio should be between 0 and 1")
+	}
+
+	if c.MaxAge != "" {
+		maxAge, err := time.ParseDuration(c.MaxAge)
+		if err != nil {
+			return fmt.Errorf("invalid readiness max age: %s", err)
+		}
+
+		if maxAge < 0 {
+			return errors.New("readiness max age should not be negative")
+		}
+	}
+
+	return nil
+}
+
+// GetMaxAge returns how long ago a chain could be scraped the last time to still be
+// considered healthy, zero means scrapes of any age are fine.
+func (c ReadinessConfig) GetMaxAge() time.Duration {
+	maxAge, _ := time.ParseDuration(c.MaxAge)
+	return maxAge
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"fmt"
+	"main/pkg/fs"
+	"strings"
+)
+
+// LoadSecretFiles fills the secret fields from their *-file variants, so secrets can be
+// mounted as files instead of being written into the config. Errors never contain secret values.
+func (c *Config) LoadSecretFiles(filesystem fs.FS) error {
+	if err := loadSecretFile(
+		filesystem,
+		"open-telemetry-http-password",
+		c.TracingConfig.OpenTelemetryHTTPPasswordFile,
+		&c.TracingConfig.OpenTelemetryHTTPPassword,
+	); err != nil {
+		return fmt.Errorf("error in tracing config: %s", err)
+	}
+
+	for index := range c.Chains {
+		chain := &c.Chains[index]
+		if err := loadSecretFile(filesystem, "lcd-endpoint", chain.LCDEndpointFile, &chain.LCDEndpoint); err != nil {
+			return fmt.Errorf("error in chain %d: %s", index, err)
+		}
+
+		if chain.LCDEndpointFile != "" {
+			chain.SecretLCDEndpoint = true
+		}
+	}
+
+	return nil
+}
+
+func loadSecretFile(filesystem fs.FS, field string, path string, target *string) error {
+	if path == "" {
+		return nil
+	}
+
+	if *target != "" {
+		return fmt.Errorf("both %s and %s-file are provided", field, field)
+	}
+
+	content, err := filesystem.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("could not read %s-file: %s", field, err)
+	}
+
+	*target = strings.TrimSpace(string(content))
+	return nil
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"main/pkg/fs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadSecretFilesOk(t *testing.T) {
+	t.Parallel()
+
+	config, err := GetConfig([]string{"config-secret-files.toml"}, "", &fs.TestFS{})
+	require.NoError(t, err)
+	require.NoError(t, config.Validate())
+
+	assert.Equal(t, "password", config.TracingConfig.OpenTelemetryHTTPPassword)
+	assert.Equal(t, "https://example.com/api-key", config.Chains[0].LCDEndpoint)
+	assert.Equal(t, "https://example.com/<redacted>", config.Chains[0].GetDisplayLCDEndpoint())
+}
+
+func TestLoadSecretFilesBothProvided(t *testing.T) {
+	t.Parallel()
+
+	config := &Config{Chains: []Chain{{
+		LCDEndpoint:     "https://example.com",
+		LCDEndpointFile: "secret-lcd-endpoint.txt",
+	}}}
+
+	err := config.LoadSecretFiles(&fs.TestFS{})
+	require.Error(t, err)
+	require.ErrorContains(t, err, "both lcd-endpoint and lcd-endpoint-file are provided")
+}
+
+func TestLoadSecretFilesNotFound(t *testing.T) {
+	t.Parallel()
+
+	config := &Config{TracingConfig: TracingConfig{OpenTelemetryHTTPPasswordFile: "not-found.txt"}}
+
+	err := config.LoadSecretFiles(&fs.TestFS{})
+	require.Error(t, err)
+	require.ErrorContains(t, err, "could not read open-telemetry-http-password-file")
+}
@@ -5,9 +5,10 @@ import (
 )
 
 type Supplier struct {
-	Address string `toml:"address"`
-	Name    string `toml:"name"`
-	Group   string `toml:"group"`
+	Address string            `json:"address" toml:"address" yaml:"address"`
+	Name    string            `json:"name"    toml:"name"    yaml:"name"`
+	Group   string            `json:"group"   toml:"group"   yaml:"group"`
+	Labels  map[string]string `json:"labels"  toml:"labels"  yaml:"labels"`
 }
 
 func (s Supplier) Validate() error {
@@ -15,5 +16,9 @@ func (s Supplier) Validate() error {
 		return errors.New("address for supplier is not specified")
 	}
 
+	if err := ValidateLabels(s.Labels); err != nil {
+		return err
+	}
+
 	return nil
 }
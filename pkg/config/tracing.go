@@ -3,9 +3,10 @@ package config
 import "github.com/guregu/null/v5"
 
 type TracingConfig struct {
-	Enabled                   null.Bool `default:"false"                     toml:"enabled"`
-	OpenTelemetryHTTPHost     string    `toml:"open-telemetry-http-host"`
-	OpenTelemetryHTTPInsecure null.Bool `default:"true"                      toml:"open-telemetry-http-insecure"`
-	OpenTelemetryHTTPUser     string    `toml:"open-telemetry-http-user"`
-	OpenTelemetryHTTPPassword string    `toml:"open-telemetry-http-password"`
+	Enabled                       null.Bool `default:"false"                          json:"enabled"                           toml:"enabled"                           yaml:"enabled"`
+	OpenTelemetryHTTPHost         string    `json:"open-telemetry-http-host"          toml:"open-telemetry-http-host"          yaml:"open-telemetry-http-host"`
+	OpenTelemetryHTTPInsecure     null.Bool `default:"true"                           json:"open-telemetry-http-insecure"      toml:"open-telemetry-http-insecure"      yaml:"open-telemetry-http-insecure"`
+	OpenTelemetryHTTPUser         string    `json:"open-telemetry-http-user"          toml:"open-telemetry-http-user"          yaml:"open-telemetry-http-user"`
+	OpenTelemetryHTTPPassword     string    `json:"open-telemetry-http-password"      toml:"open-telemetry-http-password"      yaml:"open-telemetry-http-password"`
+	OpenTelemetryHTTPPasswordFile string    `json:"open-telemetry-http-password-file" toml:"open-telemetry-http-password-file" yaml:"open-telemetry-http-password-file"`
 }
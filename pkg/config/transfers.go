@@ -0,0 +1,43 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// TransfersConfig configures the transfers indexer, which counts tokens sent and received
+// by wallets of chains with transfer metrics enabled. MaxHeightsPerRun limits the heights
+// range of a wallet indexed at once, so a wallet far behind, like after a long downtime,
+// catches up over several runs instead of one that never finishes.
+type TransfersConfig struct {
+	Interval         string `default:"1m"    json:"interval"            toml:"interval"            yaml:"interval"`
+	PageSize         int    `default:"100"   json:"page-size"           toml:"page-size"           yaml:"page-size"`
+	MaxHeightsPerRun int64  `default:"10000" json:"max-heights-per-run" toml:"max-heights-per-run" yaml:"max-heights-per-run"`
+}
+
+func (c TransfersConfig) Validate() error {
+	interval, err := time.ParseDuration(c.Interval)
+	if err != nil {
+		return fmt.Errorf("invalid transfers interval: %s", err)
+	}
+
+	if interval <= 0 {
+		return errors.New("transfers interval should be positive")
+	}
+
+	if c.PageSize <= 0 {
+		return errors.New("transfers page size should be positive")
+	}
+
+	if c.MaxHeightsPerRun <= 0 {
+		return errors.New("transfers max heights per run should be positive")
+	}
+
+	return nil
+}
+
+func (c TransfersConfig) GetInterval() time.Duration {
+	interval, _ := time.ParseDuration(c.Interval)
+	return interval
+}
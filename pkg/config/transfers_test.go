@@ -0,0 +1,50 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransfersConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, TransfersConfig{Interval: "1m", PageSize: 100, MaxHeightsPerRun: 1000}.Validate())
+	require.ErrorContains(t, TransfersConfig{Interval: "invalid", PageSize: 100, MaxHeightsPerRun: 1000}.Validate(), "invalid transfers interval")
+	require.ErrorContains(t, TransfersConfig{Interval: "0s", PageSize: 100, MaxHeightsPerRun: 1000}.Validate(), "transfers interval should be positive")
+	require.ErrorContains(t, TransfersConfig{Interval: "1m"}.Validate(), "transfers page size should be positive")
+	require.ErrorContains(
+		t,
+		TransfersConfig{Interval: "1m", PageSize: 100}.Validate(),
+		"transfers max heights per run should be positive",
+	)
+}
+
+func TestTransfersConfigGetInterval(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 30*time.Second, TransfersConfig{Interval: "30s"}.GetInterval())
+}
+
+func TestConfigInvalidTransfers(t *testing.T) {
+	t.Parallel()
+
+	config := &Config{
+		Chains: []Chain{{
+			Name:        "chain",
+			LCDEndpoint: "test",
+			Wallets:     []Wallet{{Address: "address"}},
+		}},
+		TransfersConfig: TransfersConfig{Interval: "invalid"},
+	}
+
+	// not validated if no chain has transfer metrics enabled
+	require.NoError(t, config.Validate())
+	assert.False(t, config.HasTransferMetrics())
+
+	config.Chains[0].TransferMetrics = true
+	assert.True(t, config.HasTransferMetrics())
+	require.ErrorContains(t, config.Validate(), "error in transfers config: invalid transfers interval")
+}
@@ -9,11 +9,16 @@ func (w Wallet) Validate() error {
 		return errors.New("address for wallet is not specified")
 	}
 
+	if err := ValidateLabels(w.Labels); err != nil {
+		return err
+	}
+
 	return nil
 }
 
 type Wallet struct {
-	Address string `toml:"address"`
-	Name    string `toml:"name"`
-	Group   string `toml:"group"`
+	Address string            `json:"address" toml:"address" yaml:"address"`
+	Name    string            `json:"name"    toml:"name"    yaml:"name"`
+	Group   string            `json:"group"   toml:"group"   yaml:"group"`
+	Labels  map[string]string `json:"labels"  toml:"labels"  yaml:"labels"`
 }
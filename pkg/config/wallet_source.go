@@ -0,0 +1,108 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	WalletSourceFormatCSV  = "csv"
+	WalletSourceFormatJSON = "json"
+)
+
+// WalletSource is a file or an HTTP URL with a list of wallets, loaded in addition
+// to the wallets listed in the config.
+type WalletSource struct {
+	Name            string `json:"name"   toml:"name"             yaml:"name"`
+	File            string `json:"file"   toml:"file"             yaml:"file"`
+	URL             string `json:"url"    toml:"url"              yaml:"url"`
+	Format          string `json:"format" toml:"format"           yaml:"format"`
+	Group           string `json:"group"  toml:"group"            yaml:"group"`
+	RefreshInterval string `default:"5m"  json:"refresh-interval" toml:"refresh-interval" yaml:"refresh-interval"`
+}
+
+func (s WalletSource) Validate() error {
+	if s.File == "" && s.URL == "" {
+		return errors.New("neither file nor url is specified")
+	}
+
+	if s.File != "" && s.URL != "" {
+		return errors.New("both file and url are specified")
+	}
+
+	if s.URL != "" {
+		// the parse error has the URL in it, which may have credentials
+		if _, err := url.ParseRequestURI(s.URL); err != nil {
+			var urlErr *url.Error
+			if errors.As(err, &urlErr) {
+				err = urlErr.Err
+			}
+
+			return fmt.Errorf("invalid url: %s", err)
+		}
+	}
+
+	switch s.Format {
+	case "", WalletSourceFormatJSON:
+	case WalletSourceFormatCSV:
+		if s.URL != "" {
+			return errors.New("csv format is only supported for files")
+		}
+	default:
+		return fmt.Errorf("unsupported format: %s", s.Format)
+	}
+
+	if s.RefreshInterval != "" {
+		interval, err := time.ParseDuration(s.RefreshInterval)
+		if err != nil {
+			return fmt.Errorf("invalid refresh interval: %s", err)
+		}
+
+		if interval <= 0 {
+			return errors.New("refresh interval should be positive")
+		}
+	}
+
+	return nil
+}
+
+// GetName returns the name used in metrics labels: the configured one, or the file path,
+// or the URL host, as the URL itself may contain credentials.
+func (s WalletSource) GetName() string {
+	if s.Name != "" {
+		return s.Name
+	}
+
+	if s.File != "" {
+		return s.File
+	}
+
+	if parsed, err := url.Parse(s.URL); err == nil {
+		return parsed.Host
+	}
+
+	return ""
+}
+
+// GetFormat returns the configured format, or detects it by the file extension.
+// URLs are always expected to return JSON.
+func (s WalletSource) GetFormat() string {
+	if s.Format != "" {
+		return s.Format
+	}
+
+	if strings.EqualFold(filepath.Ext(s.File), ".csv") {
+		return WalletSourceFormatCSV
+	}
+
+	return WalletSourceFormatJSON
+}
+
+func (s WalletSource) GetRefreshInterval() time.Duration {
+	interval, _ := time.ParseDuration(s.RefreshInterval)
+	return interval
+}
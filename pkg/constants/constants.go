@@ -1,5 +1,6 @@
 package constants
 
 const (
-	HeaderBlockHeight = "Grpc-Metadata-X-Cosmos-Block-Height"
+	HeaderBlockHeight   = "Grpc-Metadata-X-Cosmos-Block-Height"
+	HeaderScrapeTimeout = "X-Prometheus-Scrape-Timeout-Seconds"
 )
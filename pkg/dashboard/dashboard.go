@@ -0,0 +1,160 @@
+package dashboard
+
+import (
+	"html/template"
+	"io"
+	"main/assets"
+	"main/pkg/config"
+	"main/pkg/status"
+	"sort"
+	"time"
+)
+
+const templateName = "dashboard.html"
+
+// Dashboard renders a human-readable overview of the latest scrape results
+// for every configured chain, wallet, application and supplier.
+type Dashboard struct {
+	Template *template.Template
+}
+
+type amountView struct {
+	Denom  string
+	Amount float64
+}
+
+type entryView struct {
+	Type     string
+	Address  string
+	Name     string
+	Group    string
+	Balances []amountView
+	Stake    []amountView
+	Queries  int
+	Errors   []string
+}
+
+type chainView struct {
+	Name        string
+	LCDEndpoint string
+	LastScrape  *time.Time
+	Age         time.Duration
+	Prices      []amountView
+	Entries     []entryView
+	Queries     int
+	Errors      int
+}
+
+type pageView struct {
+	Time   time.Time
+	Chains []chainView
+}
+
+func NewDashboard() *Dashboard {
+	return &Dashboard{
+		Template: template.Must(template.ParseFS(assets.EmbedFS, templateName)),
+	}
+}
+
+// Render renders the page for the given config, as it may include wallets
+// loaded from wallet sources, and the latest scrape results.
+func (d *Dashboard) Render(w io.Writer, appConfig *config.Config, snapshot *status.Snapshot) error {
+	page := pageView{
+		Time:   snapshot.Time,
+		Chains: make([]chainView, len(appConfig.Chains)),
+	}
+
+	for index, chain := range appConfig.Chains {
+		chainStatus := status.NewResponse(snapshot, status.Filter{Chain: chain.Name})
+
+		chainPage := chainView{
+			Name:        chain.Name,
+			LCDEndpoint: chain.GetDisplayLCDEndpoint(),
+			Prices:      getAmounts(chainStatus.Metrics["price"]),
+			Queries:     len(chainStatus.Queries),
+		}
+
+		// chains can be scraped at different times by filtered scrapes
+		if lastScrape, ok := snapshot.ChainTimes[chain.Name]; ok {
+			chainPage.LastScrape = &lastScrape
+			chainPage.Age = time.Since(lastScrape).Round(time.Second)
+		}
+
+		for _, query := range chainStatus.Queries {
+			if !query.Success {
+				chainPage.Errors++
+			}
+		}
+
+		for _, wallet := range chain.Wallets {
+			chainPage.Entries = append(chainPage.Entries, getEntry(
+				snapshot, chain.Name, "wallet", wallet.Address, wallet.Name, wallet.Group, "",
+			))
+		}
+
+		for _, application := range chain.Applications {
+			chainPage.Entries = append(chainPage.Entries, getEntry(
+				snapshot, chain.Name, "application", application.Address, application.Name, application.Group, "application_stake",
+			))
+		}
+
+		for _, supplier := range chain.Suppliers {
+			chainPage.Entries = append(chainPage.Entries, getEntry(
+				snapshot, chain.Name, "supplier", supplier.Address, supplier.Name, supplier.Group, "supplier_stake",
+			))
+		}
+
+		page.Chains[index] = chainPage
+	}
+
+	return d.Template.Execute(w, page)
+}
+
+func getEntry(
+	snapshot *status.Snapshot,
+	chain string,
+	entryType string,
+	address string,
+	name string,
+	group string,
+	stakeMetric string,
+) entryView {
+	entryStatus := status.NewResponse(snapshot, status.Filter{Chain: chain, Address: address})
+
+	entry := entryView{
+		Type:     entryType,
+		Address:  address,
+		Name:     name,
+		Group:    group,
+		Balances: getAmounts(entryStatus.Metrics["balance"]),
+		Queries:  len(entryStatus.Queries),
+	}
+
+	if stakeMetric != "" {
+		entry.Stake = getAmounts(entryStatus.Metrics[stakeMetric])
+	}
+
+	for _, query := range entryStatus.Queries {
+		if !query.Success && query.Error != "" {
+			entry.Errors = append(entry.Errors, query.Error)
+		}
+	}
+
+	return entry
+}
+
+func getAmounts(metrics []status.Metric) []amountView {
+	amounts := make([]amountView, len(metrics))
+	for index, metric := range metrics {
+		amounts[index] = amountView{
+			Denom:  metric.Labels["denom"],
+			Amount: metric.Value,
+		}
+	}
+
+	sort.Slice(amounts, func(i, j int) bool {
+		return amounts[i].Denom < amounts[j].Denom
+	})
+
+	return amounts
+}
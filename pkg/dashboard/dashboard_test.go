@@ -0,0 +1,72 @@
+package dashboard
+
+import (
+	"bytes"
+	configPkg "main/pkg/config"
+	"main/pkg/status"
+	"main/pkg/types"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDashboardRender(t *testing.T) {
+	t.Parallel()
+
+	config := &configPkg.Config{Chains: []configPkg.Chain{{
+		Name:         "chain",
+		LCDEndpoint:  "https://example.com",
+		Wallets:      []configPkg.Wallet{{Address: "wallet", Name: "wallet-name", Group: "group"}},
+		Applications: []configPkg.Application{{Address: "application", Name: "application-name"}},
+		Suppliers:    []configPkg.Supplier{{Address: "supplier", Name: "supplier-name"}},
+	}}}
+
+	balanceGauge := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{Name: "cosmos_wallets_exporter_balance"},
+		[]string{"chain", "address", "name", "group", "denom"},
+	)
+	balanceGauge.With(prometheus.Labels{
+		"chain": "chain", "address": "wallet", "name": "wallet-name", "group": "group", "denom": "atom",
+	}).Set(1.5)
+
+	stakeGauge := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{Name: "cosmos_wallets_exporter_application_stake"},
+		[]string{"chain", "address", "name", "group", "denom"},
+	)
+	stakeGauge.With(prometheus.Labels{
+		"chain": "chain", "address": "application", "name": "application-name", "group": "", "denom": "pokt",
+	}).Set(12345)
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(balanceGauge, stakeGauge)
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	snapshot := &status.Snapshot{
+		Time:       time.Now(),
+		Families:   families,
+		ChainTimes: map[string]time.Time{"chain": time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC)},
+		QueryInfos: []types.QueryInfo{
+			{Chain: "chain", URL: "https://example.com/balances/wallet", Success: true},
+			{Chain: "chain", URL: "https://example.com/supplier/supplier", Error: "<custom error>"},
+		},
+	}
+
+	var buffer bytes.Buffer
+	err = NewDashboard().Render(&buffer, config, snapshot)
+	require.NoError(t, err)
+
+	page := buffer.String()
+	assert.Contains(t, page, "wallet-name")
+	assert.Contains(t, page, "1.500000 atom")
+	assert.Contains(t, page, "12345.000000 pokt")
+	assert.Contains(t, page, "failed: 1")
+	assert.Contains(t, page, "last queried: 2024-01-31 12:00:00 UTC")
+	assert.Contains(t, page, "not queried")
+	// errors are escaped
+	assert.Contains(t, page, "&lt;custom error&gt;")
+}
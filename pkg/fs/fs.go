@@ -1,5 +1,8 @@
 package fs
 
+import "os"
+
 type FS interface {
 	ReadFile(name string) ([]byte, error)
+	ReadDir(name string) ([]os.DirEntry, error)
 }
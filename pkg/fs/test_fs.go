@@ -2,6 +2,7 @@ package fs
 
 import (
 	"main/assets"
+	"os"
 )
 
 type TestFS struct{}
@@ -9,3 +10,7 @@ type TestFS struct{}
 func (fs *TestFS) ReadFile(name string) ([]byte, error) {
 	return assets.EmbedFS.ReadFile(name)
 }
+
+func (fs *TestFS) ReadDir(name string) ([]os.DirEntry, error) {
+	return assets.EmbedFS.ReadDir(name)
+}
@@ -0,0 +1,415 @@
+package history
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var balancesBucket = []byte("balances")
+
+// how often old samples are removed, no need to do it on every scrape
+const pruneInterval = time.Hour
+
+const keySeparator = 0x00
+
+// Sample is a single wallet balance observed at some point in time,
+// with the same labels as the balance metric has.
+type Sample struct {
+	Time    time.Time `json:"time"`
+	Chain   string    `json:"chain"`
+	Address string    `json:"address"`
+	Name    string    `json:"name"`
+	Group   string    `json:"group"`
+	Denom   string    `json:"denom"`
+	Amount  float64   `json:"amount"`
+}
+
+// storedSample is a sample with the total amount spent by the wallet since its first sample,
+// so the amount spent within a window is a difference between the window boundaries.
+type storedSample struct {
+	Sample
+	Spent float64 `json:"spent"`
+}
+
+// Store keeps balance samples in an embedded BoltDB file, so the exporter can calculate
+// how balances change over time without relying on Prometheus retention.
+// Samples are keyed by chain, address, denom and big-endian time, so the samples of a wallet
+// and denom (a series) are sorted by time and a time range of a series is a single seek.
+// At most one sample per interval is stored for each series, however often it's scraped.
+type Store struct {
+	DB        *bolt.DB
+	Retention time.Duration
+	Interval  time.Duration
+	LastPrune time.Time
+	Mutex     sync.Mutex
+}
+
+func NewStore(path string, retention time.Duration, interval time.Duration) (*Store, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(balancesBucket)
+		return err
+	}); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return &Store{DB: db, Retention: retention, Interval: interval}, nil
+}
+
+func (s *Store) Close() error {
+	return s.DB.Close()
+}
+
+// Record stores the samples that are at least an interval newer than the latest stored
+// sample of their series, and removes the ones older than retention, if it wasn't done recently.
+func (s *Store) Record(samples []Sample) error {
+	if err := s.DB.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(balancesBucket)
+
+		for _, sample := range samples {
+			stored := storedSample{Sample: sample}
+
+			last, found, err := getLastSample(bucket.Cursor(), sample.Chain, sample.Address, sample.Denom, sample.Time)
+			if err != nil {
+				return err
+			}
+
+			if found {
+				if sample.Time.Sub(last.Time) < s.Interval {
+					continue
+				}
+
+				stored.Spent = last.Spent + max(last.Amount-sample.Amount, 0)
+			}
+
+			value, err := json.Marshal(stored)
+			if err != nil {
+				return err
+			}
+
+			key := getKey(sample.Chain, sample.Address, sample.Denom, sample.Time)
+			if err := bucket.Put(key, value); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	s.Mutex.Lock()
+	shouldPrune := now.Sub(s.LastPrune) >= pruneInterval
+	if shouldPrune {
+		s.LastPrune = now
+	}
+	s.Mutex.Unlock()
+
+	if !shouldPrune {
+		return nil
+	}
+
+	return s.Prune(now.Add(-s.Retention))
+}
+
+// Query returns the samples of a wallet and denom within [from, to], sorted by time.
+func (s *Store) Query(chain, address, denom string, from, to time.Time) ([]Sample, error) {
+	samples := []Sample{}
+
+	err := s.DB.View(func(tx *bolt.Tx) error {
+		return queryRange(tx.Bucket(balancesBucket).Cursor(), getPrefix(chain, address, denom), from, to, &samples)
+	})
+
+	return samples, err
+}
+
+// GetStats calculates how the balance of the sample wallet and denom changed between
+// the first stored sample within [from, sample time) and the sample itself. Only these two
+// samples and the latest one stored before the sample are read, however long the window is.
+// Returns false if there is no earlier sample within the window.
+func (s *Store) GetStats(sample Sample, from time.Time) (Stats, bool, error) {
+	var stats Stats
+	var ok bool
+
+	err := s.DB.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(balancesBucket)
+		prefix := getPrefix(sample.Chain, sample.Address, sample.Denom)
+
+		key, value := bucket.Cursor().Seek(getKey(sample.Chain, sample.Address, sample.Denom, from))
+		if key == nil || !bytes.HasPrefix(key, prefix) || !getKeyTime(key).Before(sample.Time) {
+			return nil
+		}
+
+		var first storedSample
+		if err := json.Unmarshal(value, &first); err != nil {
+			return err
+		}
+
+		// the sample may be not stored, if there is a recent enough one already
+		last, _, err := getLastSample(bucket.Cursor(), sample.Chain, sample.Address, sample.Denom, sample.Time)
+		if err != nil {
+			return err
+		}
+
+		current := storedSample{
+			Sample: sample,
+			Spent:  last.Spent + max(last.Amount-sample.Amount, 0),
+		}
+
+		stats, ok = getStats(first, current)
+		return nil
+	})
+
+	return stats, ok, err
+}
+
+// Filter limits the series returned by QueryAll and Latest. With a chain set, only the keys
+// of that chain (and address, if set) are read, as they share the key prefix. With an address
+// only, the series of other addresses are skipped with a seek each, without reading their samples.
+type Filter struct {
+	Chain   string
+	Address string
+}
+
+func (f Filter) getPrefix() []byte {
+	if f.Chain == "" {
+		return nil
+	}
+
+	prefix := append([]byte(f.Chain), keySeparator)
+	if f.Address == "" {
+		return prefix
+	}
+
+	return append(append(prefix, f.Address...), keySeparator)
+}
+
+// QueryAll returns the samples of all wallets and denoms matching the filter within [from, to],
+// sorted by chain, address, denom and then time.
+func (s *Store) QueryAll(filter Filter, from, to time.Time) ([]Sample, error) {
+	samples := []Sample{}
+
+	err := s.DB.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(balancesBucket).Cursor()
+
+		return forEachSeries(cursor, filter, func(prefix []byte) error {
+			return queryRange(cursor, prefix, from, to, &samples)
+		})
+	})
+
+	return samples, err
+}
+
+// Latest returns the latest sample of each wallet and denom matching the filter.
+func (s *Store) Latest(filter Filter) ([]Sample, error) {
+	samples := []Sample{}
+
+	err := s.DB.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(balancesBucket).Cursor()
+
+		return forEachSeries(cursor, filter, func(prefix []byte) error {
+			// the latest sample of a series is the one right before the next series
+			key, value := cursor.Seek(getNextSeriesKey(prefix))
+			if key == nil {
+				key, value = cursor.Last()
+			} else {
+				key, value = cursor.Prev()
+			}
+
+			if key == nil || !bytes.HasPrefix(key, prefix) {
+				return nil
+			}
+
+			var sample storedSample
+			if err := json.Unmarshal(value, &sample); err != nil {
+				return err
+			}
+
+			samples = append(samples, sample.Sample)
+			return nil
+		})
+	})
+
+	return samples, err
+}
+
+// forEachSeries calls the callback with the prefix of each series matching the filter,
+// seeking from one series to the next one, so only the callback reads the samples.
+func forEachSeries(cursor *bolt.Cursor, filter Filter, callback func(prefix []byte) error) error {
+	start := filter.getPrefix()
+
+	for key, _ := cursor.Seek(start); key != nil && bytes.HasPrefix(key, start); {
+		prefix := bytes.Clone(getKeyPrefix(key))
+
+		if filter.Address == "" || getPrefixAddress(prefix) == filter.Address {
+			if err := callback(prefix); err != nil {
+				return err
+			}
+		}
+
+		key, _ = cursor.Seek(getNextSeriesKey(prefix))
+	}
+
+	return nil
+}
+
+// Prune removes all the samples recorded before the given time.
+func (s *Store) Prune(before time.Time) error {
+	return s.DB.Update(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(balancesBucket).Cursor()
+
+		for key, _ := cursor.First(); key != nil; key, _ = cursor.Seek(getNextSeriesKey(getKeyPrefix(key))) {
+			// samples of a series are sorted by time, so the old ones are at its start
+			for getKeyTime(key).Before(before) {
+				deletedKey := bytes.Clone(key)
+				if err := cursor.Delete(); err != nil {
+					return err
+				}
+
+				// deleting moves the cursor, so seeking to the next key explicitly
+				key, _ = cursor.Seek(deletedKey)
+				if key == nil || !bytes.HasPrefix(key, getKeyPrefix(deletedKey)) {
+					key = deletedKey
+					break
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+// queryRange appends the samples of a series within [from, to] to samples.
+func queryRange(cursor *bolt.Cursor, prefix []byte, from, to time.Time, samples *[]Sample) error {
+	start := binary.BigEndian.AppendUint64(bytes.Clone(prefix), uint64(from.UnixNano()))
+
+	for key, value := cursor.Seek(start); key != nil && bytes.HasPrefix(key, prefix); key, value = cursor.Next() {
+		if getKeyTime(key).After(to) {
+			break
+		}
+
+		var sample storedSample
+		if err := json.Unmarshal(value, &sample); err != nil {
+			return err
+		}
+
+		*samples = append(*samples, sample.Sample)
+	}
+
+	return nil
+}
+
+// getLastSample returns the latest stored sample of a series not newer than the given time.
+func getLastSample(cursor *bolt.Cursor, chain, address, denom string, before time.Time) (storedSample, bool, error) {
+	var sample storedSample
+
+	key, value := cursor.Seek(getKey(chain, address, denom, before.Add(time.Nanosecond)))
+	if key == nil {
+		key, value = cursor.Last()
+	} else {
+		key, value = cursor.Prev()
+	}
+
+	if key == nil || !bytes.HasPrefix(key, getPrefix(chain, address, denom)) {
+		return sample, false, nil
+	}
+
+	if err := json.Unmarshal(value, &sample); err != nil {
+		return sample, false, err
+	}
+
+	return sample, true, nil
+}
+
+func getPrefix(chain, address, denom string) []byte {
+	prefix := make([]byte, 0, len(chain)+len(address)+len(denom)+3)
+	prefix = append(prefix, chain...)
+	prefix = append(prefix, keySeparator)
+	prefix = append(prefix, address...)
+	prefix = append(prefix, keySeparator)
+	prefix = append(prefix, denom...)
+	prefix = append(prefix, keySeparator)
+	return prefix
+}
+
+func getKey(chain, address, denom string, timestamp time.Time) []byte {
+	// big-endian so the keys are sorted by time within a prefix
+	return binary.BigEndian.AppendUint64(getPrefix(chain, address, denom), uint64(timestamp.UnixNano()))
+}
+
+func getKeyTime(key []byte) time.Time {
+	if len(key) < 8 {
+		return time.Time{}
+	}
+
+	return time.Unix(0, int64(binary.BigEndian.Uint64(key[len(key)-8:])))
+}
+
+func getKeyPrefix(key []byte) []byte {
+	if len(key) < 8 {
+		return key
+	}
+
+	return key[:len(key)-8]
+}
+
+// getNextSeriesKey returns a key sorted after all the keys of the series with the given prefix,
+// as it's longer than any of them, and before the keys of the next series.
+func getNextSeriesKey(prefix []byte) []byte {
+	return append(bytes.Clone(prefix), bytes.Repeat([]byte{0xff}, 9)...)
+}
+
+func getPrefixAddress(prefix []byte) string {
+	parts := bytes.Split(prefix, []byte{keySeparator})
+	if len(parts) < 2 {
+		return ""
+	}
+
+	return string(parts[1])
+}
+
+// Stats describes how a balance changed within a time window.
+type Stats struct {
+	// Delta is the difference between the latest and the earliest sample.
+	Delta float64
+	// SpendRate is the average amount spent per day, only decreases are counted,
+	// so top-ups do not hide how fast a wallet is draining.
+	SpendRate float64
+	// DaysUntilEmpty is the latest balance divided by the spend rate,
+	// only set if the wallet is actually spending.
+	DaysUntilEmpty float64
+	HasSpendRate   bool
+}
+
+// getStats calculates the balance stats between the first and the last sample of a window,
+// returning false if they are at the same time, as nothing can be calculated then.
+func getStats(first storedSample, last storedSample) (Stats, bool) {
+	elapsedDays := last.Time.Sub(first.Time).Hours() / 24
+	if elapsedDays <= 0 {
+		return Stats{}, false
+	}
+
+	stats := Stats{
+		Delta:        last.Amount - first.Amount,
+		SpendRate:    (last.Spent - first.Spent) / elapsedDays,
+		HasSpendRate: true,
+	}
+
+	if stats.SpendRate > 0 {
+		stats.DaysUntilEmpty = last.Amount / stats.SpendRate
+	}
+
+	return stats, true
+}
@@ -0,0 +1,207 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+
+	store, err := NewStore(filepath.Join(t.TempDir(), "history.db"), 24*time.Hour, time.Minute)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = store.Close()
+	})
+
+	return store
+}
+
+func TestStoreOpenFail(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewStore(filepath.Join(t.TempDir(), "not-existing", "history.db"), time.Hour, time.Minute)
+	require.Error(t, err)
+}
+
+func TestStoreRecordAndQuery(t *testing.T) {
+	t.Parallel()
+
+	store := newTestStore(t)
+	now := time.Now()
+
+	err := store.Record([]Sample{
+		{Time: now.Add(-2 * time.Hour), Chain: "chain", Address: "address", Denom: "atom", Amount: 10},
+		{Time: now.Add(-time.Hour), Chain: "chain", Address: "address", Denom: "atom", Amount: 8},
+		{Time: now, Chain: "chain", Address: "address", Denom: "atom", Amount: 6},
+		{Time: now, Chain: "chain", Address: "address", Denom: "atom2", Amount: 100},
+		{Time: now, Chain: "chain", Address: "address2", Denom: "atom", Amount: 100},
+	})
+	require.NoError(t, err)
+
+	samples, err := store.Query("chain", "address", "atom", now.Add(-90*time.Minute), now)
+	require.NoError(t, err)
+	require.Len(t, samples, 2)
+	assert.InDelta(t, 8, samples[0].Amount, 0.001)
+	assert.InDelta(t, 6, samples[1].Amount, 0.001)
+
+	samples, err = store.Query("chain", "address", "atom3", now.Add(-time.Hour), now)
+	require.NoError(t, err)
+	assert.Empty(t, samples)
+}
+
+func TestStorePrune(t *testing.T) {
+	t.Parallel()
+
+	store := newTestStore(t)
+	now := time.Now()
+
+	err := store.Record([]Sample{
+		{Time: now.Add(-48 * time.Hour), Chain: "chain", Address: "address", Denom: "atom", Amount: 10},
+		{Time: now.Add(-47 * time.Hour), Chain: "chain", Address: "address", Denom: "atom", Amount: 9},
+		{Time: now.Add(-time.Hour), Chain: "chain", Address: "address", Denom: "atom", Amount: 8},
+		{Time: now, Chain: "chain", Address: "address", Denom: "atom", Amount: 6},
+		{Time: now.Add(-48 * time.Hour), Chain: "chain", Address: "address2", Denom: "atom", Amount: 10},
+		{Time: now.Add(-48 * time.Hour), Chain: "chain", Address: "address3", Denom: "atom", Amount: 10},
+		{Time: now, Chain: "chain", Address: "address3", Denom: "atom", Amount: 5},
+	})
+	require.NoError(t, err)
+
+	samples, err := store.Query("chain", "address", "atom", now.Add(-72*time.Hour), now)
+	require.NoError(t, err)
+	require.Len(t, samples, 2)
+	assert.InDelta(t, 8, samples[0].Amount, 0.001)
+
+	// old samples are removed from every series, including the ones that end up empty
+	latest, err := store.Latest(Filter{})
+	require.NoError(t, err)
+	require.Len(t, latest, 2)
+	assert.Equal(t, "address", latest[0].Address)
+	assert.Equal(t, "address3", latest[1].Address)
+	assert.InDelta(t, 5, latest[1].Amount, 0.001)
+
+	samples, err = store.QueryAll(Filter{}, now.Add(-72*time.Hour), now)
+	require.NoError(t, err)
+	assert.Len(t, samples, 3)
+}
+
+func TestStoreRecordInterval(t *testing.T) {
+	t.Parallel()
+
+	store := newTestStore(t)
+	now := time.Now()
+
+	err := store.Record([]Sample{
+		{Time: now.Add(-2 * time.Minute), Chain: "chain", Address: "address", Denom: "atom", Amount: 10},
+		{Time: now.Add(-90 * time.Second), Chain: "chain", Address: "address", Denom: "atom", Amount: 9},
+		{Time: now, Chain: "chain", Address: "address", Denom: "atom", Amount: 8},
+	})
+	require.NoError(t, err)
+
+	// the second sample is less than an interval newer than the first one
+	samples, err := store.Query("chain", "address", "atom", now.Add(-time.Hour), now)
+	require.NoError(t, err)
+	require.Len(t, samples, 2)
+	assert.InDelta(t, 10, samples[0].Amount, 0.001)
+	assert.InDelta(t, 8, samples[1].Amount, 0.001)
+}
+
+func TestStoreGetStatsNotEnoughSamples(t *testing.T) {
+	t.Parallel()
+
+	store := newTestStore(t)
+	now := time.Now()
+
+	sample := Sample{Time: now, Chain: "chain", Address: "address", Denom: "atom", Amount: 10}
+	require.NoError(t, store.Record([]Sample{sample}))
+
+	_, ok, err := store.GetStats(sample, now.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestStoreGetStatsOk(t *testing.T) {
+	t.Parallel()
+
+	store, err := NewStore(filepath.Join(t.TempDir(), "history.db"), 48*time.Hour, time.Minute)
+	require.NoError(t, err)
+	defer store.Close()
+
+	now := time.Now()
+
+	err = store.Record([]Sample{
+		{Time: now.Add(-36 * time.Hour), Chain: "chain", Address: "address", Denom: "atom", Amount: 500},
+		{Time: now.Add(-24 * time.Hour), Chain: "chain", Address: "address", Denom: "atom", Amount: 100},
+		{Time: now.Add(-12 * time.Hour), Chain: "chain", Address: "address", Denom: "atom", Amount: 50},
+		{Time: now.Add(-30 * time.Second), Chain: "chain", Address: "address", Denom: "atom", Amount: 80},
+	})
+	require.NoError(t, err)
+
+	// the current sample is not stored, as the previous one is too recent, but is still used
+	current := Sample{Time: now, Chain: "chain", Address: "address", Denom: "atom", Amount: 70}
+	require.NoError(t, store.Record([]Sample{current}))
+
+	stats, ok, err := store.GetStats(current, now.Add(-24*time.Hour))
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.InDelta(t, -30, stats.Delta, 0.001)
+	assert.True(t, stats.HasSpendRate)
+	// top-up from 50 to 80 is not counted as spending, nor anything before the window
+	assert.InDelta(t, 60, stats.SpendRate, 0.001)
+	assert.InDelta(t, 70.0/60, stats.DaysUntilEmpty, 0.001)
+}
+
+func TestStoreQueryAllAndLatest(t *testing.T) {
+	t.Parallel()
+
+	store := newTestStore(t)
+	now := time.Now()
+
+	err := store.Record([]Sample{
+		{Time: now.Add(-2 * time.Hour), Chain: "chain", Address: "address", Denom: "atom", Amount: 10},
+		{Time: now.Add(-time.Hour), Chain: "chain", Address: "address", Denom: "atom", Amount: 8},
+		{Time: now.Add(-time.Hour), Chain: "chain", Address: "address", Denom: "atom2", Amount: 100},
+		{Time: now, Chain: "chain", Address: "address2", Denom: "atom", Amount: 50},
+		{Time: now, Chain: "chain2", Address: "address", Denom: "atom", Amount: 20},
+		{Time: now, Chain: "chain2", Address: "address3", Denom: "atom", Amount: 30},
+	})
+	require.NoError(t, err)
+
+	samples, err := store.QueryAll(Filter{Chain: "chain"}, now.Add(-90*time.Minute), now)
+	require.NoError(t, err)
+	require.Len(t, samples, 3)
+
+	samples, err = store.QueryAll(Filter{}, now.Add(-90*time.Minute), now)
+	require.NoError(t, err)
+	require.Len(t, samples, 5)
+
+	samples, err = store.QueryAll(Filter{Chain: "chain2", Address: "address"}, now.Add(-time.Hour), now)
+	require.NoError(t, err)
+	require.Len(t, samples, 1)
+	assert.InDelta(t, 20, samples[0].Amount, 0.001)
+
+	samples, err = store.QueryAll(Filter{Address: "address"}, now.Add(-3*time.Hour), now)
+	require.NoError(t, err)
+	require.Len(t, samples, 4)
+
+	latest, err := store.Latest(Filter{Chain: "chain"})
+	require.NoError(t, err)
+	require.Len(t, latest, 3)
+	assert.InDelta(t, 8, latest[0].Amount, 0.001)
+	assert.InDelta(t, 100, latest[1].Amount, 0.001)
+	assert.InDelta(t, 50, latest[2].Amount, 0.001)
+
+	latest, err = store.Latest(Filter{Address: "address3"})
+	require.NoError(t, err)
+	require.Len(t, latest, 1)
+	assert.InDelta(t, 30, latest[0].Amount, 0.001)
+
+	// a chain name being a prefix of another one does not match it
+	latest, err = store.Latest(Filter{Chain: "chai"})
+	require.NoError(t, err)
+	assert.Empty(t, latest)
+}
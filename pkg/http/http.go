@@ -3,8 +3,12 @@ package http
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"main/pkg/types"
+	"main/pkg/utils"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"go.opentelemetry.io/otel/trace"
@@ -13,10 +17,17 @@ import (
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
+// requestTimeout limits requests done without a deadline in the context, like background
+// ones. Scrapes usually have a shorter deadline derived from the Prometheus scrape timeout.
+const requestTimeout = 10 * time.Second
+
 type Client struct {
 	logger zerolog.Logger
 	chain  string
 	tracer trace.Tracer
+
+	secrets  []string
+	redacted string
 }
 
 func NewClient(logger zerolog.Logger, chain string, tracer trace.Tracer) *Client {
@@ -27,6 +38,46 @@ func NewClient(logger zerolog.Logger, chain string, tracer trace.Tracer) *Client
 	}
 }
 
+// WithRedaction makes the client replace secret with redacted in the URLs and errors
+// it logs and returns, like an LCD endpoint with an API key in it.
+func (c *Client) WithRedaction(secret string, redacted string) *Client {
+	c.secrets = nil
+	c.redacted = redacted
+
+	if secret == "" || secret == redacted {
+		return c
+	}
+
+	c.secrets = append(c.secrets, secret)
+
+	// net/http errors have the password replaced with "***", but the rest of the URL as is
+	if parsed, err := url.Parse(secret); err == nil && parsed.User != nil {
+		if _, hasPassword := parsed.User.Password(); hasPassword {
+			stripped := strings.Replace(parsed.String(), parsed.User.String()+"@", parsed.User.Username()+":***@", 1)
+			c.secrets = append(c.secrets, stripped)
+		}
+	}
+
+	return c
+}
+
+func (c *Client) redact(value string) string {
+	for _, secret := range c.secrets {
+		value = strings.ReplaceAll(value, secret, c.redacted)
+	}
+
+	return value
+}
+
+func (c *Client) redactError(err error) error {
+	message := c.redact(err.Error())
+	if message == err.Error() {
+		return err
+	}
+
+	return &redactedError{err: err, message: message}
+}
+
 func (c *Client) Get(
 	url string,
 	target interface{},
@@ -46,7 +97,7 @@ func (c *Client) Get(
 	}
 
 	client := &http.Client{
-		Timeout:   10 * 1000000000,
+		Timeout:   requestTimeout,
 		Transport: otelhttp.NewTransport(transport),
 	}
 	start := time.Now()
@@ -54,37 +105,64 @@ func (c *Client) Get(
 	queryInfo := types.QueryInfo{
 		Success: false,
 		Chain:   c.chain,
-		URL:     url,
+		URL:     c.redact(url),
 	}
 
 	req, err := http.NewRequestWithContext(childCtx, http.MethodGet, url, nil)
 	if err != nil {
+		err = c.redactError(err)
+		queryInfo.Error = err.Error()
 		return queryInfo, nil, err
 	}
 
 	req.Header.Set("User-Agent", "cosmos-wallets-exporter")
 
-	c.logger.Debug().Str("url", url).Msg("Doing a query...")
+	c.logger.Debug().Str("url", queryInfo.URL).Msg("Doing a query...")
 
 	res, err := client.Do(req)
 	queryInfo.Duration = time.Since(start)
 	if err != nil {
-		c.logger.Warn().Str("url", url).Err(err).Msg("Query failed")
+		err = c.redactError(err)
+		c.logger.Warn().Str("url", queryInfo.URL).Err(err).Msg("Query failed")
+		queryInfo.Error = err.Error()
+		// the scrape deadline is reached, as opposed to the node returning an error
+		queryInfo.TimedOut = errors.Is(childCtx.Err(), context.DeadlineExceeded)
 		return queryInfo, nil, err
 	}
 	defer res.Body.Close()
 
 	c.logger.Debug().
-		Str("url", url).
+		Str("url", queryInfo.URL).
 		Dur("duration", time.Since(start)).
 		Msg("Query is finished")
 
+	queryInfo.Height, _ = utils.GetBlockHeightFromHeader(res.Header)
+
 	if predicateErr := predicate(res); predicateErr != nil {
+		queryInfo.Error = predicateErr.Error()
 		return queryInfo, res.Header, predicateErr
 	}
 
 	err = json.NewDecoder(res.Body).Decode(target)
 	queryInfo.Success = err == nil
+	if err != nil {
+		queryInfo.Error = err.Error()
+	}
 
 	return queryInfo, res.Header, err
 }
+
+// redactedError hides a secret in the error message, keeping the original error
+// available for errors.Is and errors.As.
+type redactedError struct {
+	err     error
+	message string
+}
+
+func (e *redactedError) Error() string {
+	return e.message
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
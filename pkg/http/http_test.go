@@ -45,6 +45,30 @@ func TestHttpClientQueryFail(t *testing.T) {
 	require.ErrorContains(t, err, "custom error")
 }
 
+//nolint:paralleltest // disabled due to httpmock usage
+func TestHttpClientQueryFailRedacted(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder(
+		"GET",
+		"https://example.com/api-key/status",
+		httpmock.NewErrorResponder(errors.New("custom error")),
+	)
+	logger := loggerPkg.GetNopLogger()
+	tracer := tracing.InitNoopTracer()
+	client := NewClient(*logger, "chain", tracer).
+		WithRedaction("https://example.com/api-key", "https://example.com/<redacted>")
+
+	var response interface{}
+	queryInfo, _, err := client.Get("https://example.com/api-key/status", &response, types.HTTPPredicateAlwaysPass(), nil)
+	require.Error(t, err)
+	require.ErrorContains(t, err, "custom error")
+	require.NotContains(t, err.Error(), "api-key")
+	require.Equal(t, "https://example.com/<redacted>/status", queryInfo.URL)
+	require.NotContains(t, queryInfo.Error, "api-key")
+}
+
 //nolint:paralleltest // disabled due to httpmock usage
 func TestHttpClientPredicateFail(t *testing.T) {
 	httpmock.Activate()
@@ -103,3 +127,26 @@ func TestHttpClientOk(t *testing.T) {
 	_, _, err := client.Get("https://example.com", &response, types.HTTPPredicateAlwaysPass(), nil)
 	require.NoError(t, err)
 }
+
+//nolint:paralleltest // disabled due to httpmock usage
+func TestHttpClientOkWithHeight(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder(
+		"GET",
+		"https://example.com",
+		httpmock.NewBytesResponder(200, assets.GetBytesOrPanic("error.json")).HeaderAdd(http.Header{
+			constants.HeaderBlockHeight: []string{"123"},
+		}),
+	)
+	logger := loggerPkg.GetNopLogger()
+	tracer := tracing.InitNoopTracer()
+	client := NewClient(*logger, "chain", tracer)
+
+	var response interface{}
+	queryInfo, _, err := client.Get("https://example.com", &response, types.HTTPPredicateAlwaysPass(), nil)
+	require.NoError(t, err)
+	require.True(t, queryInfo.Success)
+	require.Equal(t, int64(123), queryInfo.Height)
+}
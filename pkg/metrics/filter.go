@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"slices"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Filter collects only the metrics of another collector having one of the given values
+// of a label, so a collector kept for the whole app lifetime, like a counter, can be
+// exported by a scrape of some of the chains only.
+type Filter struct {
+	Collector prometheus.Collector
+	Label     string
+	Values    []string
+}
+
+func NewFilter(collector prometheus.Collector, label string, values []string) *Filter {
+	return &Filter{
+		Collector: collector,
+		Label:     label,
+		Values:    values,
+	}
+}
+
+func (f *Filter) Describe(ch chan<- *prometheus.Desc) {
+	f.Collector.Describe(ch)
+}
+
+func (f *Filter) Collect(ch chan<- prometheus.Metric) {
+	metrics := make(chan prometheus.Metric)
+
+	go func() {
+		f.Collector.Collect(metrics)
+		close(metrics)
+	}()
+
+	for metric := range metrics {
+		var written dto.Metric
+		if err := metric.Write(&written); err != nil {
+			continue
+		}
+
+		for _, label := range written.GetLabel() {
+			if label.GetName() == f.Label && slices.Contains(f.Values, label.GetValue()) {
+				ch <- metric
+				break
+			}
+		}
+	}
+}
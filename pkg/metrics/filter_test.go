@@ -0,0 +1,35 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilter(t *testing.T) {
+	t.Parallel()
+
+	counter := prometheus.NewCounterVec(
+		prometheus.CounterOpts{Name: "cosmos_wallets_exporter_failures_total", Help: "Test counter"},
+		[]string{"chain", "source"},
+	)
+	counter.With(prometheus.Labels{"chain": "cosmos", "source": "relayers"}).Add(2)
+	counter.With(prometheus.Labels{"chain": "osmosis", "source": "relayers"}).Inc()
+	counter.With(prometheus.Labels{"chain": "bitsong", "source": "relayers"}).Inc()
+
+	assert.Equal(t, 2, testutil.CollectAndCount(NewFilter(counter, "chain", []string{"cosmos", "osmosis"})))
+	assert.Equal(t, 0, testutil.CollectAndCount(NewFilter(counter, "chain", []string{})))
+	assert.Equal(t, 0, testutil.CollectAndCount(NewFilter(counter, "denom", []string{"cosmos"})))
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(NewFilter(counter, "chain", []string{"bitsong"}))
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	require.Len(t, families, 1)
+	require.Len(t, families[0].GetMetric(), 1)
+	assert.InDelta(t, 1, families[0].GetMetric()[0].GetCounter().GetValue(), 0.01)
+}
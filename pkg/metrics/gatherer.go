@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"main/pkg/config"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// DefaultNamespace is the prefix all the metrics are created with.
+const DefaultNamespace = "cosmos_wallets_exporter"
+
+// Gatherer changes the metrics gathered from another gatherer as configured
+// in the metrics config: replaces the default namespace, renames labels and adds const labels.
+// Metrics are created with the default names internally, so the status API and dashboard
+// do not depend on this config, and only the exposed metrics are changed.
+type Gatherer struct {
+	Gatherer prometheus.Gatherer
+	Config   config.MetricsConfig
+}
+
+func NewGatherer(gatherer prometheus.Gatherer, metricsConfig config.MetricsConfig) *Gatherer {
+	return &Gatherer{
+		Gatherer: gatherer,
+		Config:   metricsConfig,
+	}
+}
+
+func (g *Gatherer) Gather() ([]*dto.MetricFamily, error) {
+	families, err := g.Gatherer.Gather()
+
+	for _, family := range families {
+		g.transformFamily(family)
+	}
+
+	return families, err
+}
+
+func (g *Gatherer) transformFamily(family *dto.MetricFamily) {
+	if g.Config.Namespace != "" && g.Config.Namespace != DefaultNamespace {
+		name := family.GetName()
+		if strings.HasPrefix(name, DefaultNamespace+"_") {
+			newName := g.Config.Namespace + strings.TrimPrefix(name, DefaultNamespace)
+			family.Name = &newName
+		}
+	}
+
+	for _, metric := range family.GetMetric() {
+		existing := make(map[string]bool, len(metric.GetLabel()))
+
+		for _, label := range metric.GetLabel() {
+			if to, ok := g.Config.RenameLabels[label.GetName()]; ok {
+				newName := to
+				label.Name = &newName
+			}
+
+			existing[label.GetName()] = true
+		}
+
+		for name, value := range g.Config.ConstLabels {
+			// should not happen as config validation prevents it,
+			// but wallets from wallet sources can have any labels
+			if existing[name] {
+				continue
+			}
+
+			labelName, labelValue := name, value
+			metric.Label = append(metric.Label, &dto.LabelPair{Name: &labelName, Value: &labelValue})
+		}
+
+		sort.Slice(metric.Label, func(i, j int) bool {
+			return metric.Label[i].GetName() < metric.Label[j].GetName()
+		})
+	}
+}
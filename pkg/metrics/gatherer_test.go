@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"main/pkg/config"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getRegistry(name string) *prometheus.Registry {
+	gauge := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{Name: name, Help: "Test gauge"},
+		[]string{"chain", "group", "team"},
+	)
+	gauge.With(prometheus.Labels{"chain": "cosmos", "group": "validators", "team": "infra"}).Set(1)
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(gauge)
+	return registry
+}
+
+func TestGathererDefault(t *testing.T) {
+	t.Parallel()
+
+	gatherer := NewGatherer(getRegistry("cosmos_wallets_exporter_balance"), config.MetricsConfig{
+		Namespace: DefaultNamespace,
+	})
+
+	families, err := gatherer.Gather()
+	require.NoError(t, err)
+	require.Len(t, families, 1)
+	assert.Equal(t, "cosmos_wallets_exporter_balance", families[0].GetName())
+
+	labels := families[0].GetMetric()[0].GetLabel()
+	require.Len(t, labels, 3)
+	assert.Equal(t, "chain", labels[0].GetName())
+}
+
+func TestGathererTransform(t *testing.T) {
+	t.Parallel()
+
+	gatherer := NewGatherer(getRegistry("cosmos_wallets_exporter_balance"), config.MetricsConfig{
+		Namespace:    "wallets",
+		ConstLabels:  map[string]string{"cluster": "eu-1", "team": "relayers"},
+		RenameLabels: map[string]string{"chain": "network"},
+	})
+
+	families, err := gatherer.Gather()
+	require.NoError(t, err)
+	require.Len(t, families, 1)
+	assert.Equal(t, "wallets_balance", families[0].GetName())
+
+	values := map[string]string{}
+	names := []string{}
+
+	for _, label := range families[0].GetMetric()[0].GetLabel() {
+		values[label.GetName()] = label.GetValue()
+		names = append(names, label.GetName())
+	}
+
+	assert.Equal(t, []string{"cluster", "group", "network", "team"}, names)
+	assert.Equal(t, "cosmos", values["network"])
+	assert.Equal(t, "eu-1", values["cluster"])
+	// labels the metric already has are not overridden by const labels
+	assert.Equal(t, "infra", values["team"])
+}
+
+func TestGathererOtherMetrics(t *testing.T) {
+	t.Parallel()
+
+	gatherer := NewGatherer(getRegistry("go_goroutines"), config.MetricsConfig{Namespace: "wallets"})
+
+	families, err := gatherer.Gather()
+	require.NoError(t, err)
+	require.Len(t, families, 1)
+	assert.Equal(t, "go_goroutines", families[0].GetName())
+}
@@ -0,0 +1,204 @@
+package probe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"main/pkg/config"
+	queriersPkg "main/pkg/queriers"
+	"main/pkg/tendermint"
+	"main/pkg/types"
+	"main/pkg/utils"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	TypeWallet      = "wallet"
+	TypeApplication = "application"
+	TypeSupplier    = "supplier"
+)
+
+// Prober queries a single address that is not in the config, in the spirit of
+// blackbox_exporter, so Prometheus service discovery can drive the list of addresses.
+// It shares the LCD clients with the app, so probes reuse their connections.
+type Prober struct {
+	Config *config.Config
+	RPCs   *tendermint.Registry
+	Logger zerolog.Logger
+	Tracer trace.Tracer
+}
+
+func NewProber(
+	config *config.Config,
+	rpcs *tendermint.Registry,
+	logger zerolog.Logger,
+	tracer trace.Tracer,
+) *Prober {
+	return &Prober{
+		Config: config,
+		RPCs:   rpcs,
+		Logger: logger.With().Str("component", "prober").Logger(),
+		Tracer: tracer,
+	}
+}
+
+// GetConfig builds a config with only the configured chain from params and the probed
+// address in it. Everything else is taken from the app config as is (chain LCD endpoint,
+// denoms, labels, scrape timeout, metrics config), so probes return the same metrics
+// as /metrics would for this address.
+// Params are chain and address (required), type (wallet, application or supplier,
+// defaults to wallet), name and group (used as labels). The address should be a valid bech32 one,
+// as it's put into LCD URL paths, with the same prefix as the chain addresses in the config, if any.
+func (p *Prober) GetConfig(params url.Values) (*config.Config, error) {
+	chainName := params.Get("chain")
+	if chainName == "" {
+		return nil, errors.New("chain is not specified")
+	}
+
+	address := params.Get("address")
+	if address == "" {
+		return nil, errors.New("address is not specified")
+	}
+
+	var chain *config.Chain
+	for index := range p.Config.Chains {
+		if p.Config.Chains[index].Name == chainName {
+			chain = &p.Config.Chains[index]
+			break
+		}
+	}
+
+	if chain == nil {
+		return nil, fmt.Errorf("chain %s is not found in config", chainName)
+	}
+
+	prefix, err := utils.GetBech32Prefix(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %q: %w", address, err)
+	}
+
+	if chainPrefix, ok := getChainPrefix(*chain); ok && prefix != chainPrefix {
+		return nil, fmt.Errorf("address prefix %s does not match chain prefix %s", prefix, chainPrefix)
+	}
+
+	probeChain := *chain
+	probeChain.Wallets = []config.Wallet{}
+	probeChain.Applications = []config.Application{}
+	probeChain.Suppliers = []config.Supplier{}
+
+	name := params.Get("name")
+	group := params.Get("group")
+
+	switch params.Get("type") {
+	case "", TypeWallet:
+		probeChain.Wallets = []config.Wallet{{Address: address, Name: name, Group: group}}
+	case TypeApplication:
+		probeChain.Applications = []config.Application{{Address: address, Name: name, Group: group}}
+	case TypeSupplier:
+		probeChain.Suppliers = []config.Supplier{{Address: address, Name: name, Group: group}}
+	default:
+		return nil, fmt.Errorf("unsupported type: %s", params.Get("type"))
+	}
+
+	probeConfig := *p.Config
+	probeConfig.Chains = []config.Chain{probeChain}
+
+	return &probeConfig, nil
+}
+
+// getChainPrefix returns the bech32 prefix of the first valid address in the chain config.
+func getChainPrefix(chain config.Chain) (string, bool) {
+	addresses := []string{}
+	for _, wallet := range chain.Wallets {
+		addresses = append(addresses, wallet.Address)
+	}
+
+	for _, application := range chain.Applications {
+		addresses = append(addresses, application.Address)
+	}
+
+	for _, supplier := range chain.Suppliers {
+		addresses = append(addresses, supplier.Address)
+	}
+
+	for _, address := range addresses {
+		if prefix, err := utils.GetBech32Prefix(address); err == nil {
+			return prefix, true
+		}
+	}
+
+	return "", false
+}
+
+// Probe runs balance, application and supplier queriers against a config
+// returned by GetConfig and returns a registry with their metrics.
+func (p *Prober) Probe(ctx context.Context, probeConfig *config.Config) *prometheus.Registry {
+	start := time.Now()
+
+	// heights of probed addresses are forgotten once they are not probed anymore,
+	// see tendermint.HeightMaxAge
+	rpcs := p.RPCs
+	queryCtx := tendermint.ContextWithQueryCache(ctx, tendermint.NewQueryCache())
+
+	queriers := []types.Querier{
+		queriersPkg.NewBalanceQuerier(probeConfig, rpcs, nil, p.Logger, p.Tracer),
+		queriersPkg.NewApplicationQuerier(probeConfig, rpcs, p.Logger, p.Tracer),
+		queriersPkg.NewSupplierQuerier(probeConfig, rpcs, p.Logger, p.Tracer),
+	}
+
+	registry := prometheus.NewRegistry()
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+
+	var queryInfos []types.QueryInfo
+
+	for _, querier := range queriers {
+		wg.Add(1)
+		go func(querier types.Querier) {
+			defer wg.Done()
+
+			metrics, querierQueryInfos := querier.GetMetrics(queryCtx)
+
+			mutex.Lock()
+			registry.MustRegister(metrics...)
+			queryInfos = append(queryInfos, querierQueryInfos...)
+			mutex.Unlock()
+		}(querier)
+	}
+
+	wg.Wait()
+
+	queriesQuerier := queriersPkg.NewQueriesQuerier(probeConfig, queryInfos)
+	queriesMetrics, _ := queriesQuerier.GetMetrics()
+	registry.MustRegister(queriesMetrics...)
+
+	successGauge := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "cosmos_wallets_exporter_probe_success",
+		Help: "Whether all the probe queries succeeded",
+	})
+
+	durationGauge := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "cosmos_wallets_exporter_probe_duration_seconds",
+		Help: "How long the probe took to complete in seconds",
+	})
+
+	success := 1.0
+	for _, queryInfo := range queryInfos {
+		if !queryInfo.Success && !queryInfo.Cached {
+			success = 0
+		}
+	}
+
+	successGauge.Set(success)
+	durationGauge.Set(time.Since(start).Seconds())
+	registry.MustRegister(successGauge, durationGauge)
+
+	return registry
+}
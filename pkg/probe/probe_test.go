@@ -0,0 +1,164 @@
+package probe
+
+import (
+	"context"
+	"errors"
+	"main/assets"
+	configPkg "main/pkg/config"
+	loggerPkg "main/pkg/logger"
+	"main/pkg/tendermint"
+	"main/pkg/tracing"
+	"net/url"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	configuredAddress = "cosmos1yq2cyfr4qpqav57d33eh6exzlh8ztr2zfqxhlk"
+	probedAddress     = "cosmos1h2w8xmceulmqklm8vjkmpdus3s9zkw2w8yr0m7"
+)
+
+func getTestProber() *Prober {
+	config := &configPkg.Config{
+		ScrapeTimeout: "10s",
+		MetricsConfig: configPkg.MetricsConfig{Namespace: "custom"},
+		Chains: []configPkg.Chain{{
+			Name:        "chain",
+			LCDEndpoint: "https://example.com",
+			Labels:      map[string]string{"environment": "production"},
+			Wallets:     []configPkg.Wallet{{Address: configuredAddress}},
+			Denoms:      []configPkg.DenomInfo{{Denom: "uatom", DisplayDenom: "atom", DenomExponent: 6}},
+		}},
+	}
+
+	logger := *loggerPkg.GetNopLogger()
+	tracer := tracing.InitNoopTracer()
+
+	return NewProber(config, tendermint.NewRegistry(config, logger, tracer), logger, tracer)
+}
+
+func TestProberGetConfigInvalid(t *testing.T) {
+	t.Parallel()
+
+	prober := getTestProber()
+
+	for query, expected := range map[string]string{
+		"address=" + probedAddress:                                          "chain is not specified",
+		"chain=chain":                                                       "address is not specified",
+		"chain=unknown&address=" + probedAddress:                            "chain unknown is not found in config",
+		"chain=chain&address=" + probedAddress + "&type=unknown":            "unsupported type: unknown",
+		"chain=chain&address=../../../cosmos/params":                        "invalid address",
+		"chain=chain&address=cosmos1h2w8xmceulmqklm8vjkmpdus3s9zkw2w8yr0m8": "invalid bech32 checksum",
+		"chain=chain&address=osmo1h2w8xmceulmqklm8vjkmpdus3s9zkw2w0lsldv":   "address prefix osmo does not match chain prefix cosmos",
+	} {
+		params, err := url.ParseQuery(query)
+		require.NoError(t, err)
+
+		_, err = prober.GetConfig(params)
+		require.Error(t, err)
+		require.ErrorContains(t, err, expected)
+	}
+}
+
+func TestProberGetConfigOk(t *testing.T) {
+	t.Parallel()
+
+	prober := getTestProber()
+
+	config, err := prober.GetConfig(url.Values{
+		"chain":   []string{"chain"},
+		"address": []string{probedAddress},
+		"type":    []string{"supplier"},
+		"name":    []string{"name"},
+	})
+	require.NoError(t, err)
+	require.Len(t, config.Chains, 1)
+
+	chain := config.Chains[0]
+	assert.Equal(t, "https://example.com", chain.LCDEndpoint)
+	assert.Len(t, chain.Denoms, 1)
+	assert.Empty(t, chain.Wallets)
+	require.Len(t, chain.Suppliers, 1)
+	assert.Equal(t, probedAddress, chain.Suppliers[0].Address)
+	assert.Equal(t, "name", chain.Suppliers[0].Name)
+
+	// chain and global settings are kept, so probes match /metrics
+	assert.Equal(t, map[string]string{"environment": "production"}, chain.Labels)
+	assert.Equal(t, "10s", config.ScrapeTimeout)
+	assert.Equal(t, "custom", config.MetricsConfig.Namespace)
+
+	// the app config is not changed
+	assert.Len(t, prober.Config.Chains[0].Wallets, 1)
+	assert.Empty(t, prober.Config.Chains[0].Suppliers)
+}
+
+//nolint:paralleltest // disabled due to httpmock usage
+func TestProberProbeOk(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder(
+		"GET",
+		"https://example.com/cosmos/bank/v1beta1/balances/"+probedAddress,
+		httpmock.NewBytesResponder(200, assets.GetBytesOrPanic("balance.json")),
+	)
+
+	prober := getTestProber()
+	config, err := prober.GetConfig(url.Values{"chain": []string{"chain"}, "address": []string{probedAddress}})
+	require.NoError(t, err)
+
+	registry := prober.Probe(context.Background(), config)
+
+	count, err := testutil.GatherAndCount(registry, "cosmos_wallets_exporter_balance")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	found := false
+	for _, family := range families {
+		if family.GetName() == "cosmos_wallets_exporter_probe_success" {
+			found = true
+			assert.InDelta(t, 1, family.GetMetric()[0].GetGauge().GetValue(), 0.001)
+		}
+	}
+	assert.True(t, found)
+
+	// only the probed address is queried
+	assert.Equal(t, 1, httpmock.GetTotalCallCount())
+}
+
+//nolint:paralleltest // disabled due to httpmock usage
+func TestProberProbeFail(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder(
+		"GET",
+		"https://example.com/cosmos/bank/v1beta1/balances/"+probedAddress,
+		httpmock.NewErrorResponder(errors.New("custom error")),
+	)
+
+	prober := getTestProber()
+	config, err := prober.GetConfig(url.Values{"chain": []string{"chain"}, "address": []string{probedAddress}})
+	require.NoError(t, err)
+
+	registry := prober.Probe(context.Background(), config)
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	found := false
+	for _, family := range families {
+		if family.GetName() == "cosmos_wallets_exporter_probe_success" {
+			found = true
+			assert.Zero(t, family.GetMetric()[0].GetGauge().GetValue())
+		}
+	}
+	assert.True(t, found)
+}
@@ -0,0 +1,220 @@
+package queriers
+
+import (
+	"context"
+	"main/pkg/config"
+	"main/pkg/tendermint"
+	"main/pkg/types"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+)
+
+type ActivityQuerier struct {
+	Config *config.Config
+	Logger zerolog.Logger
+	RPCs   *tendermint.Registry
+	Tracer trace.Tracer
+}
+
+func NewActivityQuerier(
+	config *config.Config,
+	rpcs *tendermint.Registry,
+	logger zerolog.Logger,
+	tracer trace.Tracer,
+) *ActivityQuerier {
+	return &ActivityQuerier{
+		Config: config,
+		Logger: logger.With().Str("component", "activity_querier").Logger(),
+		RPCs:   rpcs,
+		Tracer: tracer,
+	}
+}
+
+func (q *ActivityQuerier) Name() string {
+	return "activity"
+}
+
+func (q *ActivityQuerier) GetMetrics(ctx context.Context) ([]prometheus.Collector, []types.QueryInfo) {
+	childCtx, span := q.Tracer.Start(ctx, "Querying account activity metrics")
+	defer span.End()
+
+	customLabelNames := q.Config.GetLabelNames()
+	labelNames := withCustomLabelNames(config.WalletLabelNames, customLabelNames)
+
+	sequenceGauge := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cosmos_wallets_exporter_account_sequence",
+			Help: "An account sequence, which is the count of transactions it has signed",
+		},
+		labelNames,
+	)
+
+	lastTxTimestampGauge := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cosmos_wallets_exporter_last_tx_timestamp",
+			Help: "A Unix timestamp of the latest transaction sent by a wallet",
+		},
+		labelNames,
+	)
+
+	lastTxHeightGauge := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cosmos_wallets_exporter_last_tx_height",
+			Help: "A block height of the latest transaction sent by a wallet",
+		},
+		labelNames,
+	)
+
+	sentTxsGauge := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cosmos_wallets_exporter_sent_txs",
+			Help: "A count of transactions sent by a wallet known to the node tx indexer",
+		},
+		labelNames,
+	)
+
+	var queryInfos []types.QueryInfo
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+
+	for _, chain := range q.Config.Chains {
+		if !chain.SequenceMetrics && !chain.LastTxMetrics {
+			continue
+		}
+
+		rpc := q.RPCs.Get(chain)
+
+		for _, wallet := range chain.Wallets {
+			labels := withCustomLabels(prometheus.Labels{
+				"chain":   chain.Name,
+				"address": wallet.Address,
+				"name":    wallet.Name,
+				"group":   wallet.Group,
+			}, customLabelNames, config.MergeLabels(chain.Labels, wallet.Labels))
+
+			if chain.SequenceMetrics {
+				wg.Add(1)
+				go func(wallet config.Wallet, chain config.Chain, rpc *tendermint.RPC, labels prometheus.Labels) {
+					chainCtx, chainSpan := q.Tracer.Start(childCtx, "Querying chain and account sequence")
+					chainSpan.SetAttributes(attribute.String("chain", chain.Name))
+					chainSpan.SetAttributes(attribute.String("wallet", wallet.Address))
+					defer chainSpan.End()
+
+					defer wg.Done()
+
+					accountResponse, queryInfo, err := rpc.GetAccount(wallet.Address, chainCtx)
+
+					mutex.Lock()
+					defer mutex.Unlock()
+
+					queryInfos = append(queryInfos, queryInfo)
+
+					if err != nil {
+						q.Logger.Error().
+							Err(err).
+							Str("chain", chain.Name).
+							Str("wallet", wallet.Address).
+							Msg("Error querying account")
+						return
+					}
+
+					sequenceString, found := accountResponse.Account.GetSequence()
+					if !found {
+						q.Logger.Warn().
+							Str("chain", chain.Name).
+							Str("wallet", wallet.Address).
+							Str("type", accountResponse.Account.Type).
+							Msg("Could not find sequence of account")
+						return
+					}
+
+					sequence, err := strconv.ParseFloat(sequenceString, 64)
+					if err != nil {
+						q.Logger.Error().
+							Err(err).
+							Str("chain", chain.Name).
+							Str("wallet", wallet.Address).
+							Str("sequence", sequenceString).
+							Msg("Error parsing account sequence")
+						return
+					}
+
+					sequenceGauge.With(labels).Set(sequence)
+				}(wallet, chain, rpc, labels)
+			}
+
+			if chain.LastTxMetrics {
+				wg.Add(1)
+				go func(wallet config.Wallet, chain config.Chain, rpc *tendermint.RPC, labels prometheus.Labels) {
+					chainCtx, chainSpan := q.Tracer.Start(childCtx, "Querying chain and last sent transaction")
+					chainSpan.SetAttributes(attribute.String("chain", chain.Name))
+					chainSpan.SetAttributes(attribute.String("wallet", wallet.Address))
+					defer chainSpan.End()
+
+					defer wg.Done()
+
+					txsResponse, queryInfo, err := rpc.GetLastSentTx(wallet.Address, chainCtx)
+
+					mutex.Lock()
+					defer mutex.Unlock()
+
+					queryInfos = append(queryInfos, queryInfo)
+
+					if err != nil {
+						q.Logger.Error().
+							Err(err).
+							Str("chain", chain.Name).
+							Str("wallet", wallet.Address).
+							Msg("Error querying last sent transaction")
+						return
+					}
+
+					if total, err := strconv.ParseFloat(txsResponse.GetTotal(), 64); err == nil {
+						sentTxsGauge.With(labels).Set(total)
+					}
+
+					// no transactions sent yet, or the node has already pruned them
+					if len(txsResponse.TxResponses) == 0 {
+						return
+					}
+
+					lastTx := txsResponse.TxResponses[0]
+
+					if height, err := strconv.ParseFloat(lastTx.Height, 64); err == nil {
+						lastTxHeightGauge.With(labels).Set(height)
+					}
+
+					timestamp, err := time.Parse(time.RFC3339, lastTx.Timestamp)
+					if err != nil {
+						q.Logger.Error().
+							Err(err).
+							Str("chain", chain.Name).
+							Str("wallet", wallet.Address).
+							Str("timestamp", lastTx.Timestamp).
+							Msg("Error parsing last transaction timestamp")
+						return
+					}
+
+					lastTxTimestampGauge.With(labels).Set(float64(timestamp.Unix()))
+				}(wallet, chain, rpc, labels)
+			}
+		}
+	}
+
+	wg.Wait()
+
+	return []prometheus.Collector{
+		sequenceGauge,
+		lastTxTimestampGauge,
+		lastTxHeightGauge,
+		sentTxsGauge,
+	}, queryInfos
+}
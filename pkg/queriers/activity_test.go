@@ -0,0 +1,154 @@
+package queriers
+
+import (
+	"context"
+	"errors"
+	"main/assets"
+	configPkg "main/pkg/config"
+	loggerPkg "main/pkg/logger"
+	"main/pkg/tendermint"
+	"main/pkg/tracing"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:paralleltest // disabled due to httpmock usage
+func TestActivityQuerierFail(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder(
+		"GET",
+		"https://example.com/cosmos/auth/v1beta1/accounts/address",
+		httpmock.NewErrorResponder(errors.New("custom error")),
+	)
+	httpmock.RegisterResponder(
+		"GET",
+		`=~^https://example.com/cosmos/tx/v1beta1/txs`,
+		httpmock.NewErrorResponder(errors.New("custom error")),
+	)
+
+	config := &configPkg.Config{Chains: []configPkg.Chain{{
+		Name:            "chain",
+		LCDEndpoint:     "https://example.com",
+		Wallets:         []configPkg.Wallet{{Address: "address"}},
+		SequenceMetrics: true,
+		LastTxMetrics:   true,
+	}}}
+
+	tracer := tracing.InitNoopTracer()
+	logger := loggerPkg.GetNopLogger()
+	querier := NewActivityQuerier(config, tendermint.NewRegistry(config, *logger, tracer), *logger, tracer)
+
+	metrics, queries := querier.GetMetrics(context.Background())
+	assert.Len(t, queries, 2)
+	for _, query := range queries {
+		assert.False(t, query.Success)
+	}
+
+	assert.Len(t, metrics, 4)
+	for _, metric := range metrics {
+		assert.Zero(t, testutil.CollectAndCount(metric))
+	}
+}
+
+//nolint:paralleltest // disabled due to httpmock usage
+func TestActivityQuerierOk(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder(
+		"GET",
+		"https://example.com/cosmos/auth/v1beta1/accounts/base",
+		httpmock.NewBytesResponder(200, assets.GetBytesOrPanic("account-base.json")),
+	)
+	httpmock.RegisterResponder(
+		"GET",
+		"https://example.com/cosmos/auth/v1beta1/accounts/vesting",
+		httpmock.NewBytesResponder(200, assets.GetBytesOrPanic("account-vesting.json")),
+	)
+	httpmock.RegisterResponder(
+		"GET",
+		"https://example.com/cosmos/tx/v1beta1/txs?events=message.sender%3D%27base%27&query=message.sender%3D%27base%27&order_by=ORDER_BY_DESC&limit=1&page=1",
+		httpmock.NewBytesResponder(200, assets.GetBytesOrPanic("txs.json")),
+	)
+	httpmock.RegisterResponder(
+		"GET",
+		`=~^https://example.com/cosmos/tx/v1beta1/txs\?events=message.sender%3D%27vesting%27`,
+		httpmock.NewStringResponder(200, `{"tx_responses":[],"pagination":{"next_key":null,"total":"0"}}`),
+	)
+
+	config := &configPkg.Config{Chains: []configPkg.Chain{{
+		Name:        "chain",
+		LCDEndpoint: "https://example.com",
+		Wallets: []configPkg.Wallet{
+			{Address: "base", Name: "relayer", Group: "relayers"},
+			{Address: "vesting", Name: "team", Group: "team"},
+		},
+		SequenceMetrics: true,
+		LastTxMetrics:   true,
+	}}}
+
+	tracer := tracing.InitNoopTracer()
+	logger := loggerPkg.GetNopLogger()
+	querier := NewActivityQuerier(config, tendermint.NewRegistry(config, *logger, tracer), *logger, tracer)
+
+	metrics, queries := querier.GetMetrics(context.Background())
+	assert.Len(t, queries, 4)
+	for _, query := range queries {
+		assert.True(t, query.Success)
+	}
+
+	require.Len(t, metrics, 4)
+
+	sequenceGauge, ok := metrics[0].(*prometheus.GaugeVec)
+	require.True(t, ok)
+	lastTxTimestampGauge, ok := metrics[1].(*prometheus.GaugeVec)
+	require.True(t, ok)
+	lastTxHeightGauge, ok := metrics[2].(*prometheus.GaugeVec)
+	require.True(t, ok)
+	sentTxsGauge, ok := metrics[3].(*prometheus.GaugeVec)
+	require.True(t, ok)
+
+	baseLabels := prometheus.Labels{"chain": "chain", "address": "base", "name": "relayer", "group": "relayers"}
+	vestingLabels := prometheus.Labels{"chain": "chain", "address": "vesting", "name": "team", "group": "team"}
+
+	assert.Equal(t, 2, testutil.CollectAndCount(sequenceGauge))
+	assert.InDelta(t, 5, testutil.ToFloat64(sequenceGauge.With(baseLabels)), 0.01)
+	assert.Zero(t, testutil.ToFloat64(sequenceGauge.With(vestingLabels)))
+
+	// the vesting wallet has not sent anything yet
+	assert.Equal(t, 1, testutil.CollectAndCount(lastTxTimestampGauge))
+	assert.Equal(t, 1, testutil.CollectAndCount(lastTxHeightGauge))
+	assert.InDelta(t, 1714564800, testutil.ToFloat64(lastTxTimestampGauge.With(baseLabels)), 0.01)
+	assert.InDelta(t, 12345, testutil.ToFloat64(lastTxHeightGauge.With(baseLabels)), 0.01)
+
+	assert.Equal(t, 2, testutil.CollectAndCount(sentTxsGauge))
+	assert.InDelta(t, 42, testutil.ToFloat64(sentTxsGauge.With(baseLabels)), 0.01)
+	assert.Zero(t, testutil.ToFloat64(sentTxsGauge.With(vestingLabels)))
+}
+
+//nolint:paralleltest // disabled due to httpmock usage
+func TestActivityQuerierDisabled(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	config := &configPkg.Config{Chains: []configPkg.Chain{{
+		Name:        "chain",
+		LCDEndpoint: "https://example.com",
+		Wallets:     []configPkg.Wallet{{Address: "address"}},
+	}}}
+
+	tracer := tracing.InitNoopTracer()
+	logger := loggerPkg.GetNopLogger()
+	querier := NewActivityQuerier(config, tendermint.NewRegistry(config, *logger, tracer), *logger, tracer)
+
+	_, queries := querier.GetMetrics(context.Background())
+	assert.Empty(t, queries)
+	assert.Zero(t, httpmock.GetTotalCallCount())
+}
@@ -19,21 +19,16 @@ import (
 type ApplicationQuerier struct {
 	Config *config.Config
 	Logger zerolog.Logger
-	RPCs   []*tendermint.RPC
+	RPCs   *tendermint.Registry
 	Tracer trace.Tracer
 }
 
 func NewApplicationQuerier(
 	config *config.Config,
+	rpcs *tendermint.Registry,
 	logger zerolog.Logger,
 	tracer trace.Tracer,
 ) *ApplicationQuerier {
-	rpcs := make([]*tendermint.RPC, len(config.Chains))
-
-	for index, chain := range config.Chains {
-		rpcs[index] = tendermint.NewRPC(chain, logger, tracer)
-	}
-
 	return &ApplicationQuerier{
 		Config: config,
 		Logger: logger.With().Str("component", "application_querier").Logger(),
@@ -42,16 +37,22 @@ func NewApplicationQuerier(
 	}
 }
 
+func (q *ApplicationQuerier) Name() string {
+	return "application"
+}
+
 func (q *ApplicationQuerier) GetMetrics(ctx context.Context) ([]prometheus.Collector, []types.QueryInfo) {
 	childCtx, span := q.Tracer.Start(ctx, "Querying application stake metrics")
 	defer span.End()
 
+	customLabelNames := q.Config.GetLabelNames()
+
 	applicationStakeGauge := prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "cosmos_wallets_exporter_application_stake",
 			Help: "A Pocket Network application stake (in tokens)",
 		},
-		[]string{"chain", "address", "name", "group", "denom"},
+		withCustomLabelNames(config.WalletDenomLabelNames, customLabelNames),
 	)
 
 	var queryInfos []types.QueryInfo
@@ -59,8 +60,8 @@ func (q *ApplicationQuerier) GetMetrics(ctx context.Context) ([]prometheus.Colle
 	var wg sync.WaitGroup
 	var mutex sync.Mutex
 
-	for index, chain := range q.Config.Chains {
-		rpc := q.RPCs[index]
+	for _, chain := range q.Config.Chains {
+		rpc := q.RPCs.Get(chain)
 
 		for _, application := range chain.Applications {
 			wg.Add(1)
@@ -109,13 +110,13 @@ func (q *ApplicationQuerier) GetMetrics(ctx context.Context) ([]prometheus.Colle
 					amount /= math.Pow10(denomInfo.DenomExponent)
 				}
 
-				applicationStakeGauge.With(prometheus.Labels{
+				applicationStakeGauge.With(withCustomLabels(prometheus.Labels{
 					"chain":   chain.Name,
 					"address": application.Address,
 					"name":    application.Name,
 					"group":   application.Group,
 					"denom":   denom,
-				}).Set(amount)
+				}, customLabelNames, config.MergeLabels(chain.Labels, application.Labels))).Set(amount)
 			}(application, chain, rpc)
 		}
 	}
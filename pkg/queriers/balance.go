@@ -3,10 +3,12 @@ package queriers
 import (
 	"context"
 	"main/pkg/config"
+	"main/pkg/history"
 	"main/pkg/tendermint"
 	"main/pkg/types"
 	"math"
 	"sync"
+	"time"
 
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
@@ -16,53 +18,149 @@ import (
 )
 
 type BalanceQuerier struct {
-	Config *config.Config
-	Logger zerolog.Logger
-	RPCs   []*tendermint.RPC
-	Tracer trace.Tracer
+	Config  *config.Config
+	Logger  zerolog.Logger
+	RPCs    *tendermint.Registry
+	History *history.Store
+	Tracer  trace.Tracer
 }
 
 func NewBalanceQuerier(
 	config *config.Config,
+	rpcs *tendermint.Registry,
+	history *history.Store,
 	logger zerolog.Logger,
 	tracer trace.Tracer,
 ) *BalanceQuerier {
-	rpcs := make([]*tendermint.RPC, len(config.Chains))
-
-	for index, chain := range config.Chains {
-		rpcs[index] = tendermint.NewRPC(chain, logger, tracer)
-	}
-
 	return &BalanceQuerier{
-		Config: config,
-		Logger: logger.With().Str("component", "balance_querier").Logger(),
-		RPCs:   rpcs,
-		Tracer: tracer,
+		Config:  config,
+		Logger:  logger.With().Str("component", "balance_querier").Logger(),
+		RPCs:    rpcs,
+		History: history,
+		Tracer:  tracer,
 	}
 }
 
+func (q *BalanceQuerier) Name() string {
+	return "balance"
+}
+
 func (q *BalanceQuerier) GetMetrics(ctx context.Context) ([]prometheus.Collector, []types.QueryInfo) {
 	childCtx, span := q.Tracer.Start(ctx, "Querying balance metrics")
 	defer span.End()
 
+	customLabelNames := q.Config.GetLabelNames()
+	labelNames := withCustomLabelNames(config.WalletDenomLabelNames, customLabelNames)
+
 	balancesGauge := prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "cosmos_wallets_exporter_balance",
 			Help: "A wallet balance (in tokens)",
 		},
-		[]string{"chain", "address", "name", "group", "denom"},
+		labelNames,
+	)
+
+	deltaGauge := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cosmos_wallets_exporter_balance_delta",
+			Help: "A wallet balance change within the history window (in tokens)",
+		},
+		labelNames,
+	)
+
+	spendRateGauge := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cosmos_wallets_exporter_balance_spend_rate",
+			Help: "An average wallet spend rate within the history window (in tokens per day)",
+		},
+		labelNames,
+	)
+
+	daysUntilEmptyGauge := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cosmos_wallets_exporter_balance_days_until_empty",
+			Help: "Projected days until a wallet balance is spent at the current spend rate",
+		},
+		labelNames,
 	)
 
 	var queryInfos []types.QueryInfo
+	var samples []history.Sample
+
+	// custom labels by chain and address, as they are not stored in history
+	walletsLabels := make(map[string]map[string]string)
+
+	now := time.Now()
 
 	var wg sync.WaitGroup
 	var mutex sync.Mutex
 
-	for index, chain := range q.Config.Chains {
-		rpc := q.RPCs[index]
+	// should be called with the mutex locked
+	setBalance := func(chain config.Chain, wallet config.Wallet, balance types.Balance) {
+		denom := balance.Denom
+		amount := balance.Amount.MustFloat64()
+
+		denomInfo, found := chain.FindDenomByName(balance.Denom)
+		if found {
+			denom = denomInfo.GetName()
+			amount /= math.Pow10(denomInfo.DenomExponent)
+		}
+
+		balancesGauge.With(withCustomLabels(prometheus.Labels{
+			"chain":   chain.Name,
+			"address": wallet.Address,
+			"name":    wallet.Name,
+			"group":   wallet.Group,
+			"denom":   denom,
+		}, customLabelNames, wallet.Labels)).Set(amount)
+
+		samples = append(samples, history.Sample{
+			Time:    now,
+			Chain:   chain.Name,
+			Address: wallet.Address,
+			Name:    wallet.Name,
+			Group:   wallet.Group,
+			Denom:   denom,
+			Amount:  amount,
+		})
+	}
+
+	for _, chain := range q.Config.Chains {
+		rpc := q.RPCs.Get(chain)
+
+		// Applications and suppliers are monitored as wallets too (for liquid balance monitoring)
+		wallets := make([]config.Wallet, 0, len(chain.Wallets)+len(chain.Applications)+len(chain.Suppliers))
 
-		// Monitor configured wallets
 		for _, wallet := range chain.Wallets {
+			wallets = append(wallets, config.Wallet{
+				Address: wallet.Address,
+				Name:    wallet.Name,
+				Group:   wallet.Group,
+				Labels:  config.MergeLabels(chain.Labels, wallet.Labels),
+			})
+		}
+
+		for _, application := range chain.Applications {
+			wallets = append(wallets, config.Wallet{
+				Address: application.Address,
+				Name:    application.Name,
+				Group:   application.Group,
+				Labels:  config.MergeLabels(chain.Labels, application.Labels),
+			})
+		}
+
+		for _, supplier := range chain.Suppliers {
+			wallets = append(wallets, config.Wallet{
+				Address: supplier.Address,
+				Name:    supplier.Name,
+				Group:   supplier.Group,
+				Labels:  config.MergeLabels(chain.Labels, supplier.Labels),
+			})
+		}
+
+		for _, wallet := range wallets {
+			walletsLabels[getWalletKey(chain.Name, wallet.Address)] = wallet.Labels
+
 			wg.Add(1)
 			go func(wallet config.Wallet, chain config.Chain, rpc *tendermint.RPC) {
 				chainCtx, chainSpan := q.Tracer.Start(childCtx, "Querying chain and wallet")
@@ -89,124 +187,124 @@ func (q *BalanceQuerier) GetMetrics(ctx context.Context) ([]prometheus.Collector
 				}
 
 				for _, balance := range balancesResponse.Balances {
-					denom := balance.Denom
-					amount := balance.Amount.MustFloat64()
-
-					denomInfo, found := chain.FindDenomByName(balance.Denom)
-					if found {
-						denom = denomInfo.GetName()
-						amount /= math.Pow10(denomInfo.DenomExponent)
-					}
-
-					balancesGauge.With(prometheus.Labels{
-						"chain":   chain.Name,
-						"address": wallet.Address,
-						"name":    wallet.Name,
-						"group":   wallet.Group,
-						"denom":   denom,
-					}).Set(amount)
+					setBalance(chain, wallet, balance)
 				}
 			}(wallet, chain, rpc)
-		}
-
-		// Also monitor applications as wallets (for liquid balance monitoring)
-		for _, application := range chain.Applications {
-			wg.Add(1)
-			go func(application config.Application, chain config.Chain, rpc *tendermint.RPC) {
-				chainCtx, chainSpan := q.Tracer.Start(childCtx, "Querying chain and application wallet balance")
-				chainSpan.SetAttributes(attribute.String("chain", chain.Name))
-				chainSpan.SetAttributes(attribute.String("wallet", application.Address))
-				defer chainSpan.End()
 
-				defer wg.Done()
+			for _, denom := range chain.Denoms {
+				if !denom.IsCW20() {
+					continue
+				}
 
-				balancesResponse, queryInfo, err := rpc.GetWalletBalances(application.Address, chainCtx)
+				wg.Add(1)
+				go func(wallet config.Wallet, chain config.Chain, denom config.DenomInfo, rpc *tendermint.RPC) {
+					chainCtx, chainSpan := q.Tracer.Start(childCtx, "Querying chain and wallet CW20 token")
+					chainSpan.SetAttributes(attribute.String("chain", chain.Name))
+					chainSpan.SetAttributes(attribute.String("wallet", wallet.Address))
+					chainSpan.SetAttributes(attribute.String("contract", denom.CW20Contract))
+					defer chainSpan.End()
 
-				mutex.Lock()
-				defer mutex.Unlock()
+					defer wg.Done()
 
-				queryInfos = append(queryInfos, queryInfo)
+					balanceResponse, queryInfo, err := rpc.GetCW20Balance(denom.CW20Contract, wallet.Address, chainCtx)
 
-				if err != nil {
-					q.Logger.Error().
-						Err(err).
-						Str("chain", chain.Name).
-						Str("wallet", application.Address).
-						Msg("Error querying application wallet balance")
-					return
-				}
+					mutex.Lock()
+					defer mutex.Unlock()
 
-				for _, balance := range balancesResponse.Balances {
-					denom := balance.Denom
-					amount := balance.Amount.MustFloat64()
+					queryInfos = append(queryInfos, queryInfo)
 
-					denomInfo, found := chain.FindDenomByName(balance.Denom)
-					if found {
-						denom = denomInfo.GetName()
-						amount /= math.Pow10(denomInfo.DenomExponent)
+					if err != nil {
+						q.Logger.Error().
+							Err(err).
+							Str("chain", chain.Name).
+							Str("wallet", wallet.Address).
+							Str("contract", denom.CW20Contract).
+							Msg("Error querying CW20 token balance")
+						return
 					}
 
-					balancesGauge.With(prometheus.Labels{
-						"chain":   chain.Name,
-						"address": application.Address,
-						"name":    application.Name,
-						"group":   application.Group,
-						"denom":   denom,
-					}).Set(amount)
-				}
-			}(application, chain, rpc)
+					setBalance(chain, wallet, types.Balance{
+						Denom:  denom.GetDenom(),
+						Amount: balanceResponse.Data.Balance,
+					})
+				}(wallet, chain, denom, rpc)
+			}
 		}
+	}
 
-		// Also monitor suppliers as wallets (for liquid balance monitoring)
-		for _, supplier := range chain.Suppliers {
-			wg.Add(1)
-			go func(supplier config.Supplier, chain config.Chain, rpc *tendermint.RPC) {
-				chainCtx, chainSpan := q.Tracer.Start(childCtx, "Querying chain and supplier wallet balance")
-				chainSpan.SetAttributes(attribute.String("chain", chain.Name))
-				chainSpan.SetAttributes(attribute.String("wallet", supplier.Address))
-				defer chainSpan.End()
+	wg.Wait()
 
-				defer wg.Done()
+	if q.History != nil {
+		q.processHistory(
+			samples,
+			now,
+			customLabelNames,
+			walletsLabels,
+			deltaGauge,
+			spendRateGauge,
+			daysUntilEmptyGauge,
+		)
+	}
 
-				balancesResponse, queryInfo, err := rpc.GetWalletBalances(supplier.Address, chainCtx)
+	return []prometheus.Collector{
+		balancesGauge,
+		deltaGauge,
+		spendRateGauge,
+		daysUntilEmptyGauge,
+	}, queryInfos
+}
 
-				mutex.Lock()
-				defer mutex.Unlock()
+func (q *BalanceQuerier) processHistory(
+	samples []history.Sample,
+	now time.Time,
+	customLabelNames []string,
+	walletsLabels map[string]map[string]string,
+	deltaGauge *prometheus.GaugeVec,
+	spendRateGauge *prometheus.GaugeVec,
+	daysUntilEmptyGauge *prometheus.GaugeVec,
+) {
+	if err := q.History.Record(samples); err != nil {
+		q.Logger.Error().Err(err).Msg("Error recording balances history")
+		return
+	}
 
-				queryInfos = append(queryInfos, queryInfo)
+	from := now.Add(-q.Config.HistoryConfig.GetWindow())
+
+	for _, sample := range samples {
+		stats, ok, err := q.History.GetStats(sample, from)
+		if err != nil {
+			q.Logger.Error().
+				Err(err).
+				Str("chain", sample.Chain).
+				Str("wallet", sample.Address).
+				Msg("Error querying balances history")
+			continue
+		}
 
-				if err != nil {
-					q.Logger.Error().
-						Err(err).
-						Str("chain", chain.Name).
-						Str("wallet", supplier.Address).
-						Msg("Error querying supplier wallet balance")
-					return
-				}
+		if !ok {
+			continue
+		}
 
-				for _, balance := range balancesResponse.Balances {
-					denom := balance.Denom
-					amount := balance.Amount.MustFloat64()
+		labels := withCustomLabels(prometheus.Labels{
+			"chain":   sample.Chain,
+			"address": sample.Address,
+			"name":    sample.Name,
+			"group":   sample.Group,
+			"denom":   sample.Denom,
+		}, customLabelNames, walletsLabels[getWalletKey(sample.Chain, sample.Address)])
 
-					denomInfo, found := chain.FindDenomByName(balance.Denom)
-					if found {
-						denom = denomInfo.GetName()
-						amount /= math.Pow10(denomInfo.DenomExponent)
-					}
+		deltaGauge.With(labels).Set(stats.Delta)
 
-					balancesGauge.With(prometheus.Labels{
-						"chain":   chain.Name,
-						"address": supplier.Address,
-						"name":    supplier.Name,
-						"group":   supplier.Group,
-						"denom":   denom,
-					}).Set(amount)
-				}
-			}(supplier, chain, rpc)
+		if stats.HasSpendRate {
+			spendRateGauge.With(labels).Set(stats.SpendRate)
 		}
-	}
 
-	wg.Wait()
+		if stats.SpendRate > 0 {
+			daysUntilEmptyGauge.With(labels).Set(stats.DaysUntilEmpty)
+		}
+	}
+}
 
-	return []prometheus.Collector{balancesGauge}, queryInfos
+func getWalletKey(chain string, address string) string {
+	return chain + "/" + address
 }
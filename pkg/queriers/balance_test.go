@@ -5,14 +5,19 @@ import (
 	"errors"
 	"main/assets"
 	configPkg "main/pkg/config"
+	"main/pkg/history"
 	loggerPkg "main/pkg/logger"
+	"main/pkg/tendermint"
 	"main/pkg/tracing"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/jarcoal/httpmock"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 //nolint:paralleltest // disabled due to httpmock usage
@@ -34,13 +39,13 @@ func TestBalanceQuerierFail(t *testing.T) {
 
 	tracer := tracing.InitNoopTracer()
 	logger := loggerPkg.GetNopLogger()
-	querier := NewBalanceQuerier(config, *logger, tracer)
+	querier := NewBalanceQuerier(config, tendermint.NewRegistry(config, *logger, tracer), nil, *logger, tracer)
 
 	metrics, queries := querier.GetMetrics(context.Background())
 	assert.Len(t, queries, 1)
 	assert.False(t, queries[0].Success)
 
-	assert.Len(t, metrics, 1)
+	assert.Len(t, metrics, 4)
 	assert.Zero(t, testutil.CollectAndCount(metrics[0]))
 }
 
@@ -68,12 +73,12 @@ func TestBalanceQuerierOk(t *testing.T) {
 
 	tracer := tracing.InitNoopTracer()
 	logger := loggerPkg.GetNopLogger()
-	querier := NewBalanceQuerier(config, *logger, tracer)
+	querier := NewBalanceQuerier(config, tendermint.NewRegistry(config, *logger, tracer), nil, *logger, tracer)
 
 	metrics, queries := querier.GetMetrics(context.Background())
 	assert.Len(t, queries, 1)
 	assert.True(t, queries[0].Success)
-	assert.Len(t, metrics, 1)
+	assert.Len(t, metrics, 4)
 
 	balance, ok := metrics[0].(*prometheus.GaugeVec)
 	assert.True(t, ok)
@@ -94,3 +99,221 @@ func TestBalanceQuerierOk(t *testing.T) {
 		"group":   "group",
 	})), 0.01)
 }
+
+//nolint:paralleltest // disabled due to httpmock usage
+func TestBalanceQuerierCW20(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder(
+		"GET",
+		"https://example.com/cosmos/bank/v1beta1/balances/address",
+		httpmock.NewBytesResponder(200, assets.GetBytesOrPanic("balance.json")),
+	)
+	// base64 of {"balance":{"address":"address"}}
+	httpmock.RegisterResponder(
+		"GET",
+		"https://example.com/cosmwasm/wasm/v1/contract/contract1/smart/eyJiYWxhbmNlIjp7ImFkZHJlc3MiOiJhZGRyZXNzIn19",
+		httpmock.NewBytesResponder(200, assets.GetBytesOrPanic("cw20-balance.json")),
+	)
+	httpmock.RegisterResponder(
+		"GET",
+		"https://example.com/cosmwasm/wasm/v1/contract/contract2/smart/eyJiYWxhbmNlIjp7ImFkZHJlc3MiOiJhZGRyZXNzIn19",
+		httpmock.NewErrorResponder(errors.New("custom error")),
+	)
+
+	config := &configPkg.Config{Chains: []configPkg.Chain{{
+		Name:        "chain",
+		LCDEndpoint: "https://example.com",
+		Wallets:     []configPkg.Wallet{{Address: "address", Name: "name", Group: "group"}},
+		Denoms: []configPkg.DenomInfo{
+			{Denom: "uatom", DisplayDenom: "atom", DenomExponent: 6},
+			{CW20Contract: "contract1", DisplayDenom: "token", DenomExponent: 8},
+			{CW20Contract: "contract2", DenomExponent: 6},
+		},
+	}}}
+
+	tracer := tracing.InitNoopTracer()
+	logger := loggerPkg.GetNopLogger()
+	querier := NewBalanceQuerier(config, tendermint.NewRegistry(config, *logger, tracer), nil, *logger, tracer)
+
+	metrics, queries := querier.GetMetrics(context.Background())
+	assert.Len(t, queries, 3)
+
+	failed := 0
+	for _, query := range queries {
+		if !query.Success {
+			failed++
+		}
+	}
+
+	assert.Equal(t, 1, failed)
+
+	balance, ok := metrics[0].(*prometheus.GaugeVec)
+	require.True(t, ok)
+
+	assert.Equal(t, 3, testutil.CollectAndCount(balance))
+	assert.InDelta(t, 25, testutil.ToFloat64(balance.With(prometheus.Labels{
+		"chain":   "chain",
+		"denom":   "token",
+		"address": "address",
+		"name":    "name",
+		"group":   "group",
+	})), 0.01)
+}
+
+//nolint:paralleltest // disabled due to httpmock usage
+func TestBalanceQuerierDeduplicatesAddresses(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder(
+		"GET",
+		"https://example.com/cosmos/bank/v1beta1/balances/address",
+		httpmock.NewBytesResponder(200, assets.GetBytesOrPanic("balance.json")),
+	)
+
+	config := &configPkg.Config{Chains: []configPkg.Chain{{
+		Name:        "chain",
+		LCDEndpoint: "https://example.com",
+		Wallets:     []configPkg.Wallet{{Address: "address", Name: "wallet"}},
+		Suppliers:   []configPkg.Supplier{{Address: "address", Name: "supplier"}},
+	}}}
+
+	tracer := tracing.InitNoopTracer()
+	logger := loggerPkg.GetNopLogger()
+	querier := NewBalanceQuerier(config, tendermint.NewRegistry(config, *logger, tracer), nil, *logger, tracer)
+
+	ctx := tendermint.ContextWithQueryCache(context.Background(), tendermint.NewQueryCache())
+	metrics, queries := querier.GetMetrics(ctx)
+	assert.Len(t, queries, 2)
+	assert.Equal(t, 1, httpmock.GetTotalCallCount())
+
+	cached := 0
+	for _, query := range queries {
+		assert.True(t, query.Success)
+		if query.Cached {
+			cached++
+		}
+	}
+	assert.Equal(t, 1, cached)
+
+	balance, ok := metrics[0].(*prometheus.GaugeVec)
+	assert.True(t, ok)
+	assert.Equal(t, 4, testutil.CollectAndCount(balance))
+}
+
+//nolint:paralleltest // disabled due to httpmock usage
+func TestBalanceQuerierHistory(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder(
+		"GET",
+		"https://example.com/cosmos/bank/v1beta1/balances/address",
+		httpmock.NewBytesResponder(200, assets.GetBytesOrPanic("balance.json")),
+	)
+
+	config := &configPkg.Config{
+		Chains: []configPkg.Chain{{
+			Name:        "chain",
+			LCDEndpoint: "https://example.com",
+			Wallets:     []configPkg.Wallet{{Address: "address", Name: "name", Group: "group"}},
+			Denoms:      []configPkg.DenomInfo{{Denom: "uatom", DisplayDenom: "atom", DenomExponent: 6}},
+		}},
+		HistoryConfig: configPkg.HistoryConfig{Retention: "48h", Window: "24h", Interval: "5m"},
+	}
+
+	store, err := history.NewStore(filepath.Join(t.TempDir(), "history.db"), 48*time.Hour, 5*time.Minute)
+	require.NoError(t, err)
+	defer store.Close()
+
+	// the wallet had 1 atom 12 hours ago and 0.123456 atom now
+	err = store.Record([]history.Sample{{
+		Time:    time.Now().Add(-12 * time.Hour),
+		Chain:   "chain",
+		Address: "address",
+		Denom:   "atom",
+		Amount:  1,
+	}})
+	require.NoError(t, err)
+
+	tracer := tracing.InitNoopTracer()
+	logger := loggerPkg.GetNopLogger()
+	querier := NewBalanceQuerier(config, tendermint.NewRegistry(config, *logger, tracer), store, *logger, tracer)
+
+	metrics, queries := querier.GetMetrics(context.Background())
+	assert.Len(t, queries, 1)
+	require.Len(t, metrics, 4)
+
+	labels := prometheus.Labels{
+		"chain":   "chain",
+		"denom":   "atom",
+		"address": "address",
+		"name":    "name",
+		"group":   "group",
+	}
+
+	deltaGauge, ok := metrics[1].(*prometheus.GaugeVec)
+	require.True(t, ok)
+	// ustake has only one sample, so no stats for it yet
+	assert.Equal(t, 1, testutil.CollectAndCount(deltaGauge))
+	assert.InDelta(t, -0.876544, testutil.ToFloat64(deltaGauge.With(labels)), 0.001)
+
+	spendRateGauge, ok := metrics[2].(*prometheus.GaugeVec)
+	require.True(t, ok)
+	assert.InDelta(t, 1.753088, testutil.ToFloat64(spendRateGauge.With(labels)), 0.001)
+
+	daysUntilEmptyGauge, ok := metrics[3].(*prometheus.GaugeVec)
+	require.True(t, ok)
+	assert.InDelta(t, 0.070422, testutil.ToFloat64(daysUntilEmptyGauge.With(labels)), 0.001)
+}
+
+//nolint:paralleltest // disabled due to httpmock usage
+func TestBalanceQuerierCustomLabels(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder(
+		"GET",
+		`=~^https://example.com/cosmos/bank/v1beta1/balances/`,
+		httpmock.NewBytesResponder(200, assets.GetBytesOrPanic("balance.json")),
+	)
+
+	config := &configPkg.Config{Chains: []configPkg.Chain{{
+		Name:         "chain",
+		LCDEndpoint:  "https://example.com",
+		Labels:       map[string]string{"environment": "production"},
+		Wallets:      []configPkg.Wallet{{Address: "wallet", Labels: map[string]string{"team": "relayers"}}},
+		Applications: []configPkg.Application{{Address: "application"}},
+	}}}
+
+	tracer := tracing.InitNoopTracer()
+	logger := loggerPkg.GetNopLogger()
+	querier := NewBalanceQuerier(config, tendermint.NewRegistry(config, *logger, tracer), nil, *logger, tracer)
+
+	metrics, _ := querier.GetMetrics(context.Background())
+
+	balance, ok := metrics[0].(*prometheus.GaugeVec)
+	require.True(t, ok)
+
+	assert.Equal(t, 4, testutil.CollectAndCount(balance))
+	assert.InDelta(t, 234567, testutil.ToFloat64(balance.With(prometheus.Labels{
+		"chain":       "chain",
+		"denom":       "ustake",
+		"address":     "wallet",
+		"name":        "",
+		"group":       "",
+		"environment": "production",
+		"team":        "relayers",
+	})), 0.01)
+	assert.InDelta(t, 234567, testutil.ToFloat64(balance.With(prometheus.Labels{
+		"chain":       "chain",
+		"denom":       "ustake",
+		"address":     "application",
+		"name":        "",
+		"group":       "",
+		"environment": "production",
+		"team":        "",
+	})), 0.01)
+}
@@ -0,0 +1,77 @@
+package queriers
+
+import (
+	"main/pkg/config"
+	"main/pkg/tendermint"
+	"main/pkg/types"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type HeightsQuerier struct {
+	Config *config.Config
+	RPCs   *tendermint.Registry
+}
+
+func NewHeightsQuerier(appConfig *config.Config, rpcs *tendermint.Registry) *HeightsQuerier {
+	return &HeightsQuerier{
+		Config: appConfig,
+		RPCs:   rpcs,
+	}
+}
+
+func (q *HeightsQuerier) GetMetrics() ([]prometheus.Collector, []types.QueryInfo) {
+	endpointHeightGauge := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cosmos_wallets_exporter_last_seen_height",
+			Help: "The latest block height returned by an LCD endpoint",
+		},
+		config.EndpointLabelNames,
+	)
+
+	queryHeightGauge := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cosmos_wallets_exporter_query_last_seen_height",
+			Help: "The latest block height returned by an LCD endpoint for a query type",
+		},
+		config.EndpointQueryLabelNames,
+	)
+
+	heights := q.RPCs.GetHeights()
+
+	for _, chain := range q.Config.Chains {
+		chainHeights, ok := heights[chain.Name]
+		if !ok {
+			continue
+		}
+
+		// only the currently configured endpoint, the rest is kept for persistence only
+		endpointHeights, ok := chainHeights[chain.GetDisplayLCDEndpoint()]
+		if !ok {
+			continue
+		}
+
+		endpointHeightGauge.With(prometheus.Labels{
+			"chain":    chain.Name,
+			"endpoint": chain.GetDisplayLCDEndpoint(),
+		}).Set(float64(endpointHeights.Height))
+
+		// heights are tracked per address, but exported per query type only, to keep cardinality low
+		queryHeights := make(map[string]int64)
+		for key, height := range endpointHeights.Keys {
+			query, _, _ := strings.Cut(key, ":")
+			queryHeights[query] = max(queryHeights[query], height)
+		}
+
+		for query, height := range queryHeights {
+			queryHeightGauge.With(prometheus.Labels{
+				"chain":    chain.Name,
+				"endpoint": chain.GetDisplayLCDEndpoint(),
+				"query":    query,
+			}).Set(float64(height))
+		}
+	}
+
+	return []prometheus.Collector{endpointHeightGauge, queryHeightGauge}, []types.QueryInfo{}
+}
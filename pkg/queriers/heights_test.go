@@ -0,0 +1,71 @@
+package queriers
+
+import (
+	configPkg "main/pkg/config"
+	loggerPkg "main/pkg/logger"
+	"main/pkg/tendermint"
+	"main/pkg/tracing"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeightsQuerier(t *testing.T) {
+	t.Parallel()
+
+	config := &configPkg.Config{Chains: []configPkg.Chain{
+		{Name: "chain", LCDEndpoint: "https://example.com"},
+		{Name: "chain2", LCDEndpoint: "https://example2.com"},
+	}}
+
+	rpcs := tendermint.NewRegistry(config, *loggerPkg.GetNopLogger(), tracing.InitNoopTracer())
+	now := time.Now()
+	rpcs.RestoreHeights(map[string]map[string]tendermint.EndpointHeights{
+		"chain": {
+			"https://example.com": {
+				Height:    100,
+				UpdatedAt: now,
+				Keys:      map[string]int64{"balance:address": 90, "balance:address2": 80, "shared_params": 100},
+				Updated:   map[string]time.Time{"balance:address": now, "balance:address2": now, "shared_params": now},
+			},
+			"https://old.com": {
+				Height:    50,
+				UpdatedAt: now,
+				Keys:      map[string]int64{"balance:address": 50},
+				Updated:   map[string]time.Time{"balance:address": now},
+			},
+		},
+	})
+
+	querier := NewHeightsQuerier(config, rpcs)
+	metrics, queryInfos := querier.GetMetrics()
+	assert.Empty(t, queryInfos)
+	require.Len(t, metrics, 2)
+
+	endpointGauge, ok := metrics[0].(*prometheus.GaugeVec)
+	require.True(t, ok)
+	assert.Equal(t, 1, testutil.CollectAndCount(endpointGauge))
+	assert.InDelta(t, 100, testutil.ToFloat64(endpointGauge.With(prometheus.Labels{
+		"chain":    "chain",
+		"endpoint": "https://example.com",
+	})), 0.01)
+
+	keyGauge, ok := metrics[1].(*prometheus.GaugeVec)
+	require.True(t, ok)
+	assert.Equal(t, 2, testutil.CollectAndCount(keyGauge))
+	// the highest height of all the addresses of a query type
+	assert.InDelta(t, 90, testutil.ToFloat64(keyGauge.With(prometheus.Labels{
+		"chain":    "chain",
+		"endpoint": "https://example.com",
+		"query":    "balance",
+	})), 0.01)
+	assert.InDelta(t, 100, testutil.ToFloat64(keyGauge.With(prometheus.Labels{
+		"chain":    "chain",
+		"endpoint": "https://example.com",
+		"query":    "shared_params",
+	})), 0.01)
+}
@@ -0,0 +1,26 @@
+package queriers
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// withCustomLabelNames returns the metric label names followed by custom label names.
+func withCustomLabelNames(names []string, customNames []string) []string {
+	result := make([]string, 0, len(names)+len(customNames))
+	result = append(result, names...)
+	return append(result, customNames...)
+}
+
+// withCustomLabels adds custom labels values to metric labels, using empty values
+// for the custom labels the entry does not have, so the labels match the GaugeVec ones.
+func withCustomLabels(
+	labels prometheus.Labels,
+	customNames []string,
+	customLabels map[string]string,
+) prometheus.Labels {
+	for _, name := range customNames {
+		labels[name] = customLabels[name]
+	}
+
+	return labels
+}
@@ -0,0 +1,158 @@
+package queriers
+
+import (
+	"context"
+	"main/pkg/config"
+	"main/pkg/tendermint"
+	"main/pkg/types"
+	"main/pkg/utils"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+)
+
+type NodeStatusQuerier struct {
+	Config *config.Config
+	Logger zerolog.Logger
+	RPCs   *tendermint.Registry
+	Tracer trace.Tracer
+}
+
+func NewNodeStatusQuerier(
+	config *config.Config,
+	rpcs *tendermint.Registry,
+	logger zerolog.Logger,
+	tracer trace.Tracer,
+) *NodeStatusQuerier {
+	return &NodeStatusQuerier{
+		Config: config,
+		Logger: logger.With().Str("component", "node_status_querier").Logger(),
+		RPCs:   rpcs,
+		Tracer: tracer,
+	}
+}
+
+func (q *NodeStatusQuerier) Name() string {
+	return "node_status"
+}
+
+func (q *NodeStatusQuerier) GetMetrics(ctx context.Context) ([]prometheus.Collector, []types.QueryInfo) {
+	childCtx, span := q.Tracer.Start(ctx, "Querying node status metrics")
+	defer span.End()
+
+	heightGauge := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cosmos_wallets_exporter_node_latest_block_height",
+			Help: "The latest block height of an LCD endpoint node",
+		},
+		config.EndpointLabelNames,
+	)
+
+	blockAgeGauge := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cosmos_wallets_exporter_node_latest_block_age_seconds",
+			Help: "Time passed since the latest block of an LCD endpoint node, in seconds",
+		},
+		config.EndpointLabelNames,
+	)
+
+	syncingGauge := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cosmos_wallets_exporter_node_syncing",
+			Help: "Whether an LCD endpoint node is catching up with the chain",
+		},
+		config.EndpointLabelNames,
+	)
+
+	var queryInfos []types.QueryInfo
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+
+	for _, chain := range q.Config.Chains {
+		rpc := q.RPCs.Get(chain)
+
+		wg.Add(2)
+
+		go func(chain config.Chain, rpc *tendermint.RPC) {
+			chainCtx, chainSpan := q.Tracer.Start(childCtx, "Querying chain latest block")
+			chainSpan.SetAttributes(attribute.String("chain", chain.Name))
+			defer chainSpan.End()
+
+			defer wg.Done()
+
+			blockResponse, queryInfo, err := rpc.GetLatestBlock(chainCtx)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			queryInfos = append(queryInfos, queryInfo)
+
+			if err != nil {
+				q.Logger.Error().
+					Err(err).
+					Str("chain", chain.Name).
+					Msg("Error querying latest block")
+				return
+			}
+
+			header := blockResponse.Block.Header
+
+			height, err := strconv.ParseInt(header.Height, 10, 64)
+			if err != nil {
+				q.Logger.Error().
+					Err(err).
+					Str("chain", chain.Name).
+					Str("height", header.Height).
+					Msg("Error parsing latest block height")
+				return
+			}
+
+			labels := prometheus.Labels{
+				"chain":    chain.Name,
+				"endpoint": chain.GetDisplayLCDEndpoint(),
+			}
+
+			heightGauge.With(labels).Set(float64(height))
+			blockAgeGauge.With(labels).Set(time.Since(header.Time).Seconds())
+		}(chain, rpc)
+
+		go func(chain config.Chain, rpc *tendermint.RPC) {
+			chainCtx, chainSpan := q.Tracer.Start(childCtx, "Querying chain syncing status")
+			chainSpan.SetAttributes(attribute.String("chain", chain.Name))
+			defer chainSpan.End()
+
+			defer wg.Done()
+
+			syncingResponse, queryInfo, err := rpc.GetSyncing(chainCtx)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			queryInfos = append(queryInfos, queryInfo)
+
+			if err != nil {
+				q.Logger.Error().
+					Err(err).
+					Str("chain", chain.Name).
+					Msg("Error querying syncing status")
+				return
+			}
+
+			syncingGauge.With(prometheus.Labels{
+				"chain":    chain.Name,
+				"endpoint": chain.GetDisplayLCDEndpoint(),
+			}).Set(utils.BoolToFloat64(syncingResponse.Syncing))
+		}(chain, rpc)
+	}
+
+	wg.Wait()
+
+	return []prometheus.Collector{heightGauge, blockAgeGauge, syncingGauge}, queryInfos
+}
@@ -0,0 +1,160 @@
+package queriers
+
+import (
+	"context"
+	"errors"
+	"main/assets"
+	configPkg "main/pkg/config"
+	"main/pkg/constants"
+	loggerPkg "main/pkg/logger"
+	"main/pkg/tendermint"
+	"main/pkg/tracing"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:paralleltest // disabled due to httpmock usage
+func TestNodeStatusQuerierFail(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder(
+		"GET",
+		"https://example.com/cosmos/base/tendermint/v1beta1/blocks/latest",
+		httpmock.NewErrorResponder(errors.New("custom error")),
+	)
+	httpmock.RegisterResponder(
+		"GET",
+		"https://example.com/cosmos/base/tendermint/v1beta1/syncing",
+		httpmock.NewErrorResponder(errors.New("custom error")),
+	)
+
+	config := &configPkg.Config{Chains: []configPkg.Chain{{
+		Name:        "chain",
+		LCDEndpoint: "https://example.com",
+	}}}
+
+	tracer := tracing.InitNoopTracer()
+	logger := loggerPkg.GetNopLogger()
+	querier := NewNodeStatusQuerier(config, tendermint.NewRegistry(config, *logger, tracer), *logger, tracer)
+
+	metrics, queries := querier.GetMetrics(context.Background())
+	assert.Len(t, queries, 2)
+	assert.False(t, queries[0].Success)
+	assert.False(t, queries[1].Success)
+
+	require.Len(t, metrics, 3)
+	for _, metric := range metrics {
+		assert.Zero(t, testutil.CollectAndCount(metric))
+	}
+}
+
+//nolint:paralleltest // disabled due to httpmock usage
+func TestNodeStatusQuerierOk(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder(
+		"GET",
+		"https://example.com/cosmos/base/tendermint/v1beta1/blocks/latest",
+		httpmock.NewBytesResponder(200, assets.GetBytesOrPanic("latest-block.json")),
+	)
+	httpmock.RegisterResponder(
+		"GET",
+		"https://example.com/cosmos/base/tendermint/v1beta1/syncing",
+		httpmock.NewBytesResponder(200, assets.GetBytesOrPanic("syncing.json")),
+	)
+
+	config := &configPkg.Config{Chains: []configPkg.Chain{{
+		Name:        "chain",
+		LCDEndpoint: "https://example.com",
+	}}}
+
+	tracer := tracing.InitNoopTracer()
+	logger := loggerPkg.GetNopLogger()
+	querier := NewNodeStatusQuerier(config, tendermint.NewRegistry(config, *logger, tracer), *logger, tracer)
+
+	metrics, queries := querier.GetMetrics(context.Background())
+	assert.Len(t, queries, 2)
+	assert.True(t, queries[0].Success)
+	assert.True(t, queries[1].Success)
+
+	require.Len(t, metrics, 3)
+
+	labels := prometheus.Labels{"chain": "chain", "endpoint": "https://example.com"}
+
+	heightGauge, ok := metrics[0].(*prometheus.GaugeVec)
+	require.True(t, ok)
+	assert.InDelta(t, 12345, testutil.ToFloat64(heightGauge.With(labels)), 0.01)
+
+	blockAgeGauge, ok := metrics[1].(*prometheus.GaugeVec)
+	require.True(t, ok)
+	assert.Greater(t, testutil.ToFloat64(blockAgeGauge.With(labels)), float64(0))
+
+	syncingGauge, ok := metrics[2].(*prometheus.GaugeVec)
+	require.True(t, ok)
+	assert.InDelta(t, 1, testutil.ToFloat64(syncingGauge.With(labels)), 0.01)
+}
+
+//nolint:paralleltest // disabled due to httpmock usage
+func TestNodeStatusQuerierHeightDecreased(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	height := "12346"
+
+	httpmock.RegisterResponder(
+		"GET",
+		"https://example.com/cosmos/base/tendermint/v1beta1/blocks/latest",
+		func(request *http.Request) (*http.Response, error) {
+			response := httpmock.NewBytesResponse(200, assets.GetBytesOrPanic("latest-block.json"))
+			response.Header.Set(constants.HeaderBlockHeight, height)
+			return response, nil
+		},
+	)
+	httpmock.RegisterResponder(
+		"GET",
+		"https://example.com/cosmos/base/tendermint/v1beta1/syncing",
+		func(request *http.Request) (*http.Response, error) {
+			response := httpmock.NewBytesResponse(200, assets.GetBytesOrPanic("syncing.json"))
+			response.Header.Set(constants.HeaderBlockHeight, height)
+			return response, nil
+		},
+	)
+
+	config := &configPkg.Config{Chains: []configPkg.Chain{{
+		Name:        "chain",
+		LCDEndpoint: "https://example.com",
+	}}}
+
+	tracer := tracing.InitNoopTracer()
+	logger := loggerPkg.GetNopLogger()
+	querier := NewNodeStatusQuerier(config, tendermint.NewRegistry(config, *logger, tracer), *logger, tracer)
+
+	_, queries := querier.GetMetrics(context.Background())
+	require.Len(t, queries, 2)
+
+	// the node is lagging behind now, but its status should still be reported
+	height = "12340"
+
+	metrics, queries := querier.GetMetrics(context.Background())
+	require.Len(t, queries, 2)
+	assert.True(t, queries[0].Success)
+	assert.True(t, queries[1].Success)
+
+	labels := prometheus.Labels{"chain": "chain", "endpoint": "https://example.com"}
+
+	heightGauge, ok := metrics[0].(*prometheus.GaugeVec)
+	require.True(t, ok)
+	assert.InDelta(t, 12345, testutil.ToFloat64(heightGauge.With(labels)), 0.01)
+
+	syncingGauge, ok := metrics[2].(*prometheus.GaugeVec)
+	require.True(t, ok)
+	assert.InDelta(t, 1, testutil.ToFloat64(syncingGauge.With(labels)), 0.01)
+}
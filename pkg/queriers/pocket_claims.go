@@ -0,0 +1,457 @@
+package queriers
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"main/pkg/config"
+	"main/pkg/tendermint"
+	"main/pkg/types"
+	"main/pkg/utils"
+	"math/big"
+	"strconv"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+)
+
+type PocketClaimsQuerier struct {
+	Config *config.Config
+	Logger zerolog.Logger
+	RPCs   *tendermint.Registry
+	Tracer trace.Tracer
+}
+
+func NewPocketClaimsQuerier(
+	config *config.Config,
+	rpcs *tendermint.Registry,
+	logger zerolog.Logger,
+	tracer trace.Tracer,
+) *PocketClaimsQuerier {
+	return &PocketClaimsQuerier{
+		Config: config,
+		Logger: logger.With().Str("component", "pocket_claims_querier").Logger(),
+		RPCs:   rpcs,
+		Tracer: tracer,
+	}
+}
+
+type pocketClaimsStats struct {
+	Claims        int
+	Proofs        int
+	ClaimsAtRisk  int
+	ClaimsExpired int
+}
+
+// The sum root of a claim is the digest, followed by the sum of compute units
+// and the count of relays, both as big endian uint64.
+const (
+	claimRootSumSize   = 8
+	claimRootCountSize = 8
+)
+
+func (q *PocketClaimsQuerier) Name() string {
+	return "pocket_claims"
+}
+
+func (q *PocketClaimsQuerier) GetMetrics(ctx context.Context) ([]prometheus.Collector, []types.QueryInfo) {
+	childCtx, span := q.Tracer.Start(ctx, "Querying Pocket Network claims and proofs metrics")
+	defer span.End()
+
+	customLabelNames := q.Config.GetLabelNames()
+	labels := withCustomLabelNames(
+		config.ClaimLabelNames,
+		customLabelNames,
+	)
+
+	claimsGauge := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cosmos_wallets_exporter_pocket_claims",
+			Help: "A count of open claims of a Pocket Network supplier",
+		},
+		labels,
+	)
+
+	proofsGauge := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cosmos_wallets_exporter_pocket_proofs",
+			Help: "A count of proofs submitted by a Pocket Network supplier",
+		},
+		labels,
+	)
+
+	claimsAtRiskGauge := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cosmos_wallets_exporter_pocket_claims_at_risk",
+			Help: "A count of claims of a Pocket Network supplier requiring a proof, with the proof window open and no proof submitted",
+		},
+		labels,
+	)
+
+	claimsExpiredGauge := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cosmos_wallets_exporter_pocket_claims_expired",
+			Help: "A count of claims of a Pocket Network supplier requiring a proof, with the proof window closed and no proof submitted",
+		},
+		labels,
+	)
+
+	var queryInfos []types.QueryInfo
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+
+	for _, chain := range q.Config.Chains {
+		if !chain.PocketClaims {
+			continue
+		}
+
+		rpc := q.RPCs.Get(chain)
+
+		wg.Add(1)
+		go func(chain config.Chain, rpc *tendermint.RPC) {
+			defer wg.Done()
+
+			chainCtx, chainSpan := q.Tracer.Start(childCtx, "Querying chain claims and proofs")
+			chainSpan.SetAttributes(attribute.String("chain", chain.Name))
+			defer chainSpan.End()
+
+			sharedParamsResponse, queryInfo, err := rpc.GetSharedParams(chainCtx)
+
+			mutex.Lock()
+			queryInfos = append(queryInfos, queryInfo)
+			mutex.Unlock()
+
+			if err != nil {
+				q.Logger.Error().
+					Err(err).
+					Str("chain", chain.Name).
+					Msg("Error querying shared params")
+				return
+			}
+
+			proofParamsResponse, queryInfo, err := rpc.GetProofParams(chainCtx)
+
+			mutex.Lock()
+			queryInfos = append(queryInfos, queryInfo)
+			mutex.Unlock()
+
+			if err != nil {
+				q.Logger.Error().
+					Err(err).
+					Str("chain", chain.Name).
+					Msg("Error querying proof params")
+				return
+			}
+
+			var supplierWg sync.WaitGroup
+
+			for _, supplier := range chain.Suppliers {
+				supplierWg.Add(1)
+				go func(supplier config.Supplier) {
+					defer supplierWg.Done()
+
+					stats, supplierQueryInfos, err := q.getSupplierStats(
+						chainCtx,
+						chain,
+						supplier,
+						sharedParamsResponse.Params,
+						proofParamsResponse.Params,
+						rpc,
+					)
+
+					mutex.Lock()
+					defer mutex.Unlock()
+
+					queryInfos = append(queryInfos, supplierQueryInfos...)
+
+					if err != nil {
+						q.Logger.Error().
+							Err(err).
+							Str("chain", chain.Name).
+							Str("supplier", supplier.Address).
+							Msg("Error querying supplier claims and proofs")
+						return
+					}
+
+					for serviceID, value := range stats {
+						metricLabels := withCustomLabels(prometheus.Labels{
+							"chain":                     chain.Name,
+							"supplier_operator_address": supplier.Address,
+							"supplier_name":             supplier.Name,
+							"service_id":                serviceID,
+						}, customLabelNames, config.MergeLabels(chain.Labels, supplier.Labels))
+
+						claimsGauge.With(metricLabels).Set(float64(value.Claims))
+						proofsGauge.With(metricLabels).Set(float64(value.Proofs))
+						claimsAtRiskGauge.With(metricLabels).Set(float64(value.ClaimsAtRisk))
+						claimsExpiredGauge.With(metricLabels).Set(float64(value.ClaimsExpired))
+					}
+				}(supplier)
+			}
+
+			supplierWg.Wait()
+		}(chain, rpc)
+	}
+
+	wg.Wait()
+
+	return []prometheus.Collector{claimsGauge, proofsGauge, claimsAtRiskGauge, claimsExpiredGauge}, queryInfos
+}
+
+func (q *PocketClaimsQuerier) getSupplierStats(
+	ctx context.Context,
+	chain config.Chain,
+	supplier config.Supplier,
+	sharedParams types.SharedParams,
+	proofParams types.ProofParams,
+	rpc *tendermint.RPC,
+) (map[string]*pocketClaimsStats, []types.QueryInfo, error) {
+	claims, queryInfos, err := rpc.GetSupplierClaims(supplier.Address, ctx)
+	if err != nil {
+		return nil, queryInfos, err
+	}
+
+	proofs, proofsQueryInfos, err := rpc.GetSupplierProofs(supplier.Address, ctx)
+	queryInfos = append(queryInfos, proofsQueryInfos...)
+	if err != nil {
+		return nil, queryInfos, err
+	}
+
+	// the latest height we know of, used to check whether the proof window is open
+	var currentHeight int64
+	for _, queryInfo := range queryInfos {
+		if queryInfo.Height > currentHeight {
+			currentHeight = queryInfo.Height
+		}
+	}
+
+	stats := make(map[string]*pocketClaimsStats)
+	provenSessions := make(map[string]bool, len(proofs))
+
+	getStats := func(header types.SessionHeader) *pocketClaimsStats {
+		if _, ok := stats[header.ServiceID]; !ok {
+			stats[header.ServiceID] = &pocketClaimsStats{}
+		}
+
+		return stats[header.ServiceID]
+	}
+
+	for _, proof := range proofs {
+		provenSessions[proof.SessionHeader.SessionID] = true
+		getStats(proof.SessionHeader).Proofs++
+	}
+
+	// relay mining difficulty multipliers per service, queried only for services
+	// of claims that need a proof requirement check
+	difficulties := make(map[string]float64)
+
+	getDifficulty := func(serviceID string) float64 {
+		if difficulty, ok := difficulties[serviceID]; ok {
+			return difficulty
+		}
+
+		difficulty := float64(1)
+
+		response, queryInfo, err := rpc.GetRelayMiningDifficulty(serviceID, ctx)
+		queryInfos = append(queryInfos, queryInfo)
+		if err != nil {
+			q.Logger.Warn().
+				Err(err).
+				Str("chain", chain.Name).
+				Str("service_id", serviceID).
+				Msg("Could not query relay mining difficulty, assuming the base one")
+		} else if multiplier := utils.GetRelayDifficultyMultiplier(response.RelayMiningDifficulty.TargetHash); multiplier > 0 {
+			difficulty = multiplier
+		}
+
+		difficulties[serviceID] = difficulty
+		return difficulty
+	}
+
+	for _, claim := range claims {
+		claimStats := getStats(claim.SessionHeader)
+		claimStats.Claims++
+
+		if provenSessions[claim.SessionHeader.SessionID] {
+			continue
+		}
+
+		proofWindowOpenHeight, err := GetProofWindowOpenHeight(sharedParams, claim.SessionHeader.SessionEndBlockHeight)
+		if err != nil {
+			q.Logger.Warn().
+				Err(err).
+				Str("chain", chain.Name).
+				Str("supplier", supplier.Address).
+				Str("session_id", claim.SessionHeader.SessionID).
+				Msg("Could not calculate proof window open height")
+			continue
+		}
+
+		if currentHeight < proofWindowOpenHeight {
+			continue
+		}
+
+		proofRequired, err := IsProofRequired(
+			claim,
+			sharedParams,
+			proofParams,
+			getDifficulty,
+		)
+		if err != nil {
+			// better to alert on a healthy claim than to miss a missing proof
+			q.Logger.Warn().
+				Err(err).
+				Str("chain", chain.Name).
+				Str("supplier", supplier.Address).
+				Str("session_id", claim.SessionHeader.SessionID).
+				Msg("Could not check whether a claim requires a proof, assuming it does")
+			proofRequired = true
+		}
+
+		if !proofRequired {
+			continue
+		}
+
+		proofWindowCloseHeight, err := GetProofWindowCloseHeight(sharedParams, proofWindowOpenHeight)
+		if err != nil {
+			q.Logger.Warn().
+				Err(err).
+				Str("chain", chain.Name).
+				Str("supplier", supplier.Address).
+				Str("session_id", claim.SessionHeader.SessionID).
+				Msg("Could not calculate proof window close height")
+			continue
+		}
+
+		if currentHeight < proofWindowCloseHeight {
+			claimStats.ClaimsAtRisk++
+		} else {
+			claimStats.ClaimsExpired++
+		}
+	}
+
+	return stats, queryInfos, nil
+}
+
+// IsProofRequired returns whether a claim requires a proof for sure, which is when
+// the claimed amount reaches the proof requirement threshold. Claims below it are
+// only required to be proven if randomly selected, which depends on the block hash
+// at the proof window open height and is not checked here, so they are not counted.
+func IsProofRequired(
+	claim types.Claim,
+	sharedParams types.SharedParams,
+	proofParams types.ProofParams,
+	getDifficulty func(serviceID string) float64,
+) (bool, error) {
+	if proofParams.ProofRequestProbability >= 1 || proofParams.ProofRequirementThreshold == nil {
+		return true, nil
+	}
+
+	threshold, ok := new(big.Float).SetString(proofParams.ProofRequirementThreshold.Amount)
+	if !ok {
+		return false, fmt.Errorf("invalid proof requirement threshold: %s", proofParams.ProofRequirementThreshold.Amount)
+	}
+
+	claimed, err := GetClaimedAmount(claim, sharedParams, getDifficulty(claim.SessionHeader.ServiceID))
+	if err != nil {
+		return false, err
+	}
+
+	return claimed.Cmp(threshold) >= 0, nil
+}
+
+// GetClaimedAmount calculates the amount of tokens a claim is for, same way poktroll
+// does it: compute units from the claim root, scaled by the relay mining difficulty
+// multiplier, multiplied by the compute units to tokens multiplier and divided
+// by the compute unit cost granularity.
+func GetClaimedAmount(claim types.Claim, sharedParams types.SharedParams, difficulty float64) (*big.Float, error) {
+	computeUnits, err := GetClaimComputeUnits(claim.RootHash)
+	if err != nil {
+		return nil, err
+	}
+
+	multiplier, ok := new(big.Float).SetString(sharedParams.ComputeUnitsToTokensMultiplier)
+	if !ok {
+		return nil, fmt.Errorf("invalid compute units to tokens multiplier: %s", sharedParams.ComputeUnitsToTokensMultiplier)
+	}
+
+	claimed := new(big.Float).SetUint64(computeUnits)
+	claimed.Mul(claimed, big.NewFloat(difficulty))
+	claimed.Mul(claimed, multiplier)
+
+	// missing in older versions of poktroll, where there is no granularity
+	if sharedParams.ComputeUnitCostGranularity != "" {
+		granularity, ok := new(big.Float).SetString(sharedParams.ComputeUnitCostGranularity)
+		if !ok || granularity.Sign() <= 0 {
+			return nil, fmt.Errorf("invalid compute unit cost granularity: %s", sharedParams.ComputeUnitCostGranularity)
+		}
+
+		claimed.Quo(claimed, granularity)
+	}
+
+	return claimed, nil
+}
+
+// GetClaimComputeUnits returns the sum of compute units stored in a claim root.
+func GetClaimComputeUnits(rootHash []byte) (uint64, error) {
+	if len(rootHash) < claimRootSumSize+claimRootCountSize {
+		return 0, fmt.Errorf("claim root is too short: %d bytes", len(rootHash))
+	}
+
+	sumStart := len(rootHash) - claimRootSumSize - claimRootCountSize
+	return binary.BigEndian.Uint64(rootHash[sumStart : sumStart+claimRootSumSize]), nil
+}
+
+// GetProofWindowOpenHeight calculates the height at which a supplier can start
+// submitting a proof for a session, same way poktroll does it: the claim window
+// opens the block after the session end plus its offset, which already covers
+// the grace period, then the claim window, then the proof window offset.
+func GetProofWindowOpenHeight(sharedParams types.SharedParams, sessionEndHeight string) (int64, error) {
+	sessionEnd, err := strconv.ParseInt(sessionEndHeight, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	height := sessionEnd + 1
+
+	for _, offsetStr := range []string{
+		sharedParams.ClaimWindowOpenOffsetBlocks,
+		sharedParams.ClaimWindowCloseOffsetBlocks,
+		sharedParams.ProofWindowOpenOffsetBlocks,
+	} {
+		// params missing in older versions of poktroll are treated as zero offsets
+		if offsetStr == "" {
+			continue
+		}
+
+		offset, err := strconv.ParseInt(offsetStr, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+
+		height += offset
+	}
+
+	return height, nil
+}
+
+// GetProofWindowCloseHeight calculates the height after which a proof for a session
+// cannot be submitted anymore, given the height its proof window opens at.
+func GetProofWindowCloseHeight(sharedParams types.SharedParams, proofWindowOpenHeight int64) (int64, error) {
+	// missing in older versions of poktroll, treated as a zero offset
+	if sharedParams.ProofWindowCloseOffsetBlocks == "" {
+		return proofWindowOpenHeight, nil
+	}
+
+	offset, err := strconv.ParseInt(sharedParams.ProofWindowCloseOffsetBlocks, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	return proofWindowOpenHeight + offset, nil
+}
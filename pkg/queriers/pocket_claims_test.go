@@ -0,0 +1,249 @@
+package queriers
+
+import (
+	"context"
+	"errors"
+	"main/assets"
+	configPkg "main/pkg/config"
+	"main/pkg/constants"
+	loggerPkg "main/pkg/logger"
+	"main/pkg/tendermint"
+	"main/pkg/tracing"
+	"main/pkg/types"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetProofWindowOpenHeight(t *testing.T) {
+	t.Parallel()
+
+	// the grace period is covered by the claim window open offset, so it is not added
+	params := types.SharedParams{
+		GracePeriodEndOffsetBlocks:   "1",
+		ClaimWindowOpenOffsetBlocks:  "1",
+		ClaimWindowCloseOffsetBlocks: "4",
+		ProofWindowOpenOffsetBlocks:  "0",
+	}
+
+	height, err := GetProofWindowOpenHeight(params, "50")
+	require.NoError(t, err)
+	assert.Equal(t, int64(56), height)
+
+	_, err = GetProofWindowOpenHeight(params, "invalid")
+	require.Error(t, err)
+
+	_, err = GetProofWindowOpenHeight(types.SharedParams{ClaimWindowOpenOffsetBlocks: "invalid"}, "50")
+	require.Error(t, err)
+}
+
+func TestGetProofWindowCloseHeight(t *testing.T) {
+	t.Parallel()
+
+	height, err := GetProofWindowCloseHeight(types.SharedParams{ProofWindowCloseOffsetBlocks: "4"}, 56)
+	require.NoError(t, err)
+	assert.Equal(t, int64(60), height)
+
+	height, err = GetProofWindowCloseHeight(types.SharedParams{}, 56)
+	require.NoError(t, err)
+	assert.Equal(t, int64(56), height)
+
+	_, err = GetProofWindowCloseHeight(types.SharedParams{ProofWindowCloseOffsetBlocks: "invalid"}, 56)
+	require.Error(t, err)
+}
+
+func TestGetClaimComputeUnits(t *testing.T) {
+	t.Parallel()
+
+	root := make([]byte, 48)
+	root[39] = 100
+	root[47] = 5
+
+	computeUnits, err := GetClaimComputeUnits(root)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(100), computeUnits)
+
+	_, err = GetClaimComputeUnits([]byte{1, 2, 3})
+	require.Error(t, err)
+}
+
+func TestIsProofRequired(t *testing.T) {
+	t.Parallel()
+
+	root := make([]byte, 48)
+	root[39] = 100
+
+	claim := types.Claim{RootHash: root}
+	sharedParams := types.SharedParams{
+		ComputeUnitsToTokensMultiplier: "42",
+		ComputeUnitCostGranularity:     "1",
+	}
+	getDifficulty := func(serviceID string) float64 { return 2 }
+
+	// 100 compute units * 2 difficulty * 42 = 8400 tokens
+	required, err := IsProofRequired(claim, sharedParams, types.ProofParams{
+		ProofRequestProbability:   0.25,
+		ProofRequirementThreshold: &types.Coin{Amount: "8400"},
+	}, getDifficulty)
+	require.NoError(t, err)
+	assert.True(t, required)
+
+	required, err = IsProofRequired(claim, sharedParams, types.ProofParams{
+		ProofRequestProbability:   0.25,
+		ProofRequirementThreshold: &types.Coin{Amount: "8401"},
+	}, getDifficulty)
+	require.NoError(t, err)
+	assert.False(t, required)
+
+	required, err = IsProofRequired(claim, sharedParams, types.ProofParams{
+		ProofRequestProbability:   1,
+		ProofRequirementThreshold: &types.Coin{Amount: "8401"},
+	}, getDifficulty)
+	require.NoError(t, err)
+	assert.True(t, required)
+
+	required, err = IsProofRequired(claim, sharedParams, types.ProofParams{}, getDifficulty)
+	require.NoError(t, err)
+	assert.True(t, required)
+
+	_, err = IsProofRequired(claim, sharedParams, types.ProofParams{
+		ProofRequirementThreshold: &types.Coin{Amount: "invalid"},
+	}, getDifficulty)
+	require.Error(t, err)
+
+	_, err = IsProofRequired(types.Claim{}, sharedParams, types.ProofParams{
+		ProofRequirementThreshold: &types.Coin{Amount: "8400"},
+	}, getDifficulty)
+	require.Error(t, err)
+
+	_, err = IsProofRequired(claim, types.SharedParams{ComputeUnitsToTokensMultiplier: "invalid"}, types.ProofParams{
+		ProofRequirementThreshold: &types.Coin{Amount: "8400"},
+	}, getDifficulty)
+	require.Error(t, err)
+
+	_, err = IsProofRequired(claim, types.SharedParams{
+		ComputeUnitsToTokensMultiplier: "42",
+		ComputeUnitCostGranularity:     "0",
+	}, types.ProofParams{
+		ProofRequirementThreshold: &types.Coin{Amount: "8400"},
+	}, getDifficulty)
+	require.Error(t, err)
+}
+
+//nolint:paralleltest // disabled due to httpmock usage
+func TestPocketClaimsQuerierFail(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder(
+		"GET",
+		"https://example.com/pokt-network/poktroll/shared/params",
+		httpmock.NewErrorResponder(errors.New("custom error")),
+	)
+
+	config := &configPkg.Config{Chains: []configPkg.Chain{{
+		Name:         "chain",
+		LCDEndpoint:  "https://example.com",
+		Suppliers:    []configPkg.Supplier{{Address: "supplier"}},
+		PocketClaims: true,
+	}}}
+
+	tracer := tracing.InitNoopTracer()
+	logger := loggerPkg.GetNopLogger()
+	querier := NewPocketClaimsQuerier(config, tendermint.NewRegistry(config, *logger, tracer), *logger, tracer)
+
+	metrics, queries := querier.GetMetrics(context.Background())
+	assert.Len(t, queries, 1)
+	assert.False(t, queries[0].Success)
+
+	assert.Len(t, metrics, 4)
+	for _, metric := range metrics {
+		assert.Zero(t, testutil.CollectAndCount(metric))
+	}
+}
+
+//nolint:paralleltest // disabled due to httpmock usage
+func TestPocketClaimsQuerierOk(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	header := http.Header{constants.HeaderBlockHeight: []string{"58"}}
+
+	httpmock.RegisterResponder(
+		"GET",
+		"https://example.com/pokt-network/poktroll/shared/params",
+		httpmock.NewBytesResponder(200, assets.GetBytesOrPanic("pocket-shared-params.json")).HeaderAdd(header),
+	)
+	httpmock.RegisterResponder(
+		"GET",
+		"https://example.com/pokt-network/poktroll/proof/params",
+		httpmock.NewBytesResponder(200, assets.GetBytesOrPanic("pocket-proof-params.json")).HeaderAdd(header),
+	)
+	httpmock.RegisterResponder(
+		"GET",
+		"https://example.com/pokt-network/poktroll/service/relay_mining_difficulty/anvil",
+		httpmock.NewBytesResponder(200, assets.GetBytesOrPanic("pocket-relay-mining-difficulty.json")).HeaderAdd(header),
+	)
+	httpmock.RegisterResponder(
+		"GET",
+		"https://example.com/pokt-network/poktroll/proof/claim?supplier_operator_address=supplier",
+		httpmock.NewBytesResponder(200, assets.GetBytesOrPanic("pocket-claims.json")).HeaderAdd(header),
+	)
+	httpmock.RegisterResponder(
+		"GET",
+		"https://example.com/pokt-network/poktroll/proof/proof?supplier_operator_address=supplier",
+		httpmock.NewBytesResponder(200, assets.GetBytesOrPanic("pocket-proofs.json")).HeaderAdd(header),
+	)
+
+	config := &configPkg.Config{Chains: []configPkg.Chain{{
+		Name:         "chain",
+		LCDEndpoint:  "https://example.com",
+		Suppliers:    []configPkg.Supplier{{Address: "supplier", Name: "name"}},
+		PocketClaims: true,
+	}}}
+
+	tracer := tracing.InitNoopTracer()
+	logger := loggerPkg.GetNopLogger()
+	querier := NewPocketClaimsQuerier(config, tendermint.NewRegistry(config, *logger, tracer), *logger, tracer)
+
+	metrics, queries := querier.GetMetrics(context.Background())
+	// shared params, proof params, claims, proofs and relay mining difficulty
+	assert.Len(t, queries, 5)
+	for _, query := range queries {
+		assert.True(t, query.Success)
+	}
+
+	require.Len(t, metrics, 4)
+
+	claimsGauge, ok := metrics[0].(*prometheus.GaugeVec)
+	require.True(t, ok)
+	proofsGauge, ok := metrics[1].(*prometheus.GaugeVec)
+	require.True(t, ok)
+	atRiskGauge, ok := metrics[2].(*prometheus.GaugeVec)
+	require.True(t, ok)
+	expiredGauge, ok := metrics[3].(*prometheus.GaugeVec)
+	require.True(t, ok)
+
+	// claims of all sessions of a service are summed up
+	assert.Equal(t, 1, testutil.CollectAndCount(claimsGauge))
+
+	labels := prometheus.Labels{
+		"chain":                     "chain",
+		"supplier_operator_address": "supplier",
+		"supplier_name":             "name",
+		"service_id":                "anvil",
+	}
+
+	// the session ending at 50 has one proven claim, one requiring a proof and one
+	// below the proof requirement threshold, the one ending at 40 has its proof window
+	// already closed, and the one ending at 60 has its proof window not open yet
+	assert.InDelta(t, 5, testutil.ToFloat64(claimsGauge.With(labels)), 0.01)
+	assert.InDelta(t, 1, testutil.ToFloat64(proofsGauge.With(labels)), 0.01)
+	assert.InDelta(t, 1, testutil.ToFloat64(atRiskGauge.With(labels)), 0.01)
+	assert.InDelta(t, 1, testutil.ToFloat64(expiredGauge.With(labels)), 0.01)
+}
@@ -0,0 +1,406 @@
+package queriers
+
+import (
+	"context"
+	"main/pkg/config"
+	"main/pkg/tendermint"
+	"main/pkg/types"
+	"main/pkg/utils"
+	"math"
+	"strconv"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+)
+
+type PocketParamsQuerier struct {
+	Config *config.Config
+	Logger zerolog.Logger
+	RPCs   *tendermint.Registry
+	Tracer trace.Tracer
+}
+
+func NewPocketParamsQuerier(
+	config *config.Config,
+	rpcs *tendermint.Registry,
+	logger zerolog.Logger,
+	tracer trace.Tracer,
+) *PocketParamsQuerier {
+	return &PocketParamsQuerier{
+		Config: config,
+		Logger: logger.With().Str("component", "pocket_params_querier").Logger(),
+		RPCs:   rpcs,
+		Tracer: tracer,
+	}
+}
+
+type pocketParamsGauges struct {
+	shared                  *prometheus.GaugeVec
+	session                 *prometheus.GaugeVec
+	tokenomics              *prometheus.GaugeVec
+	proofRequestProbability *prometheus.GaugeVec
+	proofThreshold          *prometheus.GaugeVec
+	proofMissingPenalty     *prometheus.GaugeVec
+	proofSubmissionFee      *prometheus.GaugeVec
+	relayDifficulty         *prometheus.GaugeVec
+	relayNumRelaysEma       *prometheus.GaugeVec
+}
+
+type pocketParamsQuery func(
+	ctx context.Context,
+	chain config.Chain,
+	rpc *tendermint.RPC,
+	gauges pocketParamsGauges,
+) (types.QueryInfo, error)
+
+func (q *PocketParamsQuerier) Name() string {
+	return "pocket_params"
+}
+
+func (q *PocketParamsQuerier) GetMetrics(ctx context.Context) ([]prometheus.Collector, []types.QueryInfo) {
+	childCtx, span := q.Tracer.Start(ctx, "Querying Pocket Network params metrics")
+	defer span.End()
+
+	gauges := pocketParamsGauges{
+		shared: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "cosmos_wallets_exporter_pocket_shared_param",
+				Help: "A Pocket Network shared module param (session length, claim and proof windows, compute units to tokens multiplier, etc.)",
+			},
+			config.ChainParamLabelNames,
+		),
+		session: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "cosmos_wallets_exporter_pocket_session_param",
+				Help: "A Pocket Network session module param",
+			},
+			config.ChainParamLabelNames,
+		),
+		tokenomics: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "cosmos_wallets_exporter_pocket_tokenomics_param",
+				Help: "A Pocket Network tokenomics module param (inflation and mint allocation percentages)",
+			},
+			config.ChainParamLabelNames,
+		),
+		proofRequestProbability: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "cosmos_wallets_exporter_pocket_proof_request_probability",
+				Help: "A probability of a proof being required for a claim below the proof requirement threshold",
+			},
+			config.ChainLabelNames,
+		),
+		proofThreshold: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "cosmos_wallets_exporter_pocket_proof_requirement_threshold",
+				Help: "A claim amount above which a proof is always required (in tokens)",
+			},
+			config.ChainDenomLabelNames,
+		),
+		proofMissingPenalty: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "cosmos_wallets_exporter_pocket_proof_missing_penalty",
+				Help: "A penalty slashed from a supplier for a missing required proof (in tokens)",
+			},
+			config.ChainDenomLabelNames,
+		),
+		proofSubmissionFee: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "cosmos_wallets_exporter_pocket_proof_submission_fee",
+				Help: "A fee paid by a supplier for submitting a proof (in tokens)",
+			},
+			config.ChainDenomLabelNames,
+		),
+		relayDifficulty: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "cosmos_wallets_exporter_pocket_relay_mining_difficulty",
+				Help: "A relay mining difficulty multiplier of a service compared to the base difficulty",
+			},
+			config.ChainServiceLabelNames,
+		),
+		relayNumRelaysEma: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "cosmos_wallets_exporter_pocket_relay_mining_num_relays_ema",
+				Help: "An exponential moving average of relays count per session for a service",
+			},
+			config.ChainServiceLabelNames,
+		),
+	}
+
+	var queryInfos []types.QueryInfo
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+
+	for _, chain := range q.Config.Chains {
+		if !chain.PocketParams {
+			continue
+		}
+
+		rpc := q.RPCs.Get(chain)
+
+		queries := []pocketParamsQuery{
+			q.processSharedParams,
+			q.processSessionParams,
+			q.processProofParams,
+			q.processTokenomicsParams,
+		}
+
+		for _, service := range chain.PocketServices {
+			queries = append(queries, q.processRelayMiningDifficulty(service))
+		}
+
+		for _, query := range queries {
+			wg.Add(1)
+			go func(
+				query pocketParamsQuery,
+				chain config.Chain,
+				rpc *tendermint.RPC,
+			) {
+				chainCtx, chainSpan := q.Tracer.Start(childCtx, "Querying chain Pocket Network params")
+				chainSpan.SetAttributes(attribute.String("chain", chain.Name))
+				defer chainSpan.End()
+
+				defer wg.Done()
+
+				queryInfo, err := query(chainCtx, chain, rpc, gauges)
+
+				mutex.Lock()
+				defer mutex.Unlock()
+
+				queryInfos = append(queryInfos, queryInfo)
+
+				if err != nil {
+					q.Logger.Error().
+						Err(err).
+						Str("chain", chain.Name).
+						Str("url", queryInfo.URL).
+						Msg("Error querying Pocket Network params")
+				}
+			}(query, chain, rpc)
+		}
+	}
+
+	wg.Wait()
+
+	return []prometheus.Collector{
+		gauges.shared,
+		gauges.session,
+		gauges.tokenomics,
+		gauges.proofRequestProbability,
+		gauges.proofThreshold,
+		gauges.proofMissingPenalty,
+		gauges.proofSubmissionFee,
+		gauges.relayDifficulty,
+		gauges.relayNumRelaysEma,
+	}, queryInfos
+}
+
+func (q *PocketParamsQuerier) processSharedParams(
+	ctx context.Context,
+	chain config.Chain,
+	rpc *tendermint.RPC,
+	gauges pocketParamsGauges,
+) (types.QueryInfo, error) {
+	response, queryInfo, err := rpc.GetSharedParams(ctx)
+	if err != nil {
+		return queryInfo, err
+	}
+
+	params := response.Params
+
+	q.setParamsFromStrings(chain, gauges.shared, map[string]string{
+		"num_blocks_per_session":                params.NumBlocksPerSession,
+		"grace_period_end_offset_blocks":        params.GracePeriodEndOffsetBlocks,
+		"claim_window_open_offset_blocks":       params.ClaimWindowOpenOffsetBlocks,
+		"claim_window_close_offset_blocks":      params.ClaimWindowCloseOffsetBlocks,
+		"proof_window_open_offset_blocks":       params.ProofWindowOpenOffsetBlocks,
+		"proof_window_close_offset_blocks":      params.ProofWindowCloseOffsetBlocks,
+		"supplier_unbonding_period_sessions":    params.SupplierUnbondingPeriodSessions,
+		"application_unbonding_period_sessions": params.ApplicationUnbondingPeriodSessions,
+		"gateway_unbonding_period_sessions":     params.GatewayUnbondingPeriodSessions,
+		"compute_units_to_tokens_multiplier":    params.ComputeUnitsToTokensMultiplier,
+	})
+
+	return queryInfo, nil
+}
+
+func (q *PocketParamsQuerier) processSessionParams(
+	ctx context.Context,
+	chain config.Chain,
+	rpc *tendermint.RPC,
+	gauges pocketParamsGauges,
+) (types.QueryInfo, error) {
+	response, queryInfo, err := rpc.GetSessionParams(ctx)
+	if err != nil {
+		return queryInfo, err
+	}
+
+	q.setParamsFromStrings(chain, gauges.session, map[string]string{
+		"num_suppliers_per_session": response.Params.NumSuppliersPerSession,
+	})
+
+	return queryInfo, nil
+}
+
+func (q *PocketParamsQuerier) processProofParams(
+	ctx context.Context,
+	chain config.Chain,
+	rpc *tendermint.RPC,
+	gauges pocketParamsGauges,
+) (types.QueryInfo, error) {
+	response, queryInfo, err := rpc.GetProofParams(ctx)
+	if err != nil {
+		return queryInfo, err
+	}
+
+	params := response.Params
+
+	gauges.proofRequestProbability.With(prometheus.Labels{
+		"chain": chain.Name,
+	}).Set(params.ProofRequestProbability)
+
+	q.setCoin(chain, gauges.proofThreshold, params.ProofRequirementThreshold)
+	q.setCoin(chain, gauges.proofMissingPenalty, params.ProofMissingPenalty)
+	q.setCoin(chain, gauges.proofSubmissionFee, params.ProofSubmissionFee)
+
+	return queryInfo, nil
+}
+
+func (q *PocketParamsQuerier) processTokenomicsParams(
+	ctx context.Context,
+	chain config.Chain,
+	rpc *tendermint.RPC,
+	gauges pocketParamsGauges,
+) (types.QueryInfo, error) {
+	response, queryInfo, err := rpc.GetTokenomicsParams(ctx)
+	if err != nil {
+		return queryInfo, err
+	}
+
+	params := response.Params
+	allocations := params.MintAllocationPercentages
+
+	for param, value := range map[string]float64{
+		"global_inflation_per_claim":              params.GlobalInflationPerClaim,
+		"mint_allocation_percentage_dao":          allocations.Dao,
+		"mint_allocation_percentage_proposer":     allocations.Proposer,
+		"mint_allocation_percentage_supplier":     allocations.Supplier,
+		"mint_allocation_percentage_source_owner": allocations.SourceOwner,
+		"mint_allocation_percentage_application":  allocations.Application,
+	} {
+		gauges.tokenomics.With(prometheus.Labels{
+			"chain": chain.Name,
+			"param": param,
+		}).Set(value)
+	}
+
+	return queryInfo, nil
+}
+
+func (q *PocketParamsQuerier) processRelayMiningDifficulty(
+	serviceID string,
+) pocketParamsQuery {
+	return func(
+		ctx context.Context,
+		chain config.Chain,
+		rpc *tendermint.RPC,
+		gauges pocketParamsGauges,
+	) (types.QueryInfo, error) {
+		response, queryInfo, err := rpc.GetRelayMiningDifficulty(serviceID, ctx)
+		if err != nil {
+			return queryInfo, err
+		}
+
+		difficulty := response.RelayMiningDifficulty
+
+		gauges.relayDifficulty.With(prometheus.Labels{
+			"chain":      chain.Name,
+			"service_id": serviceID,
+		}).Set(utils.GetRelayDifficultyMultiplier(difficulty.TargetHash))
+
+		numRelaysEma, err := strconv.ParseFloat(difficulty.NumRelaysEma, 64)
+		if err != nil {
+			q.Logger.Error().
+				Err(err).
+				Str("chain", chain.Name).
+				Str("service_id", serviceID).
+				Str("value", difficulty.NumRelaysEma).
+				Msg("Error parsing relays EMA")
+			return queryInfo, nil
+		}
+
+		gauges.relayNumRelaysEma.With(prometheus.Labels{
+			"chain":      chain.Name,
+			"service_id": serviceID,
+		}).Set(numRelaysEma)
+
+		return queryInfo, nil
+	}
+}
+
+func (q *PocketParamsQuerier) setParamsFromStrings(
+	chain config.Chain,
+	gauge *prometheus.GaugeVec,
+	params map[string]string,
+) {
+	for param, valueStr := range params {
+		// params missing in older versions of poktroll are skipped
+		if valueStr == "" {
+			continue
+		}
+
+		value, err := strconv.ParseFloat(valueStr, 64)
+		if err != nil {
+			q.Logger.Error().
+				Err(err).
+				Str("chain", chain.Name).
+				Str("param", param).
+				Str("value", valueStr).
+				Msg("Error parsing param")
+			continue
+		}
+
+		gauge.With(prometheus.Labels{
+			"chain": chain.Name,
+			"param": param,
+		}).Set(value)
+	}
+}
+
+func (q *PocketParamsQuerier) setCoin(
+	chain config.Chain,
+	gauge *prometheus.GaugeVec,
+	coin *types.Coin,
+) {
+	if coin == nil {
+		return
+	}
+
+	amount, err := strconv.ParseFloat(coin.Amount, 64)
+	if err != nil {
+		q.Logger.Error().
+			Err(err).
+			Str("chain", chain.Name).
+			Str("amount", coin.Amount).
+			Msg("Error parsing coin amount")
+		return
+	}
+
+	denom := coin.Denom
+
+	denomInfo, found := chain.FindDenomByName(coin.Denom)
+	if found {
+		denom = denomInfo.GetName()
+		amount /= math.Pow10(denomInfo.DenomExponent)
+	}
+
+	gauge.With(prometheus.Labels{
+		"chain": chain.Name,
+		"denom": denom,
+	}).Set(amount)
+}
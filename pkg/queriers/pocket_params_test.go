@@ -0,0 +1,176 @@
+package queriers
+
+import (
+	"context"
+	"errors"
+	"main/assets"
+	configPkg "main/pkg/config"
+	loggerPkg "main/pkg/logger"
+	"main/pkg/tendermint"
+	"main/pkg/tracing"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:paralleltest // disabled due to httpmock usage
+func TestPocketParamsQuerierDisabled(t *testing.T) {
+	config := &configPkg.Config{Chains: []configPkg.Chain{{
+		Name:        "chain",
+		LCDEndpoint: "https://example.com",
+		Wallets:     []configPkg.Wallet{{Address: "address"}},
+	}}}
+
+	tracer := tracing.InitNoopTracer()
+	logger := loggerPkg.GetNopLogger()
+	querier := NewPocketParamsQuerier(config, tendermint.NewRegistry(config, *logger, tracer), *logger, tracer)
+
+	metrics, queries := querier.GetMetrics(context.Background())
+	assert.Empty(t, queries)
+	assert.Len(t, metrics, 9)
+
+	for _, metric := range metrics {
+		assert.Zero(t, testutil.CollectAndCount(metric))
+	}
+}
+
+//nolint:paralleltest // disabled due to httpmock usage
+func TestPocketParamsQuerierFail(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	for _, path := range []string{
+		"shared/params",
+		"session/params",
+		"proof/params",
+		"tokenomics/params",
+		"service/relay_mining_difficulty/anvil",
+	} {
+		httpmock.RegisterResponder(
+			"GET",
+			"https://example.com/pokt-network/poktroll/"+path,
+			httpmock.NewErrorResponder(errors.New("custom error")),
+		)
+	}
+
+	config := &configPkg.Config{Chains: []configPkg.Chain{{
+		Name:           "chain",
+		LCDEndpoint:    "https://example.com",
+		PocketParams:   true,
+		PocketServices: []string{"anvil"},
+	}}}
+
+	tracer := tracing.InitNoopTracer()
+	logger := loggerPkg.GetNopLogger()
+	querier := NewPocketParamsQuerier(config, tendermint.NewRegistry(config, *logger, tracer), *logger, tracer)
+
+	metrics, queries := querier.GetMetrics(context.Background())
+	assert.Len(t, queries, 5)
+
+	for _, query := range queries {
+		assert.False(t, query.Success)
+	}
+
+	for _, metric := range metrics {
+		assert.Zero(t, testutil.CollectAndCount(metric))
+	}
+}
+
+//nolint:paralleltest // disabled due to httpmock usage
+func TestPocketParamsQuerierOk(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	for path, file := range map[string]string{
+		"shared/params":                         "pocket-shared-params.json",
+		"session/params":                        "pocket-session-params.json",
+		"proof/params":                          "pocket-proof-params.json",
+		"tokenomics/params":                     "pocket-tokenomics-params.json",
+		"service/relay_mining_difficulty/anvil": "pocket-relay-mining-difficulty.json",
+	} {
+		httpmock.RegisterResponder(
+			"GET",
+			"https://example.com/pokt-network/poktroll/"+path,
+			httpmock.NewBytesResponder(200, assets.GetBytesOrPanic(file)),
+		)
+	}
+
+	config := &configPkg.Config{Chains: []configPkg.Chain{{
+		Name:           "chain",
+		LCDEndpoint:    "https://example.com",
+		PocketParams:   true,
+		PocketServices: []string{"anvil"},
+		Denoms:         []configPkg.DenomInfo{{Denom: "upokt", DisplayDenom: "pokt", DenomExponent: 6}},
+	}}}
+
+	tracer := tracing.InitNoopTracer()
+	logger := loggerPkg.GetNopLogger()
+	querier := NewPocketParamsQuerier(config, tendermint.NewRegistry(config, *logger, tracer), *logger, tracer)
+
+	metrics, queries := querier.GetMetrics(context.Background())
+	assert.Len(t, queries, 5)
+
+	for _, query := range queries {
+		assert.True(t, query.Success)
+	}
+
+	require.Len(t, metrics, 9)
+
+	sharedGauge, ok := metrics[0].(*prometheus.GaugeVec)
+	require.True(t, ok)
+	assert.Equal(t, 10, testutil.CollectAndCount(sharedGauge))
+	assert.InDelta(t, 60, testutil.ToFloat64(sharedGauge.With(prometheus.Labels{
+		"chain": "chain",
+		"param": "num_blocks_per_session",
+	})), 0.01)
+	assert.InDelta(t, 42, testutil.ToFloat64(sharedGauge.With(prometheus.Labels{
+		"chain": "chain",
+		"param": "compute_units_to_tokens_multiplier",
+	})), 0.01)
+
+	sessionGauge, ok := metrics[1].(*prometheus.GaugeVec)
+	require.True(t, ok)
+	assert.InDelta(t, 15, testutil.ToFloat64(sessionGauge.With(prometheus.Labels{
+		"chain": "chain",
+		"param": "num_suppliers_per_session",
+	})), 0.01)
+
+	tokenomicsGauge, ok := metrics[2].(*prometheus.GaugeVec)
+	require.True(t, ok)
+	assert.Equal(t, 6, testutil.CollectAndCount(tokenomicsGauge))
+	assert.InDelta(t, 0.7, testutil.ToFloat64(tokenomicsGauge.With(prometheus.Labels{
+		"chain": "chain",
+		"param": "mint_allocation_percentage_supplier",
+	})), 0.01)
+
+	probabilityGauge, ok := metrics[3].(*prometheus.GaugeVec)
+	require.True(t, ok)
+	assert.InDelta(t, 0.25, testutil.ToFloat64(probabilityGauge.With(prometheus.Labels{
+		"chain": "chain",
+	})), 0.01)
+
+	thresholdGauge, ok := metrics[4].(*prometheus.GaugeVec)
+	require.True(t, ok)
+	assert.InDelta(t, 20, testutil.ToFloat64(thresholdGauge.With(prometheus.Labels{
+		"chain": "chain",
+		"denom": "pokt",
+	})), 0.01)
+
+	difficultyGauge, ok := metrics[7].(*prometheus.GaugeVec)
+	require.True(t, ok)
+	assert.InDelta(t, 2, testutil.ToFloat64(difficultyGauge.With(prometheus.Labels{
+		"chain":      "chain",
+		"service_id": "anvil",
+	})), 0.01)
+
+	emaGauge, ok := metrics[8].(*prometheus.GaugeVec)
+	require.True(t, ok)
+	assert.InDelta(t, 1500, testutil.ToFloat64(emaGauge.With(prometheus.Labels{
+		"chain":      "chain",
+		"service_id": "anvil",
+	})), 0.01)
+}
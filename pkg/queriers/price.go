@@ -31,6 +31,10 @@ func NewPriceQuerier(
 	}
 }
 
+func (q *PriceQuerier) Name() string {
+	return "price"
+}
+
 func (q *PriceQuerier) GetMetrics(ctx context.Context) ([]prometheus.Collector, []types.QueryInfo) {
 	childCtx, span := q.Tracer.Start(ctx, "Querying prices")
 	defer span.End()
@@ -40,7 +44,7 @@ func (q *PriceQuerier) GetMetrics(ctx context.Context) ([]prometheus.Collector,
 			Name: "cosmos_wallets_exporter_price",
 			Help: "A price of 1 token",
 		},
-		[]string{"chain", "denom"},
+		config.ChainDenomLabelNames,
 	)
 
 	currenciesList := q.Config.GetCoingeckoCurrencies()
@@ -25,7 +25,7 @@ func (q *QueriesQuerier) GetMetrics() ([]prometheus.Collector, []types.QueryInfo
 			Name: "cosmos_wallets_exporter_success",
 			Help: "Whether a scrape was successful",
 		},
-		[]string{"chain"},
+		config.ChainLabelNames,
 	)
 
 	errorGauge := prometheus.NewGaugeVec(
@@ -33,7 +33,15 @@ func (q *QueriesQuerier) GetMetrics() ([]prometheus.Collector, []types.QueryInfo
 			Name: "cosmos_wallets_exporter_error",
 			Help: "Whether a scrape has errors",
 		},
-		[]string{"chain"},
+		config.ChainLabelNames,
+	)
+
+	timeoutsGauge := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cosmos_wallets_exporter_timeouts",
+			Help: "A count of queries not finished before the scrape deadline",
+		},
+		config.ChainLabelNames,
 	)
 
 	timingsGauge := prometheus.NewGaugeVec(
@@ -41,7 +49,7 @@ func (q *QueriesQuerier) GetMetrics() ([]prometheus.Collector, []types.QueryInfo
 			Name: "cosmos_wallets_exporter_timings",
 			Help: "External LCD query timing",
 		},
-		[]string{"chain", "url"},
+		config.QueryLabelNames,
 	)
 
 	// so we would have this metrics even if there are no requests
@@ -53,9 +61,18 @@ func (q *QueriesQuerier) GetMetrics() ([]prometheus.Collector, []types.QueryInfo
 		errorGauge.With(prometheus.Labels{
 			"chain": chain.Name,
 		}).Set(0)
+
+		timeoutsGauge.With(prometheus.Labels{
+			"chain": chain.Name,
+		}).Set(0)
 	}
 
 	for _, query := range q.Infos {
+		// deduplicated queries did not hit the LCD, the original one is counted instead
+		if query.Cached {
+			continue
+		}
+
 		timingsGauge.With(prometheus.Labels{
 			"chain": query.Chain,
 			"url":   query.URL,
@@ -70,11 +87,18 @@ func (q *QueriesQuerier) GetMetrics() ([]prometheus.Collector, []types.QueryInfo
 				"chain": query.Chain,
 			}).Inc()
 		}
+
+		if query.TimedOut {
+			timeoutsGauge.With(prometheus.Labels{
+				"chain": query.Chain,
+			}).Inc()
+		}
 	}
 
 	return []prometheus.Collector{
 		successGauge,
 		errorGauge,
 		timingsGauge,
+		timeoutsGauge,
 	}, []types.QueryInfo{}
 }
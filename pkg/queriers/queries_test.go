@@ -18,13 +18,14 @@ func TestQueriesQuerier(t *testing.T) {
 
 	queries := []types.QueryInfo{
 		{Chain: "chain", Success: true, URL: "url1", Duration: 5 * time.Second},
-		{Chain: "chain", Success: false, URL: "url2", Duration: 3 * time.Second},
+		{Chain: "chain", Success: false, URL: "url2", Duration: 3 * time.Second, TimedOut: true},
+		{Chain: "chain", Success: true, URL: "url1", Duration: 5 * time.Second, Cached: true},
 	}
 
 	querier := NewQueriesQuerier(config, queries)
 	metrics, queryInfos := querier.GetMetrics()
 	assert.Empty(t, queryInfos)
-	assert.Len(t, metrics, 3)
+	assert.Len(t, metrics, 4)
 
 	successGauge, ok := metrics[0].(*prometheus.GaugeVec)
 	assert.True(t, ok)
@@ -57,4 +58,14 @@ func TestQueriesQuerier(t *testing.T) {
 		"chain": "chain",
 		"url":   "url2",
 	})), 0.01)
+
+	timeoutsGauge, ok := metrics[3].(*prometheus.GaugeVec)
+	assert.True(t, ok)
+	assert.Equal(t, 2, testutil.CollectAndCount(timeoutsGauge))
+	assert.InEpsilon(t, float64(1), testutil.ToFloat64(timeoutsGauge.With(prometheus.Labels{
+		"chain": "chain",
+	})), 0.01)
+	assert.Zero(t, testutil.ToFloat64(timeoutsGauge.With(prometheus.Labels{
+		"chain": "chain2",
+	})))
 }
@@ -5,6 +5,7 @@ import (
 	"main/pkg/config"
 	"main/pkg/tendermint"
 	"main/pkg/types"
+	"main/pkg/utils"
 	"math"
 	"strconv"
 	"sync"
@@ -19,21 +20,16 @@ import (
 type SupplierQuerier struct {
 	Config *config.Config
 	Logger zerolog.Logger
-	RPCs   []*tendermint.RPC
+	RPCs   *tendermint.Registry
 	Tracer trace.Tracer
 }
 
 func NewSupplierQuerier(
 	config *config.Config,
+	rpcs *tendermint.Registry,
 	logger zerolog.Logger,
 	tracer trace.Tracer,
 ) *SupplierQuerier {
-	rpcs := make([]*tendermint.RPC, len(config.Chains))
-
-	for index, chain := range config.Chains {
-		rpcs[index] = tendermint.NewRPC(chain, logger, tracer)
-	}
-
 	return &SupplierQuerier{
 		Config: config,
 		Logger: logger.With().Str("component", "supplier_querier").Logger(),
@@ -42,16 +38,18 @@ func NewSupplierQuerier(
 	}
 }
 
-func (q *SupplierQuerier) collectSupplierStakes(ctx context.Context, supplierStakeGauge *prometheus.GaugeVec, revShareMap map[string][]types.RevShareMetadata) ([]types.QueryInfo, map[string]types.SupplierData) {
+func (q *SupplierQuerier) collectSupplierStakes(ctx context.Context, customLabelNames []string, supplierStakeGauge *prometheus.GaugeVec, revSharePercentageGauge *prometheus.GaugeVec, revShareMisconfiguredGauge *prometheus.GaugeVec, revShareMissingGauge *prometheus.GaugeVec, revShareMap map[string][]types.RevShareMetadata) ([]types.QueryInfo, map[string]types.SupplierData) {
 	var queryInfos []types.QueryInfo
 	var wg sync.WaitGroup
 	var mutex sync.Mutex
 	supplierDataMap := make(map[string]types.SupplierData)
 
-	for index, chain := range q.Config.Chains {
-		rpc := q.RPCs[index]
+	for _, chain := range q.Config.Chains {
+		rpc := q.RPCs.Get(chain)
 
 		for _, supplier := range chain.Suppliers {
+			supplier.Labels = config.MergeLabels(chain.Labels, supplier.Labels)
+
 			wg.Add(1)
 			go func(supplier config.Supplier, chain config.Chain, rpc *tendermint.RPC) {
 				chainCtx, chainSpan := q.Tracer.Start(ctx, "Querying chain and supplier")
@@ -82,7 +80,10 @@ func (q *SupplierQuerier) collectSupplierStakes(ctx context.Context, supplierSta
 				supplierDataMap[supplierKey] = supplierData
 
 				// Process stake metric
-				q.processSupplierStakeMetric(supplierData, supplier, chain, supplierStakeGauge)
+				q.processSupplierStakeMetric(supplierData, supplier, chain, customLabelNames, supplierStakeGauge)
+
+				// Process rev_share percentages
+				q.processRevSharePercentages(supplierData, supplier, chain, customLabelNames, revSharePercentageGauge, revShareMisconfiguredGauge, revShareMissingGauge)
 
 				// Collect rev_share addresses
 				q.collectRevShareAddresses(supplierData, supplier, chain, revShareMap)
@@ -94,7 +95,7 @@ func (q *SupplierQuerier) collectSupplierStakes(ctx context.Context, supplierSta
 	return queryInfos, supplierDataMap
 }
 
-func (q *SupplierQuerier) processSupplierStakeMetric(supplierData types.SupplierData, supplier config.Supplier, chain config.Chain, supplierStakeGauge *prometheus.GaugeVec) {
+func (q *SupplierQuerier) processSupplierStakeMetric(supplierData types.SupplierData, supplier config.Supplier, chain config.Chain, customLabelNames []string, supplierStakeGauge *prometheus.GaugeVec) {
 	stake := supplierData.Stake
 	denom := stake.Denom
 
@@ -116,13 +117,67 @@ func (q *SupplierQuerier) processSupplierStakeMetric(supplierData types.Supplier
 		amount /= math.Pow10(denomInfo.DenomExponent)
 	}
 
-	supplierStakeGauge.With(prometheus.Labels{
+	supplierStakeGauge.With(withCustomLabels(prometheus.Labels{
 		"chain":   chain.Name,
 		"address": supplier.Address,
 		"name":    supplier.Name,
 		"group":   supplier.Group,
 		"denom":   denom,
-	}).Set(amount)
+	}, customLabelNames, supplier.Labels)).Set(amount)
+}
+
+func (q *SupplierQuerier) processRevSharePercentages(supplierData types.SupplierData, supplier config.Supplier, chain config.Chain, customLabelNames []string, revSharePercentageGauge *prometheus.GaugeVec, revShareMisconfiguredGauge *prometheus.GaugeVec, revShareMissingGauge *prometheus.GaugeVec) {
+	for _, service := range supplierData.Services {
+		serviceLabels := withCustomLabels(prometheus.Labels{
+			"chain":                     chain.Name,
+			"supplier_operator_address": supplierData.OperatorAddress,
+			"supplier_owner_address":    supplierData.OwnerAddress,
+			"supplier_name":             supplier.Name,
+			"service_id":                service.ServiceID,
+		}, customLabelNames, supplier.Labels)
+
+		// A service without rev shares is a separate case, not a misconfiguration
+		revShareMissingGauge.With(serviceLabels).Set(utils.BoolToFloat64(len(service.RevShare) == 0))
+		if len(service.RevShare) == 0 {
+			continue
+		}
+
+		total := float64(0)
+		misconfigured := false
+
+		for _, revShare := range service.RevShare {
+			percentage, err := strconv.ParseFloat(revShare.RevSharePercentage, 64)
+			if err != nil {
+				q.Logger.Error().
+					Err(err).
+					Str("chain", chain.Name).
+					Str("supplier", supplier.Address).
+					Str("service_id", service.ServiceID).
+					Str("percentage", revShare.RevSharePercentage).
+					Msg("Error parsing rev share percentage")
+				misconfigured = true
+				continue
+			}
+
+			total += percentage
+
+			revSharePercentageGauge.With(withCustomLabels(prometheus.Labels{
+				"chain":                     chain.Name,
+				"supplier_operator_address": supplierData.OperatorAddress,
+				"supplier_owner_address":    supplierData.OwnerAddress,
+				"supplier_name":             supplier.Name,
+				"service_id":                service.ServiceID,
+				"rev_share_address":         revShare.Address,
+			}, customLabelNames, supplier.Labels)).Add(percentage)
+		}
+
+		// Rev shares of a service are expected to add up to exactly 100%
+		if math.Abs(total-100) > 1e-9 {
+			misconfigured = true
+		}
+
+		revShareMisconfiguredGauge.With(serviceLabels).Set(utils.BoolToFloat64(misconfigured))
+	}
 }
 
 func (q *SupplierQuerier) collectRevShareAddresses(supplierData types.SupplierData, supplier config.Supplier, chain config.Chain, revShareMap map[string][]types.RevShareMetadata) {
@@ -135,8 +190,8 @@ func (q *SupplierQuerier) collectRevShareAddresses(supplierData types.SupplierDa
 				SupplierOwnerAddr:    supplierData.OwnerAddress,
 				SupplierName:         supplier.Name,
 				ServiceID:            service.ServiceID,
-				RevSharePercentage:   revShare.RevSharePercentage,
 				DetailedMetrics:      detailedMetrics,
+				Labels:               supplier.Labels,
 			}
 			revShareMap[revShare.Address] = append(revShareMap[revShare.Address], metadata)
 		}
@@ -158,9 +213,9 @@ func (q *SupplierQuerier) queryRevShareBalances(ctx context.Context, revShareMap
 
 		// Find the RPC for this chain
 		var rpc *tendermint.RPC
-		for index, chain := range q.Config.Chains {
+		for _, chain := range q.Config.Chains {
 			if chain.Name == chainName {
-				rpc = q.RPCs[index]
+				rpc = q.RPCs.Get(chain)
 				break
 			}
 		}
@@ -197,9 +252,10 @@ func (q *SupplierQuerier) queryRevShareBalances(ctx context.Context, revShareMap
 	return queryInfos, revShareBalances
 }
 
-func (q *SupplierQuerier) createRevShareMetrics(revShareMap map[string][]types.RevShareMetadata, revShareBalances map[string]types.Balances, detailedGauge *prometheus.GaugeVec, aggregateGauge *prometheus.GaugeVec) []prometheus.Collector {
+func (q *SupplierQuerier) createRevShareMetrics(revShareMap map[string][]types.RevShareMetadata, revShareBalances map[string]types.Balances, customLabelNames []string, detailedGauge *prometheus.GaugeVec, aggregateGauge *prometheus.GaugeVec) []prometheus.Collector {
 	var usedCollectors []prometheus.Collector
 	aggregateBalances := make(map[types.RevShareAggregateKey]map[string]float64)
+	aggregateLabels := make(map[types.RevShareAggregateKey]map[string]string)
 
 	for revShareAddr, metadataList := range revShareMap {
 		balances, found := revShareBalances[revShareAddr]
@@ -229,16 +285,15 @@ func (q *SupplierQuerier) createRevShareMetrics(revShareMap map[string][]types.R
 				}
 
 				if metadata.DetailedMetrics {
-					detailedGauge.With(prometheus.Labels{
+					detailedGauge.With(withCustomLabels(prometheus.Labels{
 						"chain":                     metadata.Chain,
 						"supplier_operator_address": metadata.SupplierOperatorAddr,
 						"supplier_owner_address":    metadata.SupplierOwnerAddr,
 						"supplier_name":             metadata.SupplierName,
 						"rev_share_address":         revShareAddr,
 						"service_id":                metadata.ServiceID,
-						"rev_share_percentage":      metadata.RevSharePercentage,
 						"denom":                     denom,
-					}).Set(amount)
+					}, customLabelNames, metadata.Labels)).Set(amount)
 				} else {
 					aggKey := types.RevShareAggregateKey{
 						Chain:                metadata.Chain,
@@ -251,6 +306,7 @@ func (q *SupplierQuerier) createRevShareMetrics(revShareMap map[string][]types.R
 					if aggregateBalances[aggKey] == nil {
 						aggregateBalances[aggKey] = make(map[string]float64)
 					}
+					aggregateLabels[aggKey] = metadata.Labels
 					aggregateBalances[aggKey][denom] += amount
 				}
 			}
@@ -278,14 +334,14 @@ func (q *SupplierQuerier) createRevShareMetrics(revShareMap map[string][]types.R
 	if len(aggregateBalances) > 0 {
 		for aggKey, denomBalances := range aggregateBalances {
 			for denom, amount := range denomBalances {
-				aggregateGauge.With(prometheus.Labels{
+				aggregateGauge.With(withCustomLabels(prometheus.Labels{
 					"chain":                     aggKey.Chain,
 					"supplier_operator_address": aggKey.SupplierOperatorAddr,
 					"supplier_owner_address":    aggKey.SupplierOwnerAddr,
 					"supplier_name":             aggKey.SupplierName,
 					"rev_share_address":         aggKey.RevShareAddress,
 					"denom":                     denom,
-				}).Set(amount)
+				}, customLabelNames, aggregateLabels[aggKey])).Set(amount)
 			}
 		}
 		usedCollectors = append(usedCollectors, aggregateGauge)
@@ -294,16 +350,22 @@ func (q *SupplierQuerier) createRevShareMetrics(revShareMap map[string][]types.R
 	return usedCollectors
 }
 
+func (q *SupplierQuerier) Name() string {
+	return "supplier"
+}
+
 func (q *SupplierQuerier) GetMetrics(ctx context.Context) ([]prometheus.Collector, []types.QueryInfo) {
 	childCtx, span := q.Tracer.Start(ctx, "Querying supplier stake and rev share metrics")
 	defer span.End()
 
+	customLabelNames := q.Config.GetLabelNames()
+
 	supplierStakeGauge := prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "cosmos_wallets_exporter_supplier_stake",
 			Help: "A Pocket Network supplier stake (in tokens)",
 		},
-		[]string{"chain", "address", "name", "group", "denom"},
+		withCustomLabelNames(config.WalletDenomLabelNames, customLabelNames),
 	)
 
 	revShareBalanceDetailedGauge := prometheus.NewGaugeVec(
@@ -311,7 +373,31 @@ func (q *SupplierQuerier) GetMetrics(ctx context.Context) ([]prometheus.Collecto
 			Name: "cosmos_wallets_exporter_supplier_rev_share_balance_detailed",
 			Help: "Detailed balance of revenue share addresses for Pocket Network suppliers (per service and percentage)",
 		},
-		[]string{"chain", "supplier_operator_address", "supplier_owner_address", "supplier_name", "rev_share_address", "service_id", "rev_share_percentage", "denom"},
+		withCustomLabelNames(config.RevShareBalanceDetailedLabelNames, customLabelNames),
+	)
+
+	revSharePercentageGauge := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cosmos_wallets_exporter_supplier_rev_share_percentage",
+			Help: "Revenue share percentage of an address for a Pocket Network supplier service",
+		},
+		withCustomLabelNames(config.RevSharePercentageLabelNames, customLabelNames),
+	)
+
+	revShareMisconfiguredGauge := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cosmos_wallets_exporter_supplier_rev_share_misconfigured",
+			Help: "Whether revenue share percentages of a Pocket Network supplier service do not sum up to 100",
+		},
+		withCustomLabelNames(config.SupplierServiceLabelNames, customLabelNames),
+	)
+
+	revShareMissingGauge := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cosmos_wallets_exporter_supplier_rev_share_missing",
+			Help: "Whether a Pocket Network supplier service has no revenue shares configured",
+		},
+		withCustomLabelNames(config.SupplierServiceLabelNames, customLabelNames),
 	)
 
 	revShareBalanceAggregateGauge := prometheus.NewGaugeVec(
@@ -319,26 +405,26 @@ func (q *SupplierQuerier) GetMetrics(ctx context.Context) ([]prometheus.Collecto
 			Name: "cosmos_wallets_exporter_supplier_rev_share_balance",
 			Help: "Aggregated balance of revenue share addresses for Pocket Network suppliers (summed across services)",
 		},
-		[]string{"chain", "supplier_operator_address", "supplier_owner_address", "supplier_name", "rev_share_address", "denom"},
+		withCustomLabelNames(config.RevShareBalanceLabelNames, customLabelNames),
 	)
 
 	// Phase 1: Collect supplier data and rev_share addresses
 	revShareMap := make(map[string][]types.RevShareMetadata)
-	queryInfos1, _ := q.collectSupplierStakes(childCtx, supplierStakeGauge, revShareMap)
+	queryInfos1, _ := q.collectSupplierStakes(childCtx, customLabelNames, supplierStakeGauge, revSharePercentageGauge, revShareMisconfiguredGauge, revShareMissingGauge, revShareMap)
 
 	// Phase 2: Query unique rev_share addresses
 	queryInfos2, revShareBalances := q.queryRevShareBalances(childCtx, revShareMap)
 
 	// Phase 3: Create rev_share balance metrics
-	usedCollectors := q.createRevShareMetrics(revShareMap, revShareBalances, revShareBalanceDetailedGauge, revShareBalanceAggregateGauge)
+	usedCollectors := q.createRevShareMetrics(revShareMap, revShareBalances, customLabelNames, revShareBalanceDetailedGauge, revShareBalanceAggregateGauge)
 
 	// Combine query infos
 	var allQueryInfos []types.QueryInfo
 	allQueryInfos = append(allQueryInfos, queryInfos1...)
 	allQueryInfos = append(allQueryInfos, queryInfos2...)
 
-	// Always include supplier stake gauge
-	finalCollectors := []prometheus.Collector{supplierStakeGauge}
+	// Always include supplier stake and rev share percentage gauges
+	finalCollectors := []prometheus.Collector{supplierStakeGauge, revSharePercentageGauge, revShareMisconfiguredGauge, revShareMissingGauge}
 	finalCollectors = append(finalCollectors, usedCollectors...)
 
 	return finalCollectors, allQueryInfos
@@ -0,0 +1,211 @@
+package queriers
+
+import (
+	"context"
+	"errors"
+	"main/assets"
+	configPkg "main/pkg/config"
+	loggerPkg "main/pkg/logger"
+	"main/pkg/tendermint"
+	"main/pkg/tracing"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:paralleltest // disabled due to httpmock usage
+func TestSupplierQuerierFail(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder(
+		"GET",
+		"https://example.com/pokt-network/poktroll/supplier/supplier/supplier",
+		httpmock.NewErrorResponder(errors.New("custom error")),
+	)
+
+	config := &configPkg.Config{Chains: []configPkg.Chain{{
+		Name:        "chain",
+		LCDEndpoint: "https://example.com",
+		Suppliers:   []configPkg.Supplier{{Address: "supplier"}},
+	}}}
+
+	tracer := tracing.InitNoopTracer()
+	logger := loggerPkg.GetNopLogger()
+	querier := NewSupplierQuerier(config, tendermint.NewRegistry(config, *logger, tracer), *logger, tracer)
+
+	metrics, queries := querier.GetMetrics(context.Background())
+	assert.Len(t, queries, 1)
+	assert.False(t, queries[0].Success)
+
+	require.Len(t, metrics, 4)
+	for _, metric := range metrics {
+		assert.Zero(t, testutil.CollectAndCount(metric))
+	}
+}
+
+//nolint:paralleltest // disabled due to httpmock usage
+func TestSupplierQuerierRevSharePercentages(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder(
+		"GET",
+		"https://example.com/pokt-network/poktroll/supplier/supplier/supplier",
+		httpmock.NewBytesResponder(200, assets.GetBytesOrPanic("supplier.json")),
+	)
+	httpmock.RegisterResponder(
+		"GET",
+		"https://example.com/cosmos/bank/v1beta1/balances/revshare1",
+		httpmock.NewBytesResponder(200, assets.GetBytesOrPanic("balance.json")),
+	)
+	httpmock.RegisterResponder(
+		"GET",
+		"https://example.com/cosmos/bank/v1beta1/balances/revshare2",
+		httpmock.NewBytesResponder(200, assets.GetBytesOrPanic("balance.json")),
+	)
+
+	config := &configPkg.Config{Chains: []configPkg.Chain{{
+		Name:        "chain",
+		LCDEndpoint: "https://example.com",
+		Suppliers:   []configPkg.Supplier{{Address: "supplier", Name: "name"}},
+	}}}
+
+	tracer := tracing.InitNoopTracer()
+	logger := loggerPkg.GetNopLogger()
+	querier := NewSupplierQuerier(config, tendermint.NewRegistry(config, *logger, tracer), *logger, tracer)
+
+	metrics, queries := querier.GetMetrics(context.Background())
+	assert.Len(t, queries, 3)
+	require.Len(t, metrics, 5)
+
+	percentageGauge, ok := metrics[1].(*prometheus.GaugeVec)
+	require.True(t, ok)
+	assert.Equal(t, 3, testutil.CollectAndCount(percentageGauge))
+	assert.InDelta(t, 70, testutil.ToFloat64(percentageGauge.With(prometheus.Labels{
+		"chain":                     "chain",
+		"supplier_operator_address": "supplier",
+		"supplier_owner_address":    "owner",
+		"supplier_name":             "name",
+		"service_id":                "anvil",
+		"rev_share_address":         "revshare1",
+	})), 0.01)
+
+	misconfiguredGauge, ok := metrics[2].(*prometheus.GaugeVec)
+	require.True(t, ok)
+	assert.Equal(t, 2, testutil.CollectAndCount(misconfiguredGauge))
+	assert.Zero(t, testutil.ToFloat64(misconfiguredGauge.With(prometheus.Labels{
+		"chain":                     "chain",
+		"supplier_operator_address": "supplier",
+		"supplier_owner_address":    "owner",
+		"supplier_name":             "name",
+		"service_id":                "anvil",
+	})))
+	assert.InDelta(t, 1, testutil.ToFloat64(misconfiguredGauge.With(prometheus.Labels{
+		"chain":                     "chain",
+		"supplier_operator_address": "supplier",
+		"supplier_owner_address":    "owner",
+		"supplier_name":             "name",
+		"service_id":                "eth",
+	})), 0.01)
+
+	// a service without rev shares is not misconfigured, but reported as missing them
+	missingGauge, ok := metrics[3].(*prometheus.GaugeVec)
+	require.True(t, ok)
+	assert.Equal(t, 3, testutil.CollectAndCount(missingGauge))
+	assert.Zero(t, testutil.ToFloat64(missingGauge.With(prometheus.Labels{
+		"chain":                     "chain",
+		"supplier_operator_address": "supplier",
+		"supplier_owner_address":    "owner",
+		"supplier_name":             "name",
+		"service_id":                "anvil",
+	})))
+	assert.InDelta(t, 1, testutil.ToFloat64(missingGauge.With(prometheus.Labels{
+		"chain":                     "chain",
+		"supplier_operator_address": "supplier",
+		"supplier_owner_address":    "owner",
+		"supplier_name":             "name",
+		"service_id":                "base",
+	})), 0.01)
+
+	detailedGauge, ok := metrics[4].(*prometheus.GaugeVec)
+	require.True(t, ok)
+	assert.InDelta(t, 234567, testutil.ToFloat64(detailedGauge.With(prometheus.Labels{
+		"chain":                     "chain",
+		"supplier_operator_address": "supplier",
+		"supplier_owner_address":    "owner",
+		"supplier_name":             "name",
+		"rev_share_address":         "revshare2",
+		"service_id":                "anvil",
+		"denom":                     "ustake",
+	})), 0.01)
+}
+
+//nolint:paralleltest // disabled due to httpmock usage
+func TestSupplierQuerierCustomLabels(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder(
+		"GET",
+		"https://example.com/pokt-network/poktroll/supplier/supplier/supplier",
+		httpmock.NewBytesResponder(200, assets.GetBytesOrPanic("supplier.json")),
+	)
+	httpmock.RegisterResponder(
+		"GET",
+		`=~^https://example.com/cosmos/bank/v1beta1/balances/revshare`,
+		httpmock.NewBytesResponder(200, assets.GetBytesOrPanic("balance.json")),
+	)
+
+	config := &configPkg.Config{Chains: []configPkg.Chain{{
+		Name:        "chain",
+		LCDEndpoint: "https://example.com",
+		Labels:      map[string]string{"environment": "production", "team": "infra"},
+		Suppliers: []configPkg.Supplier{{
+			Address: "supplier",
+			Name:    "name",
+			Labels:  map[string]string{"team": "pocket"},
+		}},
+		Wallets: []configPkg.Wallet{{Address: "wallet", Labels: map[string]string{"purpose": "fees"}}},
+	}}}
+
+	tracer := tracing.InitNoopTracer()
+	logger := loggerPkg.GetNopLogger()
+	querier := NewSupplierQuerier(config, tendermint.NewRegistry(config, *logger, tracer), *logger, tracer)
+
+	metrics, _ := querier.GetMetrics(context.Background())
+	require.Len(t, metrics, 5)
+
+	stakeGauge, ok := metrics[0].(*prometheus.GaugeVec)
+	require.True(t, ok)
+	assert.Equal(t, 1, testutil.CollectAndCount(stakeGauge))
+	assert.Positive(t, testutil.ToFloat64(stakeGauge.With(prometheus.Labels{
+		"chain":       "chain",
+		"address":     "supplier",
+		"name":        "name",
+		"group":       "",
+		"denom":       "upokt",
+		"environment": "production",
+		"purpose":     "",
+		"team":        "pocket",
+	})))
+
+	detailedGauge, ok := metrics[4].(*prometheus.GaugeVec)
+	require.True(t, ok)
+	assert.InDelta(t, 234567, testutil.ToFloat64(detailedGauge.With(prometheus.Labels{
+		"chain":                     "chain",
+		"supplier_operator_address": "supplier",
+		"supplier_owner_address":    "owner",
+		"supplier_name":             "name",
+		"rev_share_address":         "revshare2",
+		"service_id":                "anvil",
+		"denom":                     "ustake",
+		"environment":               "production",
+		"purpose":                   "",
+		"team":                      "pocket",
+	})), 0.01)
+}
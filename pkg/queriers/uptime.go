@@ -22,6 +22,10 @@ func NewUptimeQuerier(tracer trace.Tracer) *UptimeQuerier {
 	}
 }
 
+func (u *UptimeQuerier) Name() string {
+	return "uptime"
+}
+
 func (u *UptimeQuerier) GetMetrics(ctx context.Context) ([]prometheus.Collector, []types.QueryInfo) {
 	uptimeMetricsGauge := prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
@@ -0,0 +1,319 @@
+package queriers
+
+import (
+	"context"
+	"fmt"
+	"main/pkg/config"
+	"main/pkg/tendermint"
+	"main/pkg/types"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	cosmosMath "cosmossdk.io/math"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+)
+
+type VestingQuerier struct {
+	Config *config.Config
+	Logger zerolog.Logger
+	RPCs   *tendermint.Registry
+	Tracer trace.Tracer
+}
+
+func NewVestingQuerier(
+	config *config.Config,
+	rpcs *tendermint.Registry,
+	logger zerolog.Logger,
+	tracer trace.Tracer,
+) *VestingQuerier {
+	return &VestingQuerier{
+		Config: config,
+		Logger: logger.With().Str("component", "vesting_querier").Logger(),
+		RPCs:   rpcs,
+		Tracer: tracer,
+	}
+}
+
+func (q *VestingQuerier) Name() string {
+	return "vesting"
+}
+
+func (q *VestingQuerier) GetMetrics(ctx context.Context) ([]prometheus.Collector, []types.QueryInfo) {
+	childCtx, span := q.Tracer.Start(ctx, "Querying vesting metrics")
+	defer span.End()
+
+	customLabelNames := q.Config.GetLabelNames()
+	labelNames := withCustomLabelNames(config.WalletDenomLabelNames, customLabelNames)
+
+	originalGauge := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cosmos_wallets_exporter_vesting_original",
+			Help: "An amount of tokens a vesting account was created with (in tokens)",
+		},
+		labelNames,
+	)
+
+	vestedGauge := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cosmos_wallets_exporter_vesting_vested",
+			Help: "An amount of tokens of a vesting account vested so far (in tokens)",
+		},
+		labelNames,
+	)
+
+	lockedGauge := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cosmos_wallets_exporter_vesting_locked",
+			Help: "An amount of tokens of a vesting account that are still locked (in tokens)",
+		},
+		labelNames,
+	)
+
+	spendableGauge := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cosmos_wallets_exporter_spendable_balance",
+			Help: "A vesting account balance that can be spent (in tokens)",
+		},
+		labelNames,
+	)
+
+	var queryInfos []types.QueryInfo
+
+	now := time.Now()
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+
+	for _, chain := range q.Config.Chains {
+		if !chain.VestingMetrics {
+			continue
+		}
+
+		rpc := q.RPCs.Get(chain)
+
+		for _, wallet := range chain.Wallets {
+			wg.Add(1)
+			go func(wallet config.Wallet, chain config.Chain, rpc *tendermint.RPC) {
+				chainCtx, chainSpan := q.Tracer.Start(childCtx, "Querying chain and vesting account")
+				chainSpan.SetAttributes(attribute.String("chain", chain.Name))
+				chainSpan.SetAttributes(attribute.String("wallet", wallet.Address))
+				defer chainSpan.End()
+
+				defer wg.Done()
+
+				accountResponse, queryInfo, err := rpc.GetAccount(wallet.Address, chainCtx)
+
+				mutex.Lock()
+				queryInfos = append(queryInfos, queryInfo)
+				mutex.Unlock()
+
+				if err != nil {
+					q.Logger.Error().
+						Err(err).
+						Str("chain", chain.Name).
+						Str("wallet", wallet.Address).
+						Msg("Error querying account")
+					return
+				}
+
+				account := accountResponse.Account
+				if !account.IsVesting() {
+					return
+				}
+
+				vested, err := GetVestedCoins(account, now)
+				if err != nil {
+					q.Logger.Error().
+						Err(err).
+						Str("chain", chain.Name).
+						Str("wallet", wallet.Address).
+						Msg("Error calculating vested coins")
+					return
+				}
+
+				spendableResponse, queryInfo, err := rpc.GetSpendableBalances(wallet.Address, chainCtx)
+
+				mutex.Lock()
+				defer mutex.Unlock()
+
+				queryInfos = append(queryInfos, queryInfo)
+
+				getLabels := func(denom string) prometheus.Labels {
+					return withCustomLabels(prometheus.Labels{
+						"chain":   chain.Name,
+						"address": wallet.Address,
+						"name":    wallet.Name,
+						"group":   wallet.Group,
+						"denom":   denom,
+					}, customLabelNames, config.MergeLabels(chain.Labels, wallet.Labels))
+				}
+
+				// vesting amounts are calculated from the account, so they are exported
+				// even if spendable balances could not be queried
+				for _, original := range account.BaseVestingAccount.OriginalVesting {
+					vestedAmount := getBalancesAmount(vested, original.Denom)
+
+					denom, originalTokens := getDenomAmount(chain, original)
+					_, vestedTokens := getDenomAmount(chain, types.Balance{
+						Denom:  original.Denom,
+						Amount: vestedAmount,
+					})
+					_, lockedTokens := getDenomAmount(chain, types.Balance{
+						Denom:  original.Denom,
+						Amount: original.Amount.Sub(vestedAmount),
+					})
+
+					originalGauge.With(getLabels(denom)).Set(originalTokens)
+					vestedGauge.With(getLabels(denom)).Set(vestedTokens)
+					lockedGauge.With(getLabels(denom)).Set(lockedTokens)
+				}
+
+				if err != nil {
+					q.Logger.Error().
+						Err(err).
+						Str("chain", chain.Name).
+						Str("wallet", wallet.Address).
+						Msg("Error querying spendable balances")
+					return
+				}
+
+				for _, balance := range spendableResponse.Balances {
+					denom, amount := getDenomAmount(chain, balance)
+					spendableGauge.With(getLabels(denom)).Set(amount)
+				}
+			}(wallet, chain, rpc)
+		}
+	}
+
+	wg.Wait()
+
+	return []prometheus.Collector{
+		originalGauge,
+		vestedGauge,
+		lockedGauge,
+		spendableGauge,
+	}, queryInfos
+}
+
+// GetVestedCoins returns the coins of a vesting account vested at the given time,
+// the same way the vesting module calculates them.
+func GetVestedCoins(account types.Account, now time.Time) (types.Balances, error) {
+	base := account.BaseVestingAccount
+	if base == nil {
+		return nil, fmt.Errorf("not a vesting account: %s", account.Type)
+	}
+
+	switch account.Type {
+	case types.AccountTypePermanentLocked:
+		return types.Balances{}, nil
+	case types.AccountTypeDelayedVesting:
+		endTime, err := strconv.ParseInt(base.EndTime, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid end time: %s", err)
+		}
+
+		if now.Unix() < endTime {
+			return types.Balances{}, nil
+		}
+
+		return base.OriginalVesting, nil
+	case types.AccountTypeContinuousVesting:
+		startTime, err := strconv.ParseInt(account.StartTime, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start time: %s", err)
+		}
+
+		endTime, err := strconv.ParseInt(base.EndTime, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid end time: %s", err)
+		}
+
+		if now.Unix() <= startTime {
+			return types.Balances{}, nil
+		} else if now.Unix() >= endTime {
+			return base.OriginalVesting, nil
+		}
+
+		// the vesting module rounds the vested share of the period to 18 decimals,
+		// then the vested amount of each coin to an integer
+		share := cosmosMath.LegacyNewDec(now.Unix() - startTime).
+			Quo(cosmosMath.LegacyNewDec(endTime - startTime))
+
+		vested := make(types.Balances, len(base.OriginalVesting))
+		for index, coin := range base.OriginalVesting {
+			vested[index] = types.Balance{
+				Denom:  coin.Denom,
+				Amount: cosmosMath.LegacyNewDecFromInt(coin.Amount.Mul(share).RoundInt()),
+			}
+		}
+
+		return vested, nil
+	case types.AccountTypePeriodicVesting:
+		periodStart, err := strconv.ParseInt(account.StartTime, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start time: %s", err)
+		}
+
+		vested := types.Balances{}
+
+		for index, period := range account.VestingPeriods {
+			length, err := strconv.ParseInt(period.Length, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid length of period %d: %s", index, err)
+			}
+
+			periodStart += length
+			if now.Unix() < periodStart {
+				break
+			}
+
+			for _, coin := range period.Amount {
+				vested = addBalance(vested, coin)
+			}
+		}
+
+		return vested, nil
+	default:
+		return nil, fmt.Errorf("unsupported vesting account type: %s", account.Type)
+	}
+}
+
+func addBalance(balances types.Balances, balance types.Balance) types.Balances {
+	for index := range balances {
+		if balances[index].Denom == balance.Denom {
+			balances[index].Amount = balances[index].Amount.Add(balance.Amount)
+			return balances
+		}
+	}
+
+	return append(balances, balance)
+}
+
+func getBalancesAmount(balances types.Balances, denom string) cosmosMath.LegacyDec {
+	for _, balance := range balances {
+		if balance.Denom == denom {
+			return balance.Amount
+		}
+	}
+
+	return cosmosMath.LegacyZeroDec()
+}
+
+// getDenomAmount returns the display denom and the amount in tokens, if the denom is configured.
+func getDenomAmount(chain config.Chain, balance types.Balance) (string, float64) {
+	denom := balance.Denom
+	amount := balance.Amount.MustFloat64()
+
+	if denomInfo, found := chain.FindDenomByName(balance.Denom); found {
+		denom = denomInfo.GetName()
+		amount /= math.Pow10(denomInfo.DenomExponent)
+	}
+
+	return denom, amount
+}
@@ -0,0 +1,254 @@
+package queriers
+
+import (
+	"context"
+	"errors"
+	"main/assets"
+	configPkg "main/pkg/config"
+	loggerPkg "main/pkg/logger"
+	"main/pkg/tendermint"
+	"main/pkg/tracing"
+	"main/pkg/types"
+	"testing"
+	"time"
+
+	cosmosMath "cosmossdk.io/math"
+	"github.com/jarcoal/httpmock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getVestingBalances(amount int64) types.Balances {
+	return types.Balances{{Denom: "uatom", Amount: cosmosMath.LegacyNewDec(amount)}}
+}
+
+func TestGetVestedCoinsContinuous(t *testing.T) {
+	t.Parallel()
+
+	account := types.Account{
+		Type: types.AccountTypeContinuousVesting,
+		BaseVestingAccount: &types.BaseVestingAccount{
+			OriginalVesting: getVestingBalances(1000),
+			EndTime:         "2000",
+		},
+		StartTime: "1000",
+	}
+
+	vested, err := GetVestedCoins(account, time.Unix(500, 0))
+	require.NoError(t, err)
+	assert.Empty(t, vested)
+
+	vested, err = GetVestedCoins(account, time.Unix(1250, 0))
+	require.NoError(t, err)
+	require.Len(t, vested, 1)
+	assert.Equal(t, "250.000000000000000000", vested[0].Amount.String())
+
+	// rounded to an integer, same as the vesting module does
+	account.BaseVestingAccount.OriginalVesting = getVestingBalances(1001)
+	vested, err = GetVestedCoins(account, time.Unix(1333, 0))
+	require.NoError(t, err)
+	require.Len(t, vested, 1)
+	assert.Equal(t, "333.000000000000000000", vested[0].Amount.String())
+
+	account.BaseVestingAccount.OriginalVesting = getVestingBalances(1000)
+	vested, err = GetVestedCoins(account, time.Unix(3000, 0))
+	require.NoError(t, err)
+	require.Len(t, vested, 1)
+	assert.InDelta(t, 1000, vested[0].Amount.MustFloat64(), 0.01)
+
+	account.StartTime = "invalid"
+	_, err = GetVestedCoins(account, time.Unix(1250, 0))
+	require.ErrorContains(t, err, "invalid start time")
+}
+
+func TestGetVestedCoinsDelayed(t *testing.T) {
+	t.Parallel()
+
+	account := types.Account{
+		Type: types.AccountTypeDelayedVesting,
+		BaseVestingAccount: &types.BaseVestingAccount{
+			OriginalVesting: getVestingBalances(1000),
+			EndTime:         "2000",
+		},
+	}
+
+	vested, err := GetVestedCoins(account, time.Unix(1999, 0))
+	require.NoError(t, err)
+	assert.Empty(t, vested)
+
+	vested, err = GetVestedCoins(account, time.Unix(2000, 0))
+	require.NoError(t, err)
+	require.Len(t, vested, 1)
+	assert.InDelta(t, 1000, vested[0].Amount.MustFloat64(), 0.01)
+}
+
+func TestGetVestedCoinsPeriodic(t *testing.T) {
+	t.Parallel()
+
+	account := types.Account{
+		Type: types.AccountTypePeriodicVesting,
+		BaseVestingAccount: &types.BaseVestingAccount{
+			OriginalVesting: getVestingBalances(1000),
+			EndTime:         "1300",
+		},
+		StartTime: "1000",
+		VestingPeriods: []types.VestingPeriod{
+			{Length: "100", Amount: getVestingBalances(200)},
+			{Length: "100", Amount: getVestingBalances(300)},
+			{Length: "100", Amount: getVestingBalances(500)},
+		},
+	}
+
+	vested, err := GetVestedCoins(account, time.Unix(1050, 0))
+	require.NoError(t, err)
+	assert.Empty(t, vested)
+
+	vested, err = GetVestedCoins(account, time.Unix(1200, 0))
+	require.NoError(t, err)
+	require.Len(t, vested, 1)
+	assert.InDelta(t, 500, vested[0].Amount.MustFloat64(), 0.01)
+
+	account.VestingPeriods[0].Length = "invalid"
+	_, err = GetVestedCoins(account, time.Unix(1200, 0))
+	require.ErrorContains(t, err, "invalid length of period 0")
+}
+
+func TestGetVestedCoinsNotVesting(t *testing.T) {
+	t.Parallel()
+
+	_, err := GetVestedCoins(types.Account{Type: "/cosmos.auth.v1beta1.BaseAccount"}, time.Now())
+	require.ErrorContains(t, err, "not a vesting account")
+
+	vested, err := GetVestedCoins(types.Account{
+		Type:               types.AccountTypePermanentLocked,
+		BaseVestingAccount: &types.BaseVestingAccount{OriginalVesting: getVestingBalances(1000)},
+	}, time.Now())
+	require.NoError(t, err)
+	assert.Empty(t, vested)
+}
+
+//nolint:paralleltest // disabled due to httpmock usage
+func TestVestingQuerierFail(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder(
+		"GET",
+		"https://example.com/cosmos/auth/v1beta1/accounts/address",
+		httpmock.NewErrorResponder(errors.New("custom error")),
+	)
+
+	config := &configPkg.Config{Chains: []configPkg.Chain{{
+		Name:           "chain",
+		LCDEndpoint:    "https://example.com",
+		Wallets:        []configPkg.Wallet{{Address: "address"}},
+		VestingMetrics: true,
+	}}}
+
+	tracer := tracing.InitNoopTracer()
+	logger := loggerPkg.GetNopLogger()
+	querier := NewVestingQuerier(config, tendermint.NewRegistry(config, *logger, tracer), *logger, tracer)
+
+	metrics, queries := querier.GetMetrics(context.Background())
+	assert.Len(t, queries, 1)
+	assert.False(t, queries[0].Success)
+
+	assert.Len(t, metrics, 4)
+	for _, metric := range metrics {
+		assert.Zero(t, testutil.CollectAndCount(metric))
+	}
+}
+
+//nolint:paralleltest // disabled due to httpmock usage
+func TestVestingQuerierOk(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder(
+		"GET",
+		"https://example.com/cosmos/auth/v1beta1/accounts/vesting",
+		httpmock.NewBytesResponder(200, assets.GetBytesOrPanic("account-vesting.json")),
+	)
+	httpmock.RegisterResponder(
+		"GET",
+		"https://example.com/cosmos/auth/v1beta1/accounts/base",
+		httpmock.NewBytesResponder(200, assets.GetBytesOrPanic("account-base.json")),
+	)
+	httpmock.RegisterResponder(
+		"GET",
+		"https://example.com/cosmos/bank/v1beta1/spendable_balances/vesting",
+		httpmock.NewBytesResponder(200, assets.GetBytesOrPanic("spendable-balances.json")),
+	)
+
+	config := &configPkg.Config{Chains: []configPkg.Chain{{
+		Name:        "chain",
+		LCDEndpoint: "https://example.com",
+		Denoms:      []configPkg.DenomInfo{{Denom: "uatom", DisplayDenom: "atom", DenomExponent: 6}},
+		Wallets: []configPkg.Wallet{
+			{Address: "vesting", Name: "team", Group: "team"},
+			{Address: "base", Name: "relayer", Group: "relayers"},
+		},
+		VestingMetrics: true,
+	}}}
+
+	tracer := tracing.InitNoopTracer()
+	logger := loggerPkg.GetNopLogger()
+	querier := NewVestingQuerier(config, tendermint.NewRegistry(config, *logger, tracer), *logger, tracer)
+
+	metrics, queries := querier.GetMetrics(context.Background())
+	// spendable balances are only queried for the vesting account
+	assert.Len(t, queries, 3)
+	for _, query := range queries {
+		assert.True(t, query.Success)
+	}
+
+	require.Len(t, metrics, 4)
+
+	labels := prometheus.Labels{
+		"chain":   "chain",
+		"address": "vesting",
+		"name":    "team",
+		"group":   "team",
+		"denom":   "atom",
+	}
+
+	for _, metric := range metrics {
+		assert.Equal(t, 1, testutil.CollectAndCount(metric))
+	}
+
+	originalGauge, ok := metrics[0].(*prometheus.GaugeVec)
+	require.True(t, ok)
+	vestedGauge, ok := metrics[1].(*prometheus.GaugeVec)
+	require.True(t, ok)
+	lockedGauge, ok := metrics[2].(*prometheus.GaugeVec)
+	require.True(t, ok)
+	spendableGauge, ok := metrics[3].(*prometheus.GaugeVec)
+	require.True(t, ok)
+
+	assert.InDelta(t, 1000, testutil.ToFloat64(originalGauge.With(labels)), 0.01)
+	assert.Zero(t, testutil.ToFloat64(vestedGauge.With(labels)))
+	assert.InDelta(t, 1000, testutil.ToFloat64(lockedGauge.With(labels)), 0.01)
+	assert.InDelta(t, 5, testutil.ToFloat64(spendableGauge.With(labels)), 0.01)
+}
+
+//nolint:paralleltest // disabled due to httpmock usage
+func TestVestingQuerierDisabled(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	config := &configPkg.Config{Chains: []configPkg.Chain{{
+		Name:        "chain",
+		LCDEndpoint: "https://example.com",
+		Wallets:     []configPkg.Wallet{{Address: "address"}},
+	}}}
+
+	tracer := tracing.InitNoopTracer()
+	logger := loggerPkg.GetNopLogger()
+	querier := NewVestingQuerier(config, tendermint.NewRegistry(config, *logger, tracer), *logger, tracer)
+
+	_, queries := querier.GetMetrics(context.Background())
+	assert.Empty(t, queries)
+	assert.Zero(t, httpmock.GetTotalCallCount())
+}
@@ -0,0 +1,409 @@
+package sources
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"main/pkg/config"
+	"main/pkg/fs"
+	httpPkg "main/pkg/http"
+	metricsPkg "main/pkg/metrics"
+	"main/pkg/types"
+	"main/pkg/utils"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// fileCheckInterval is how often files are checked for changes,
+// they are cheap to read, so they are checked more often than the refresh interval.
+const fileCheckInterval = 10 * time.Second
+
+// Source is a wallet source of a chain with the latest wallets loaded from it.
+type Source struct {
+	Chain  string
+	Config config.WalletSource
+
+	Wallets []config.Wallet
+	Content []byte
+	Success bool
+}
+
+// Manager loads wallets from wallet sources in the background and merges them
+// with the wallets from the config.
+type Manager struct {
+	Config     *config.Config
+	Filesystem fs.FS
+	Logger     zerolog.Logger
+	Tracer     trace.Tracer
+	Sources    []*Source
+	Mutex      sync.Mutex
+	Stopped    chan struct{}
+
+	// kept for the whole app lifetime, as it counts failures since the app start
+	FailuresCounter *prometheus.CounterVec
+}
+
+func NewManager(
+	appConfig *config.Config,
+	filesystem fs.FS,
+	logger zerolog.Logger,
+	tracer trace.Tracer,
+) *Manager {
+	sources := []*Source{}
+
+	failuresCounter := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cosmos_wallets_exporter_wallet_source_failures_total",
+			Help: "Count of failed wallet source loads since the app start",
+		},
+		config.SourceLabelNames,
+	)
+
+	for _, chain := range appConfig.Chains {
+		for _, source := range chain.WalletSources {
+			sources = append(sources, &Source{Chain: chain.Name, Config: source})
+
+			// exported as 0 until the first failure
+			failuresCounter.With(getLabels(chain.Name, source))
+		}
+	}
+
+	return &Manager{
+		Config:          appConfig,
+		Filesystem:      filesystem,
+		Logger:          logger.With().Str("component", "wallet_sources").Logger(),
+		Tracer:          tracer,
+		Sources:         sources,
+		Stopped:         make(chan struct{}),
+		FailuresCounter: failuresCounter,
+	}
+}
+
+func getLabels(chain string, source config.WalletSource) prometheus.Labels {
+	return prometheus.Labels{"chain": chain, "source": source.GetName()}
+}
+
+// Load loads all the sources once.
+func (m *Manager) Load(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	for _, source := range m.Sources {
+		wg.Add(1)
+		go func(source *Source) {
+			defer wg.Done()
+			m.LoadSource(ctx, source)
+		}(source)
+	}
+
+	wg.Wait()
+}
+
+// Start reloads the sources in the background until Stop is called: files are reloaded
+// once their content changes, and URLs are reloaded every refresh interval.
+func (m *Manager) Start() {
+	for _, source := range m.Sources {
+		interval := source.Config.GetRefreshInterval()
+		if source.Config.File != "" && fileCheckInterval < interval {
+			interval = fileCheckInterval
+		}
+
+		go func(source *Source, interval time.Duration) {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-m.Stopped:
+					return
+				case <-ticker.C:
+					m.LoadSource(context.Background(), source)
+				}
+			}
+		}(source, interval)
+	}
+}
+
+func (m *Manager) Stop() {
+	close(m.Stopped)
+}
+
+// LoadSource loads wallets from a source. If it fails, the previously loaded wallets are kept.
+func (m *Manager) LoadSource(ctx context.Context, source *Source) {
+	sourceLogger := m.Logger.With().
+		Str("chain", source.Chain).
+		Str("source", source.Config.GetName()).
+		Logger()
+
+	content, wallets, err := m.fetch(ctx, source)
+
+	m.Mutex.Lock()
+	defer m.Mutex.Unlock()
+
+	if err != nil {
+		sourceLogger.Warn().Err(err).Msg("Could not load wallets from source")
+		source.Success = false
+		m.FailuresCounter.With(getLabels(source.Chain, source.Config)).Inc()
+		return
+	}
+
+	if source.Success && bytes.Equal(content, source.Content) {
+		return
+	}
+
+	sourceLogger.Info().Int("wallets", len(wallets)).Msg("Loaded wallets from source")
+
+	source.Content = content
+	source.Wallets = wallets
+	source.Success = true
+}
+
+func (m *Manager) fetch(ctx context.Context, source *Source) ([]byte, []config.Wallet, error) {
+	if source.Config.File != "" {
+		content, err := m.Filesystem.ReadFile(source.Config.File)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		wallets, err := ParseWallets(content, source.Config.GetFormat(), source.Config.Group, m.Config.MetricsConfig)
+		return content, wallets, err
+	}
+
+	var entries []config.Wallet
+
+	// the URL may have credentials in its user info or query, so they are not logged
+	client := httpPkg.NewClient(m.Logger, source.Chain, m.Tracer).
+		WithRedaction(source.Config.URL, utils.RedactURLCredentials(source.Config.URL))
+	if _, _, err := client.Get(source.Config.URL, &entries, checkStatusCode, ctx); err != nil {
+		return nil, nil, err
+	}
+
+	content, err := json.Marshal(entries)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	wallets, err := ParseWallets(content, config.WalletSourceFormatJSON, source.Config.Group, m.Config.MetricsConfig)
+	return content, wallets, err
+}
+
+func checkStatusCode(response *http.Response) error {
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", response.StatusCode)
+	}
+
+	return nil
+}
+
+// ParseWallets parses a list of wallets either as a JSON array of objects
+// with address, name and group fields, or as CSV with a header having the same columns.
+// Wallets without a group get the default one. Wallet labels are checked against
+// the metrics config, as they end up on the same metrics as const and renamed labels.
+func ParseWallets(
+	content []byte,
+	format string,
+	defaultGroup string,
+	metricsConfig config.MetricsConfig,
+) ([]config.Wallet, error) {
+	var wallets []config.Wallet
+
+	if format == config.WalletSourceFormatCSV {
+		parsed, err := parseCSV(content)
+		if err != nil {
+			return nil, err
+		}
+
+		wallets = parsed
+	} else if err := json.Unmarshal(content, &wallets); err != nil {
+		return nil, err
+	}
+
+	for index := range wallets {
+		if err := wallets[index].Validate(); err != nil {
+			return nil, fmt.Errorf("error in wallet %d: %s", index, err)
+		}
+
+		for name := range wallets[index].Labels {
+			if err := metricsConfig.ValidateCustomLabelName(name); err != nil {
+				return nil, fmt.Errorf("error in wallet %d: %s", index, err)
+			}
+		}
+
+		if wallets[index].Group == "" {
+			wallets[index].Group = defaultGroup
+		}
+	}
+
+	return wallets, nil
+}
+
+func parseCSV(content []byte) ([]config.Wallet, error) {
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("could not read CSV header: %s", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for index, column := range header {
+		columns[strings.ToLower(strings.TrimSpace(column))] = index
+	}
+
+	if _, ok := columns["address"]; !ok {
+		return nil, errors.New("no address column in CSV header")
+	}
+
+	getColumn := func(record []string, name string) string {
+		index, ok := columns[name]
+		if !ok || index >= len(record) {
+			return ""
+		}
+
+		return strings.TrimSpace(record[index])
+	}
+
+	wallets := []config.Wallet{}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		wallets = append(wallets, config.Wallet{
+			Address: getColumn(record, "address"),
+			Name:    getColumn(record, "name"),
+			Group:   getColumn(record, "group"),
+		})
+	}
+
+	return wallets, nil
+}
+
+// GetConfig returns the config with wallets loaded from sources added to their chains.
+// Addresses that are already listed in the config or in a previous source are skipped.
+// If there are no sources, the config itself is returned.
+func (m *Manager) GetConfig() *config.Config {
+	if len(m.Sources) == 0 {
+		return m.Config
+	}
+
+	m.Mutex.Lock()
+	defer m.Mutex.Unlock()
+
+	merged := *m.Config
+	merged.Chains = make([]config.Chain, len(m.Config.Chains))
+
+	for index, chain := range m.Config.Chains {
+		addresses := make(map[string]bool, len(chain.Wallets))
+		for _, wallet := range chain.Wallets {
+			addresses[wallet.Address] = true
+		}
+
+		wallets := slices.Clone(chain.Wallets)
+
+		for _, source := range m.Sources {
+			if source.Chain != chain.Name {
+				continue
+			}
+
+			for _, wallet := range source.Wallets {
+				if addresses[wallet.Address] {
+					continue
+				}
+
+				addresses[wallet.Address] = true
+				wallets = append(wallets, wallet)
+			}
+		}
+
+		chain.Wallets = wallets
+		merged.Chains[index] = chain
+	}
+
+	return &merged
+}
+
+// Querier exports the wallet sources metrics of the chains of a config, which can be
+// a filtered copy of the app config, while the sources are loaded by the shared manager.
+type Querier struct {
+	Config  *config.Config
+	Manager *Manager
+	Tracer  trace.Tracer
+}
+
+func NewQuerier(appConfig *config.Config, manager *Manager, tracer trace.Tracer) *Querier {
+	return &Querier{
+		Config:  appConfig,
+		Manager: manager,
+		Tracer:  tracer,
+	}
+}
+
+func (q *Querier) Name() string {
+	return "wallet_sources"
+}
+
+func (q *Querier) GetMetrics(ctx context.Context) ([]prometheus.Collector, []types.QueryInfo) {
+	_, span := q.Tracer.Start(ctx, "Getting wallet sources metrics")
+	defer span.End()
+
+	successGauge := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cosmos_wallets_exporter_wallet_source_success",
+			Help: "Whether the latest load of a wallet source succeeded",
+		},
+		config.SourceLabelNames,
+	)
+
+	walletsGauge := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cosmos_wallets_exporter_wallet_source_wallets",
+			Help: "Count of wallets loaded from a wallet source",
+		},
+		config.SourceLabelNames,
+	)
+
+	chains := make([]string, len(q.Config.Chains))
+	for index, chain := range q.Config.Chains {
+		chains[index] = chain.Name
+	}
+
+	q.Manager.Mutex.Lock()
+	defer q.Manager.Mutex.Unlock()
+
+	for _, source := range q.Manager.Sources {
+		if !slices.Contains(chains, source.Chain) {
+			continue
+		}
+
+		labels := getLabels(source.Chain, source.Config)
+
+		success := 0.0
+		if source.Success {
+			success = 1
+		}
+
+		successGauge.With(labels).Set(success)
+		walletsGauge.With(labels).Set(float64(len(source.Wallets)))
+	}
+
+	failuresCounter := metricsPkg.NewFilter(q.Manager.FailuresCounter, "chain", chains)
+
+	return []prometheus.Collector{successGauge, walletsGauge, failuresCounter}, []types.QueryInfo{}
+}
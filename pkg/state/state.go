@@ -0,0 +1,166 @@
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Manager persists the exporter state (like last seen block heights) between restarts
+// as a JSON file. Each component stores its data under its own key.
+// If no path is provided, the state is kept in memory only.
+// The file is only written when some value has changed since the last save.
+type Manager struct {
+	Path    string
+	Data    map[string]json.RawMessage
+	Dirty   bool
+	SavedAt time.Time
+	Mutex   sync.Mutex
+	// held for the whole save, so an older content is never written over a newer one
+	SaveMutex sync.Mutex
+}
+
+func NewManager(path string) *Manager {
+	return &Manager{
+		Path: path,
+		Data: make(map[string]json.RawMessage),
+	}
+}
+
+// Load reads the state file. A missing file is not an error, as it's expected on the first run.
+func (m *Manager) Load() error {
+	if m.Path == "" {
+		return nil
+	}
+
+	bytes, err := os.ReadFile(m.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	data := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(bytes, &data); err != nil {
+		return err
+	}
+
+	m.Mutex.Lock()
+	m.Data = data
+	m.Mutex.Unlock()
+
+	return nil
+}
+
+// Get decodes the value stored under the key into target, returning false if there's none.
+func (m *Manager) Get(key string, target interface{}) (bool, error) {
+	m.Mutex.Lock()
+	value, ok := m.Data[key]
+	m.Mutex.Unlock()
+
+	if !ok {
+		return false, nil
+	}
+
+	if err := json.Unmarshal(value, target); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (m *Manager) Set(key string, target interface{}) error {
+	value, err := json.Marshal(target)
+	if err != nil {
+		return err
+	}
+
+	m.Mutex.Lock()
+	if !bytes.Equal(m.Data[key], value) {
+		m.Data[key] = value
+		m.Dirty = true
+	}
+	m.Mutex.Unlock()
+
+	return nil
+}
+
+// Save writes the state file, first into a temporary file and then renaming it,
+// so a crash while writing won't leave a corrupted state behind.
+// Does nothing if nothing has changed since the last save. Safe to call concurrently.
+func (m *Manager) Save() error {
+	if m.Path == "" {
+		return nil
+	}
+
+	m.SaveMutex.Lock()
+	defer m.SaveMutex.Unlock()
+
+	m.Mutex.Lock()
+	if !m.Dirty {
+		m.Mutex.Unlock()
+		return nil
+	}
+
+	content, err := json.MarshalIndent(m.Data, "", "  ")
+	m.Dirty = false
+	m.SavedAt = time.Now()
+	m.Mutex.Unlock()
+
+	if err != nil {
+		m.markDirty()
+		return err
+	}
+
+	if err := m.write(content); err != nil {
+		// so it's written again next time
+		m.markDirty()
+		return err
+	}
+
+	return nil
+}
+
+// SaveIfOlderThan saves the state only if it was not saved within the interval,
+// so values changing all the time (like block heights) don't cause a write on every scrape.
+func (m *Manager) SaveIfOlderThan(interval time.Duration) error {
+	m.Mutex.Lock()
+	savedAt := m.SavedAt
+	m.Mutex.Unlock()
+
+	if time.Since(savedAt) < interval {
+		return nil
+	}
+
+	return m.Save()
+}
+
+func (m *Manager) markDirty() {
+	m.Mutex.Lock()
+	m.Dirty = true
+	m.Mutex.Unlock()
+}
+
+func (m *Manager) write(content []byte) error {
+	tmpFile, err := os.CreateTemp(filepath.Dir(m.Path), filepath.Base(m.Path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmpFile.Write(content); err != nil {
+		_ = tmpFile.Close()
+		_ = os.Remove(tmpFile.Name())
+		return err
+	}
+
+	if err := tmpFile.Close(); err != nil {
+		_ = os.Remove(tmpFile.Name())
+		return err
+	}
+
+	return os.Rename(tmpFile.Name(), m.Path)
+}
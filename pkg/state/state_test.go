@@ -0,0 +1,127 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateInMemory(t *testing.T) {
+	t.Parallel()
+
+	manager := NewManager("")
+	require.NoError(t, manager.Load())
+	require.NoError(t, manager.Set("key", map[string]int64{"value": 1}))
+	require.NoError(t, manager.Save())
+
+	var value map[string]int64
+	found, err := manager.Get("key", &value)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, int64(1), value["value"])
+
+	found, err = manager.Get("not-found", &value)
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestStateSaveAndLoad(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	manager := NewManager(path)
+	require.NoError(t, manager.Load())
+	require.NoError(t, manager.Set("key", map[string]int64{"value": 1}))
+	require.NoError(t, manager.Save())
+
+	loaded := NewManager(path)
+	require.NoError(t, loaded.Load())
+
+	var value map[string]int64
+	found, err := loaded.Get("key", &value)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, int64(1), value["value"])
+}
+
+func TestStateLoadInvalid(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "state.json")
+	require.NoError(t, os.WriteFile(path, []byte("invalid"), 0o600))
+
+	manager := NewManager(path)
+	require.Error(t, manager.Load())
+}
+
+func TestStateGetInvalid(t *testing.T) {
+	t.Parallel()
+
+	manager := NewManager("")
+	require.NoError(t, manager.Set("key", "string"))
+
+	var value map[string]int64
+	found, err := manager.Get("key", &value)
+	require.Error(t, err)
+	assert.False(t, found)
+}
+
+func TestStateSaveFailed(t *testing.T) {
+	t.Parallel()
+
+	manager := NewManager(filepath.Join(t.TempDir(), "not-existing", "state.json"))
+	require.NoError(t, manager.Set("key", "value"))
+	require.Error(t, manager.Save())
+
+	// kept dirty, so it's retried on the next save
+	assert.True(t, manager.Dirty)
+}
+
+func TestStateSaveOnlyChanged(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	manager := NewManager(path)
+	require.NoError(t, manager.Save())
+	assert.NoFileExists(t, path)
+
+	require.NoError(t, manager.Set("key", map[string]int64{"value": 1}))
+	require.NoError(t, manager.Save())
+	assert.FileExists(t, path)
+
+	// setting the same value does not write the file again
+	require.NoError(t, os.Remove(path))
+	require.NoError(t, manager.Set("key", map[string]int64{"value": 1}))
+	require.NoError(t, manager.Save())
+	assert.NoFileExists(t, path)
+
+	require.NoError(t, manager.Set("key", map[string]int64{"value": 2}))
+	require.NoError(t, manager.Save())
+	assert.FileExists(t, path)
+}
+
+func TestStateSaveIfOlderThan(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	manager := NewManager(path)
+	require.NoError(t, manager.Set("key", 1))
+	require.NoError(t, manager.SaveIfOlderThan(time.Hour))
+	assert.FileExists(t, path)
+
+	// saved recently, so it is not written again until the interval passes
+	require.NoError(t, os.Remove(path))
+	require.NoError(t, manager.Set("key", 2))
+	require.NoError(t, manager.SaveIfOlderThan(time.Hour))
+	assert.NoFileExists(t, path)
+
+	require.NoError(t, manager.SaveIfOlderThan(0))
+	assert.FileExists(t, path)
+}
@@ -0,0 +1,134 @@
+package status
+
+import (
+	"main/pkg/config"
+	"time"
+)
+
+type FailingChain struct {
+	Name          string     `json:"name"`
+	LCDEndpoint   string     `json:"lcd_endpoint"`
+	Reason        string     `json:"reason"`
+	LastScrape    *time.Time `json:"last_scrape,omitempty"`
+	FailedQueries []Query    `json:"failed_queries"`
+}
+
+type Readiness struct {
+	Ready           bool           `json:"ready"`
+	Reason          string         `json:"reason,omitempty"`
+	LastScrape      *time.Time     `json:"last_scrape,omitempty"`
+	HealthyChains   int            `json:"healthy_chains"`
+	TotalChains     int            `json:"total_chains"`
+	MinSuccessRatio float64        `json:"min_success_ratio"`
+	MaxAge          float64        `json:"max_age"`
+	FailingChains   []FailingChain `json:"failing_chains"`
+}
+
+// ChainScrape is the result of the latest scrape of a chain, either a full or a filtered one.
+type ChainScrape struct {
+	Time          time.Time
+	FailedQueries []Query
+}
+
+// GetChainScrapes returns the results of the chains queried in the snapshot. Chains
+// without any queries in it (like ones filtered out) are not included, as the snapshot
+// says nothing about them.
+func GetChainScrapes(snapshot *Snapshot) map[string]ChainScrape {
+	chainScrapes := make(map[string]ChainScrape)
+
+	for _, queryInfo := range snapshot.QueryInfos {
+		chainScrape, ok := chainScrapes[queryInfo.Chain]
+		if !ok {
+			chainScrape = ChainScrape{Time: snapshot.Time, FailedQueries: []Query{}}
+		}
+
+		// cached queries are not separate LCD queries, so they are not counted
+		if !queryInfo.Success && !queryInfo.Cached {
+			chainScrape.FailedQueries = append(chainScrape.FailedQueries, Query{
+				Chain:    queryInfo.Chain,
+				URL:      queryInfo.URL,
+				Duration: queryInfo.Duration.Seconds(),
+				Height:   queryInfo.Height,
+				Error:    queryInfo.Error,
+			})
+		}
+
+		chainScrapes[queryInfo.Chain] = chainScrape
+	}
+
+	return chainScrapes
+}
+
+// GetReadiness checks whether the exporter is ready to serve metrics: at least
+// MinSuccessRatio of chains should have had no failed queries in their latest scrape,
+// done no longer than MaxAge ago.
+func GetReadiness(
+	chainScrapes map[string]ChainScrape,
+	chains []config.Chain,
+	readinessConfig config.ReadinessConfig,
+	now time.Time,
+) Readiness {
+	maxAge := readinessConfig.GetMaxAge()
+
+	readiness := Readiness{
+		TotalChains:     len(chains),
+		MinSuccessRatio: readinessConfig.MinSuccessRatio,
+		MaxAge:          maxAge.Seconds(),
+		FailingChains:   []FailingChain{},
+	}
+
+	for _, chainScrape := range chainScrapes {
+		if readiness.LastScrape == nil || chainScrape.Time.After(*readiness.LastScrape) {
+			scrapeTime := chainScrape.Time
+			readiness.LastScrape = &scrapeTime
+		}
+	}
+
+	// nothing to query, so nothing to wait for
+	if len(chains) == 0 {
+		readiness.Ready = true
+		return readiness
+	}
+
+	if readiness.LastScrape == nil {
+		readiness.Reason = "no scrape completed yet"
+		return readiness
+	}
+
+	for _, chain := range chains {
+		failingChain := FailingChain{
+			Name:          chain.Name,
+			LCDEndpoint:   chain.GetDisplayLCDEndpoint(),
+			FailedQueries: []Query{},
+		}
+
+		chainScrape, found := chainScrapes[chain.Name]
+		if found {
+			scrapeTime := chainScrape.Time
+			failingChain.LastScrape = &scrapeTime
+		}
+
+		switch {
+		case !found:
+			failingChain.Reason = "not scraped yet"
+		case maxAge > 0 && now.Sub(chainScrape.Time) > maxAge:
+			failingChain.Reason = "not scraped recently"
+		case len(chainScrape.FailedQueries) > 0:
+			failingChain.Reason = "queries failed"
+			failingChain.FailedQueries = chainScrape.FailedQueries
+		default:
+			readiness.HealthyChains++
+			continue
+		}
+
+		readiness.FailingChains = append(readiness.FailingChains, failingChain)
+	}
+
+	ratio := float64(readiness.HealthyChains) / float64(len(chains))
+	readiness.Ready = ratio >= readinessConfig.MinSuccessRatio
+	if !readiness.Ready {
+		readiness.Reason = "not enough chains succeeded recently"
+	}
+
+	return readiness
+}
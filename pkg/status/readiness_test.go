@@ -0,0 +1,123 @@
+package status
+
+import (
+	configPkg "main/pkg/config"
+	"main/pkg/types"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getTestChains() []configPkg.Chain {
+	return []configPkg.Chain{
+		{Name: "chain", LCDEndpoint: "https://example.com"},
+		{Name: "chain2", LCDEndpoint: "https://example2.com"},
+	}
+}
+
+func TestGetReadinessNoScrape(t *testing.T) {
+	t.Parallel()
+
+	readiness := GetReadiness(
+		map[string]ChainScrape{},
+		getTestChains(),
+		configPkg.ReadinessConfig{MinSuccessRatio: 0.5},
+		time.Now(),
+	)
+	assert.False(t, readiness.Ready)
+	assert.Equal(t, "no scrape completed yet", readiness.Reason)
+	assert.Nil(t, readiness.LastScrape)
+}
+
+func TestGetReadinessNoChains(t *testing.T) {
+	t.Parallel()
+
+	readiness := GetReadiness(
+		map[string]ChainScrape{},
+		[]configPkg.Chain{},
+		configPkg.ReadinessConfig{MinSuccessRatio: 1},
+		time.Now(),
+	)
+	assert.True(t, readiness.Ready)
+}
+
+func TestGetReadinessPartialFailure(t *testing.T) {
+	t.Parallel()
+
+	snapshot := &Snapshot{
+		Time: time.Now(),
+		QueryInfos: []types.QueryInfo{
+			{Chain: "chain", URL: "https://example.com/balances/address", Success: true},
+			{Chain: "chain2", URL: "https://example2.com/balances/address", Error: "custom error"},
+			// cached queries are not separate LCD queries, so they are not counted
+			{Chain: "chain", URL: "https://example.com/balances/address", Cached: true, Error: "context canceled"},
+		},
+	}
+
+	chainScrapes := GetChainScrapes(snapshot)
+
+	readiness := GetReadiness(chainScrapes, getTestChains(), configPkg.ReadinessConfig{MinSuccessRatio: 0.5}, time.Now())
+	assert.True(t, readiness.Ready)
+	assert.Equal(t, 1, readiness.HealthyChains)
+	assert.Equal(t, 2, readiness.TotalChains)
+	require.Len(t, readiness.FailingChains, 1)
+	assert.Equal(t, "chain2", readiness.FailingChains[0].Name)
+	assert.Equal(t, "https://example2.com", readiness.FailingChains[0].LCDEndpoint)
+	assert.Equal(t, "queries failed", readiness.FailingChains[0].Reason)
+	require.Len(t, readiness.FailingChains[0].FailedQueries, 1)
+	assert.Equal(t, "custom error", readiness.FailingChains[0].FailedQueries[0].Error)
+
+	readiness = GetReadiness(chainScrapes, getTestChains(), configPkg.ReadinessConfig{MinSuccessRatio: 0.75}, time.Now())
+	assert.False(t, readiness.Ready)
+	assert.Equal(t, "not enough chains succeeded recently", readiness.Reason)
+}
+
+func TestGetReadinessMaxAge(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	chainScrapes := map[string]ChainScrape{
+		"chain":  {Time: now.Add(-time.Hour), FailedQueries: []Query{}},
+		"chain2": {Time: now.Add(-time.Minute), FailedQueries: []Query{}},
+	}
+
+	readiness := GetReadiness(chainScrapes, getTestChains(), configPkg.ReadinessConfig{
+		MinSuccessRatio: 1,
+		MaxAge:          "5m",
+	}, now)
+	assert.False(t, readiness.Ready)
+	assert.Equal(t, 1, readiness.HealthyChains)
+	require.Len(t, readiness.FailingChains, 1)
+	assert.Equal(t, "chain", readiness.FailingChains[0].Name)
+	assert.Equal(t, "not scraped recently", readiness.FailingChains[0].Reason)
+	require.NotNil(t, readiness.LastScrape)
+	assert.Equal(t, now.Add(-time.Minute), *readiness.LastScrape)
+
+	// zero max age means any scrape is recent enough
+	readiness = GetReadiness(chainScrapes, getTestChains(), configPkg.ReadinessConfig{
+		MinSuccessRatio: 1,
+		MaxAge:          "0s",
+	}, now)
+	assert.True(t, readiness.Ready)
+}
+
+func TestGetReadinessChainNotScraped(t *testing.T) {
+	t.Parallel()
+
+	// only one chain was scraped, like with a filtered scrape
+	chainScrapes := GetChainScrapes(&Snapshot{
+		Time: time.Now(),
+		QueryInfos: []types.QueryInfo{
+			{Chain: "chain2", URL: "https://example2.com/balances/address", Success: true},
+		},
+	})
+
+	readiness := GetReadiness(chainScrapes, getTestChains(), configPkg.ReadinessConfig{MinSuccessRatio: 0.5}, time.Now())
+	assert.True(t, readiness.Ready)
+	require.Len(t, readiness.FailingChains, 1)
+	assert.Equal(t, "chain", readiness.FailingChains[0].Name)
+	assert.Equal(t, "not scraped yet", readiness.FailingChains[0].Reason)
+	assert.Nil(t, readiness.FailingChains[0].LastScrape)
+}
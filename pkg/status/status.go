@@ -0,0 +1,266 @@
+package status
+
+import (
+	"main/pkg/types"
+	"sort"
+	"strings"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+const metricPrefix = "cosmos_wallets_exporter_"
+
+// Snapshot is the result of the latest scrape: all the metrics gathered
+// and all the queries done by queriers, with the time each chain was last queried at,
+// as results of filtered scrapes are merged into it (see Merge).
+type Snapshot struct {
+	Time       time.Time
+	Families   []*dto.MetricFamily
+	QueryInfos []types.QueryInfo
+	ChainTimes map[string]time.Time
+}
+
+// Merge returns a new snapshot with the results of a newer, possibly filtered, scrape
+// merged into this one: metrics with the same name and labels and queries to the same URL
+// are replaced, new ones are added, and the ones the newer scrape did not get are kept,
+// so scrapes split per chain, group or querier all keep the snapshot up to date.
+func (s *Snapshot) Merge(newer *Snapshot) *Snapshot {
+	merged := &Snapshot{
+		Time:       newer.Time,
+		Families:   make([]*dto.MetricFamily, 0, len(s.Families)),
+		QueryInfos: make([]types.QueryInfo, 0, len(s.QueryInfos)),
+		ChainTimes: make(map[string]time.Time, len(s.ChainTimes)),
+	}
+
+	newerFamilies := make(map[string]*dto.MetricFamily, len(newer.Families))
+	for _, family := range newer.Families {
+		newerFamilies[family.GetName()] = family
+	}
+
+	for _, family := range s.Families {
+		newerFamily, ok := newerFamilies[family.GetName()]
+		if !ok {
+			merged.Families = append(merged.Families, family)
+			continue
+		}
+
+		delete(newerFamilies, family.GetName())
+
+		newerMetrics := make(map[string]bool, len(newerFamily.GetMetric()))
+		for _, metric := range newerFamily.GetMetric() {
+			newerMetrics[getMetricKey(metric)] = true
+		}
+
+		metrics := make([]*dto.Metric, 0, len(family.GetMetric())+len(newerFamily.GetMetric()))
+		for _, metric := range family.GetMetric() {
+			if !newerMetrics[getMetricKey(metric)] {
+				metrics = append(metrics, metric)
+			}
+		}
+
+		merged.Families = append(merged.Families, &dto.MetricFamily{
+			Name:   family.Name,
+			Help:   family.Help,
+			Type:   family.Type,
+			Metric: append(metrics, newerFamily.GetMetric()...),
+		})
+	}
+
+	// families only the newer scrape has, in their original order
+	for _, family := range newer.Families {
+		if _, ok := newerFamilies[family.GetName()]; ok {
+			merged.Families = append(merged.Families, family)
+		}
+	}
+
+	newerQueries := make(map[string]bool, len(newer.QueryInfos))
+	for _, queryInfo := range newer.QueryInfos {
+		newerQueries[queryInfo.Chain+" "+queryInfo.URL] = true
+	}
+
+	for _, queryInfo := range s.QueryInfos {
+		if !newerQueries[queryInfo.Chain+" "+queryInfo.URL] {
+			merged.QueryInfos = append(merged.QueryInfos, queryInfo)
+		}
+	}
+
+	merged.QueryInfos = append(merged.QueryInfos, newer.QueryInfos...)
+
+	for chain, chainTime := range s.ChainTimes {
+		merged.ChainTimes[chain] = chainTime
+	}
+
+	for chain, chainTime := range newer.ChainTimes {
+		merged.ChainTimes[chain] = chainTime
+	}
+
+	return merged
+}
+
+// getMetricKey returns the labels of a metric as a string, to tell metrics of a family apart.
+func getMetricKey(metric *dto.Metric) string {
+	labels := make([]string, len(metric.GetLabel()))
+	for index, label := range metric.GetLabel() {
+		labels[index] = label.GetName() + "=" + label.GetValue()
+	}
+
+	sort.Strings(labels)
+	return strings.Join(labels, "\x00")
+}
+
+// Filter limits the status to a chain, group or address, empty fields match everything.
+// A metric matches the address filter if any of its address labels (like address
+// or supplier_operator_address) is equal to it.
+type Filter struct {
+	Chain   string
+	Group   string
+	Address string
+}
+
+type Metric struct {
+	Labels map[string]string `json:"labels"`
+	Value  float64           `json:"value"`
+}
+
+type Query struct {
+	Chain    string  `json:"chain"`
+	URL      string  `json:"url"`
+	Success  bool    `json:"success"`
+	Cached   bool    `json:"cached"`
+	Duration float64 `json:"duration"`
+	Height   int64   `json:"height,omitempty"`
+	Error    string  `json:"error,omitempty"`
+}
+
+type Response struct {
+	// Time is when the snapshot was last updated, by a full or a filtered scrape,
+	// ChainTimes is when each chain was last queried.
+	Time       time.Time            `json:"time"`
+	ChainTimes map[string]time.Time `json:"chain_times"`
+	// Metrics are keyed by the metric name without the common prefix,
+	// like balance, application_stake or price.
+	Metrics map[string][]Metric `json:"metrics"`
+	Queries []Query             `json:"queries"`
+}
+
+func NewResponse(snapshot *Snapshot, filter Filter) Response {
+	response := Response{
+		Time:       snapshot.Time,
+		ChainTimes: make(map[string]time.Time),
+		Metrics:    make(map[string][]Metric),
+		Queries:    []Query{},
+	}
+
+	for chain, chainTime := range snapshot.ChainTimes {
+		if filter.Chain == "" || filter.Chain == chain {
+			response.ChainTimes[chain] = chainTime
+		}
+	}
+
+	for _, family := range snapshot.Families {
+		name := strings.TrimPrefix(family.GetName(), metricPrefix)
+
+		for _, metric := range family.GetMetric() {
+			labels := make(map[string]string, len(metric.GetLabel()))
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+
+			if !filter.MatchesLabels(labels) {
+				continue
+			}
+
+			response.Metrics[name] = append(response.Metrics[name], Metric{
+				Labels: labels,
+				Value:  getValue(metric),
+			})
+		}
+	}
+
+	for _, queryInfo := range snapshot.QueryInfos {
+		if !filter.MatchesQuery(queryInfo) {
+			continue
+		}
+
+		response.Queries = append(response.Queries, Query{
+			Chain:    queryInfo.Chain,
+			URL:      queryInfo.URL,
+			Success:  queryInfo.Success,
+			Cached:   queryInfo.Cached,
+			Duration: queryInfo.Duration.Seconds(),
+			Height:   queryInfo.Height,
+			Error:    queryInfo.Error,
+		})
+	}
+
+	sort.SliceStable(response.Queries, func(i, j int) bool {
+		if response.Queries[i].Chain != response.Queries[j].Chain {
+			return response.Queries[i].Chain < response.Queries[j].Chain
+		}
+
+		return response.Queries[i].URL < response.Queries[j].URL
+	})
+
+	return response
+}
+
+func (f Filter) MatchesLabels(labels map[string]string) bool {
+	if f.Chain != "" && labels["chain"] != f.Chain {
+		return false
+	}
+
+	if f.Group != "" && labels["group"] != f.Group {
+		return false
+	}
+
+	if f.Address == "" {
+		return true
+	}
+
+	for name, value := range labels {
+		if (name == "address" || strings.HasSuffix(name, "_address")) && value == f.Address {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MatchesQuery checks whether a query is related to the filter. Queries do not have
+// a group, so the group filter is ignored, and the address is matched against
+// the URL path segments and query params.
+func (f Filter) MatchesQuery(queryInfo types.QueryInfo) bool {
+	if f.Chain != "" && queryInfo.Chain != f.Chain {
+		return false
+	}
+
+	if f.Address == "" {
+		return true
+	}
+
+	segments := strings.FieldsFunc(queryInfo.URL, func(r rune) bool {
+		return r == '/' || r == '?' || r == '&' || r == '='
+	})
+
+	for _, segment := range segments {
+		if segment == f.Address {
+			return true
+		}
+	}
+
+	return false
+}
+
+func getValue(metric *dto.Metric) float64 {
+	switch {
+	case metric.GetGauge() != nil:
+		return metric.GetGauge().GetValue()
+	case metric.GetCounter() != nil:
+		return metric.GetCounter().GetValue()
+	case metric.GetUntyped() != nil:
+		return metric.GetUntyped().GetValue()
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,170 @@
+package status
+
+import (
+	"errors"
+	"main/pkg/types"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getTestSnapshot(t *testing.T) *Snapshot {
+	t.Helper()
+
+	balanceGauge := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{Name: "cosmos_wallets_exporter_balance"},
+		[]string{"chain", "address", "name", "group", "denom"},
+	)
+	balanceGauge.With(prometheus.Labels{
+		"chain": "chain", "address": "address", "name": "name", "group": "group", "denom": "atom",
+	}).Set(1.5)
+	balanceGauge.With(prometheus.Labels{
+		"chain": "chain2", "address": "address2", "name": "name2", "group": "group2", "denom": "atom",
+	}).Set(2)
+
+	supplierGauge := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{Name: "cosmos_wallets_exporter_supplier_stake"},
+		[]string{"chain", "supplier_operator_address", "denom"},
+	)
+	supplierGauge.With(prometheus.Labels{
+		"chain": "chain", "supplier_operator_address": "address", "denom": "atom",
+	}).Set(100)
+
+	priceGauge := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{Name: "cosmos_wallets_exporter_price"},
+		[]string{"chain", "denom"},
+	)
+	priceGauge.With(prometheus.Labels{"chain": "chain", "denom": "atom"}).Set(10)
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(balanceGauge, supplierGauge, priceGauge)
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	return &Snapshot{
+		Time:     time.Now(),
+		Families: families,
+		QueryInfos: []types.QueryInfo{
+			{
+				Chain:    "chain2",
+				URL:      "https://example.com/balances/address2",
+				Success:  false,
+				Duration: time.Second,
+				Error:    errors.New("custom error").Error(),
+			},
+			{
+				Chain:    "chain",
+				URL:      "https://example.com/balances/address",
+				Success:  true,
+				Duration: 2 * time.Second,
+				Height:   100,
+			},
+		},
+	}
+}
+
+func TestNewResponseNoFilter(t *testing.T) {
+	t.Parallel()
+
+	response := NewResponse(getTestSnapshot(t), Filter{})
+	require.Len(t, response.Metrics["balance"], 2)
+	require.Len(t, response.Metrics["supplier_stake"], 1)
+	require.Len(t, response.Metrics["price"], 1)
+	assert.InDelta(t, 10, response.Metrics["price"][0].Value, 0.001)
+
+	require.Len(t, response.Queries, 2)
+	assert.Equal(t, "chain", response.Queries[0].Chain)
+	assert.InDelta(t, 2, response.Queries[0].Duration, 0.001)
+	assert.Equal(t, "custom error", response.Queries[1].Error)
+}
+
+func TestNewResponseFilterByChain(t *testing.T) {
+	t.Parallel()
+
+	response := NewResponse(getTestSnapshot(t), Filter{Chain: "chain2"})
+	require.Len(t, response.Metrics["balance"], 1)
+	assert.Equal(t, "address2", response.Metrics["balance"][0].Labels["address"])
+	assert.Empty(t, response.Metrics["price"])
+	require.Len(t, response.Queries, 1)
+}
+
+func TestNewResponseFilterByGroup(t *testing.T) {
+	t.Parallel()
+
+	response := NewResponse(getTestSnapshot(t), Filter{Group: "group"})
+	require.Len(t, response.Metrics["balance"], 1)
+	assert.Empty(t, response.Metrics["supplier_stake"])
+	require.Len(t, response.Queries, 2)
+}
+
+func TestNewResponseFilterByAddress(t *testing.T) {
+	t.Parallel()
+
+	response := NewResponse(getTestSnapshot(t), Filter{Address: "address"})
+	require.Len(t, response.Metrics["balance"], 1)
+	require.Len(t, response.Metrics["supplier_stake"], 1)
+	assert.Empty(t, response.Metrics["price"])
+	require.Len(t, response.Queries, 1)
+	assert.Equal(t, "chain", response.Queries[0].Chain)
+}
+
+func TestSnapshotMerge(t *testing.T) {
+	t.Parallel()
+
+	snapshot := getTestSnapshot(t)
+	snapshot.ChainTimes = map[string]time.Time{"chain": snapshot.Time, "chain2": snapshot.Time}
+
+	// a scrape filtered by chain2 and the balance querier
+	balanceGauge := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{Name: "cosmos_wallets_exporter_balance"},
+		[]string{"chain", "address", "name", "group", "denom"},
+	)
+	balanceGauge.With(prometheus.Labels{
+		"chain": "chain2", "address": "address2", "name": "name2", "group": "group2", "denom": "atom",
+	}).Set(3)
+	balanceGauge.With(prometheus.Labels{
+		"chain": "chain2", "address": "address3", "name": "name3", "group": "group2", "denom": "atom",
+	}).Set(4)
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(balanceGauge)
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	newerTime := snapshot.Time.Add(time.Minute)
+	merged := snapshot.Merge(&Snapshot{
+		Time:     newerTime,
+		Families: families,
+		QueryInfos: []types.QueryInfo{
+			{Chain: "chain2", URL: "https://example.com/balances/address2", Success: true},
+		},
+		ChainTimes: map[string]time.Time{"chain2": newerTime},
+	})
+
+	assert.Equal(t, newerTime, merged.Time)
+	assert.Equal(t, map[string]time.Time{"chain": snapshot.Time, "chain2": newerTime}, merged.ChainTimes)
+
+	response := NewResponse(merged, Filter{Chain: "chain2"})
+	require.Len(t, response.Metrics["balance"], 2)
+	assert.InDelta(t, 3, response.Metrics["balance"][0].Value, 0.001)
+	assert.InDelta(t, 4, response.Metrics["balance"][1].Value, 0.001)
+	require.Len(t, response.Queries, 1)
+	assert.True(t, response.Queries[0].Success)
+
+	// metrics of other chains and families are kept
+	response = NewResponse(merged, Filter{Chain: "chain"})
+	require.Len(t, response.Metrics["balance"], 1)
+	assert.InDelta(t, 1.5, response.Metrics["balance"][0].Value, 0.001)
+	assert.Len(t, response.Metrics["supplier_stake"], 1)
+	assert.Len(t, response.Metrics["price"], 1)
+
+	// the original snapshot is not changed, as it can be read concurrently
+	response = NewResponse(snapshot, Filter{Chain: "chain2"})
+	require.Len(t, response.Metrics["balance"], 1)
+	assert.InDelta(t, 2, response.Metrics["balance"][0].Value, 0.001)
+}
@@ -0,0 +1,81 @@
+package tendermint
+
+import (
+	"context"
+	"errors"
+	"main/pkg/types"
+	"sync"
+)
+
+type queryCacheContextKey struct{}
+
+// QueryCache deduplicates LCD queries within a single scrape: the first caller for a key
+// does the actual query, all the others wait for it and get the same result,
+// so an address monitored by multiple queriers is only fetched once.
+type QueryCache struct {
+	entries map[string]*queryCacheEntry
+	mutex   sync.Mutex
+}
+
+type queryCacheEntry struct {
+	done      chan struct{}
+	value     interface{}
+	queryInfo types.QueryInfo
+	err       error
+}
+
+func NewQueryCache() *QueryCache {
+	return &QueryCache{
+		entries: make(map[string]*queryCacheEntry),
+	}
+}
+
+func ContextWithQueryCache(ctx context.Context, cache *QueryCache) context.Context {
+	return context.WithValue(ctx, queryCacheContextKey{}, cache)
+}
+
+func QueryCacheFromContext(ctx context.Context) *QueryCache {
+	if ctx == nil {
+		return nil
+	}
+
+	cache, _ := ctx.Value(queryCacheContextKey{}).(*QueryCache)
+	return cache
+}
+
+// Do returns the result of query for the given key, running it only once per cache.
+// Results returned to all callers but the first one have QueryInfo.Cached set,
+// so they won't be counted as separate LCD queries.
+func (c *QueryCache) Do(
+	ctx context.Context,
+	key string,
+	query func() (interface{}, types.QueryInfo, error),
+) (interface{}, types.QueryInfo, error) {
+	c.mutex.Lock()
+	entry, found := c.entries[key]
+	if !found {
+		entry = &queryCacheEntry{done: make(chan struct{})}
+		c.entries[key] = entry
+	}
+	c.mutex.Unlock()
+
+	if !found {
+		entry.value, entry.queryInfo, entry.err = query()
+		close(entry.done)
+		return entry.value, entry.queryInfo, entry.err
+	}
+
+	select {
+	case <-entry.done:
+		queryInfo := entry.queryInfo
+		queryInfo.Cached = true
+		return entry.value, queryInfo, entry.err
+	case <-ctx.Done():
+		return nil, types.QueryInfo{
+			URL:      key,
+			Cached:   true,
+			TimedOut: errors.Is(ctx.Err(), context.DeadlineExceeded),
+			Error:    ctx.Err().Error(),
+		}, ctx.Err()
+	}
+}
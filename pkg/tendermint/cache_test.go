@@ -0,0 +1,82 @@
+package tendermint
+
+import (
+	"context"
+	"errors"
+	"main/pkg/types"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryCacheFromContext(t *testing.T) {
+	t.Parallel()
+
+	//nolint:staticcheck // testing nil context handling
+	assert.Nil(t, QueryCacheFromContext(nil))
+	assert.Nil(t, QueryCacheFromContext(context.Background()))
+
+	cache := NewQueryCache()
+	ctx := ContextWithQueryCache(context.Background(), cache)
+	assert.Equal(t, cache, QueryCacheFromContext(ctx))
+}
+
+func TestQueryCacheDeduplicates(t *testing.T) {
+	t.Parallel()
+
+	cache := NewQueryCache()
+
+	var calls atomic.Int32
+	var wg sync.WaitGroup
+
+	results := make([]types.QueryInfo, 10)
+
+	for index := range results {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+
+			value, queryInfo, err := cache.Do(context.Background(), "key", func() (interface{}, types.QueryInfo, error) {
+				calls.Add(1)
+				return "value", types.QueryInfo{URL: "url", Success: true}, nil
+			})
+
+			assert.NoError(t, err)
+			assert.Equal(t, "value", value)
+			results[index] = queryInfo
+		}(index)
+	}
+
+	wg.Wait()
+
+	require.Equal(t, int32(1), calls.Load())
+
+	notCached := 0
+	for _, queryInfo := range results {
+		assert.True(t, queryInfo.Success)
+		if !queryInfo.Cached {
+			notCached++
+		}
+	}
+
+	assert.Equal(t, 1, notCached)
+}
+
+func TestQueryCacheReturnsError(t *testing.T) {
+	t.Parallel()
+
+	cache := NewQueryCache()
+
+	for i := 0; i < 2; i++ {
+		_, queryInfo, err := cache.Do(context.Background(), "key", func() (interface{}, types.QueryInfo, error) {
+			return nil, types.QueryInfo{URL: "url"}, errors.New("custom error")
+		})
+
+		require.Error(t, err)
+		require.ErrorContains(t, err, "custom error")
+		assert.False(t, queryInfo.Success)
+	}
+}
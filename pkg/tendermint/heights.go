@@ -0,0 +1,145 @@
+package tendermint
+
+import (
+	"sync"
+	"time"
+)
+
+// HeightMaxAge is how long a tracked height is kept without being updated. If every response
+// is rejected for that long, like after a chain halt and reset or a switch to a node that is
+// behind, the node is trusted again instead of rejecting its responses forever.
+const HeightMaxAge = 10 * time.Minute
+
+type EndpointHeights struct {
+	Height    int64                `json:"height"`
+	UpdatedAt time.Time            `json:"updated_at"`
+	Keys      map[string]int64     `json:"keys"`
+	Updated   map[string]time.Time `json:"updated"`
+}
+
+// HeightTracker keeps the latest block heights seen per LCD endpoint and per query key
+// (usually a query type and an address), so responses from a node serving stale state
+// can be detected. Endpoints are expected to be redacted, as the heights are persisted.
+type HeightTracker struct {
+	Endpoints map[string]*EndpointHeights
+	Mutex     sync.RWMutex
+	Now       func() time.Time
+}
+
+func NewHeightTracker() *HeightTracker {
+	return &HeightTracker{
+		Endpoints: make(map[string]*EndpointHeights),
+		Now:       time.Now,
+	}
+}
+
+// Get returns the latest height seen for the key, or 0 if there's none
+// or it was not updated for longer than HeightMaxAge.
+func (t *HeightTracker) Get(endpoint string, key string) int64 {
+	t.Mutex.RLock()
+	defer t.Mutex.RUnlock()
+
+	heights, ok := t.Endpoints[endpoint]
+	if !ok {
+		return 0
+	}
+
+	if t.Now().Sub(heights.Updated[key]) > HeightMaxAge {
+		return 0
+	}
+
+	return heights.Keys[key]
+}
+
+func (t *HeightTracker) Update(endpoint string, key string, height int64) {
+	// nodes not returning the height header are not tracked
+	if height == 0 {
+		return
+	}
+
+	t.Mutex.Lock()
+	defer t.Mutex.Unlock()
+
+	now := t.Now()
+
+	heights, ok := t.Endpoints[endpoint]
+	if !ok {
+		heights = &EndpointHeights{}
+		t.Endpoints[endpoint] = heights
+	}
+
+	if heights.Keys == nil {
+		heights.Keys = make(map[string]int64)
+	}
+
+	if heights.Updated == nil {
+		heights.Updated = make(map[string]time.Time)
+	}
+
+	heights.Keys[key] = height
+	heights.Updated[key] = now
+
+	// the endpoint height is aged the same way, so it goes down after a chain reset
+	if height >= heights.Height || now.Sub(heights.UpdatedAt) > HeightMaxAge {
+		heights.Height = height
+		heights.UpdatedAt = now
+	}
+}
+
+// Snapshot returns a deep copy of the tracked heights, safe to read or serialize.
+// Heights older than HeightMaxAge are left out, as they are not used anymore,
+// so heights of removed wallets or endpoints are not kept forever.
+func (t *HeightTracker) Snapshot() map[string]EndpointHeights {
+	t.Mutex.RLock()
+	defer t.Mutex.RUnlock()
+
+	now := t.Now()
+	snapshot := make(map[string]EndpointHeights, len(t.Endpoints))
+
+	for endpoint, heights := range t.Endpoints {
+		if now.Sub(heights.UpdatedAt) > HeightMaxAge {
+			continue
+		}
+
+		copied := copyHeights(*heights)
+		for key := range copied.Keys {
+			if now.Sub(copied.Updated[key]) > HeightMaxAge {
+				delete(copied.Keys, key)
+				delete(copied.Updated, key)
+			}
+		}
+
+		snapshot[endpoint] = copied
+	}
+
+	return snapshot
+}
+
+func (t *HeightTracker) Restore(snapshot map[string]EndpointHeights) {
+	t.Mutex.Lock()
+	defer t.Mutex.Unlock()
+
+	for endpoint, heights := range snapshot {
+		restored := copyHeights(heights)
+		t.Endpoints[endpoint] = &restored
+	}
+}
+
+func copyHeights(heights EndpointHeights) EndpointHeights {
+	keys := make(map[string]int64, len(heights.Keys))
+	for key, height := range heights.Keys {
+		keys[key] = height
+	}
+
+	updated := make(map[string]time.Time, len(heights.Updated))
+	for key, updatedAt := range heights.Updated {
+		updated[key] = updatedAt
+	}
+
+	return EndpointHeights{
+		Height:    heights.Height,
+		UpdatedAt: heights.UpdatedAt,
+		Keys:      keys,
+		Updated:   updated,
+	}
+}
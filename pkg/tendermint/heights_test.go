@@ -0,0 +1,72 @@
+package tendermint
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeightTrackerUpdate(t *testing.T) {
+	t.Parallel()
+
+	tracker := NewHeightTracker()
+	assert.Zero(t, tracker.Get("endpoint", "address"))
+
+	tracker.Update("endpoint", "address", 0)
+	assert.Empty(t, tracker.Snapshot())
+
+	tracker.Update("endpoint", "address", 100)
+	tracker.Update("endpoint", "other", 90)
+	assert.Equal(t, int64(100), tracker.Get("endpoint", "address"))
+	assert.Equal(t, int64(90), tracker.Get("endpoint", "other"))
+	assert.Zero(t, tracker.Get("other-endpoint", "address"))
+
+	snapshot := tracker.Snapshot()
+	assert.Equal(t, int64(100), snapshot["endpoint"].Height)
+	assert.Len(t, snapshot["endpoint"].Keys, 2)
+
+	// snapshot is a copy and is not affected by further updates
+	tracker.Update("endpoint", "address", 110)
+	assert.Equal(t, int64(100), snapshot["endpoint"].Keys["address"])
+
+	restored := NewHeightTracker()
+	restored.Restore(snapshot)
+	assert.Equal(t, int64(100), restored.Get("endpoint", "address"))
+}
+
+func TestHeightTrackerMaxAge(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	tracker := NewHeightTracker()
+	tracker.Now = func() time.Time { return now }
+
+	tracker.Update("endpoint", "address", 100)
+	tracker.Update("endpoint", "other", 100)
+
+	// responses of a reset chain are rejected for a while, so heights are not updated
+	now = now.Add(HeightMaxAge / 2)
+	tracker.Update("endpoint", "other", 100)
+	assert.Equal(t, int64(100), tracker.Get("endpoint", "address"))
+
+	// then the height is forgotten, so the lower heights are accepted again
+	now = now.Add(HeightMaxAge + time.Second)
+	assert.Zero(t, tracker.Get("endpoint", "address"))
+
+	tracker.Update("endpoint", "address", 10)
+	assert.Equal(t, int64(10), tracker.Get("endpoint", "address"))
+
+	snapshot := tracker.Snapshot()
+	assert.Equal(t, int64(10), snapshot["endpoint"].Height)
+	assert.Equal(t, map[string]int64{"address": 10}, snapshot["endpoint"].Keys)
+
+	// heights restored without update times, like from older state files, are forgotten
+	restored := NewHeightTracker()
+	restored.Restore(map[string]EndpointHeights{
+		"endpoint": {Height: 100, Keys: map[string]int64{"address": 100}},
+	})
+	assert.Zero(t, restored.Get("endpoint", "address"))
+	assert.Empty(t, restored.Snapshot())
+}
@@ -0,0 +1,80 @@
+package tendermint
+
+import (
+	"main/pkg/config"
+	"sync"
+
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Registry holds a single RPC per chain, shared across all queriers,
+// so per-chain state like the last queried heights is not duplicated.
+type Registry struct {
+	Logger zerolog.Logger
+	Tracer trace.Tracer
+
+	RPCs  map[string]*RPC
+	Mutex sync.Mutex
+}
+
+func NewRegistry(appConfig *config.Config, logger zerolog.Logger, tracer trace.Tracer) *Registry {
+	registry := &Registry{
+		Logger: logger,
+		Tracer: tracer,
+		RPCs:   make(map[string]*RPC, len(appConfig.Chains)),
+	}
+
+	for _, chain := range appConfig.Chains {
+		registry.RPCs[chain.Name] = NewRPC(chain, logger, tracer)
+	}
+
+	return registry
+}
+
+// Get returns an RPC for a chain, creating it if it's not yet present.
+func (r *Registry) Get(chain config.Chain) *RPC {
+	r.Mutex.Lock()
+	defer r.Mutex.Unlock()
+
+	existing, ok := r.RPCs[chain.Name]
+	if ok && existing.URL == chain.LCDEndpoint {
+		return existing
+	}
+
+	rpc := NewRPC(chain, r.Logger, r.Tracer)
+
+	// heights are tracked per endpoint, so they can be safely reused
+	if ok {
+		rpc.Heights = existing.Heights
+	}
+
+	r.RPCs[chain.Name] = rpc
+	return rpc
+}
+
+// GetHeights returns the tracked heights for all chains, keyed by chain name.
+func (r *Registry) GetHeights() map[string]map[string]EndpointHeights {
+	r.Mutex.Lock()
+	defer r.Mutex.Unlock()
+
+	heights := make(map[string]map[string]EndpointHeights, len(r.RPCs))
+	for chain, rpc := range r.RPCs {
+		heights[chain] = rpc.Heights.Snapshot()
+	}
+
+	return heights
+}
+
+// RestoreHeights loads previously tracked heights, for example from a state file.
+// Chains that are no longer configured are ignored.
+func (r *Registry) RestoreHeights(heights map[string]map[string]EndpointHeights) {
+	r.Mutex.Lock()
+	defer r.Mutex.Unlock()
+
+	for chain, chainHeights := range heights {
+		if rpc, ok := r.RPCs[chain]; ok {
+			rpc.Heights.Restore(chainHeights)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package tendermint
+
+import (
+	"main/pkg/config"
+	loggerPkg "main/pkg/logger"
+	"main/pkg/tracing"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistryGet(t *testing.T) {
+	t.Parallel()
+
+	chain := config.Chain{Name: "chain", LCDEndpoint: "https://example.com"}
+	appConfig := &config.Config{Chains: []config.Chain{chain}}
+
+	registry := NewRegistry(appConfig, *loggerPkg.GetNopLogger(), tracing.InitNoopTracer())
+	assert.Len(t, registry.RPCs, 1)
+
+	rpc := registry.Get(chain)
+	assert.Equal(t, "https://example.com", rpc.URL)
+	assert.Same(t, rpc, registry.Get(chain))
+
+	other := registry.Get(config.Chain{Name: "other", LCDEndpoint: "https://other.com"})
+	assert.NotSame(t, rpc, other)
+	assert.Len(t, registry.RPCs, 2)
+
+	changed := registry.Get(config.Chain{Name: "chain", LCDEndpoint: "https://changed.com"})
+	assert.NotSame(t, rpc, changed)
+	assert.Equal(t, "https://changed.com", changed.URL)
+}
+
+func TestRegistryHeights(t *testing.T) {
+	t.Parallel()
+
+	chain := config.Chain{Name: "chain", LCDEndpoint: "https://example.com"}
+	appConfig := &config.Config{Chains: []config.Chain{chain}}
+	now := time.Now()
+
+	registry := NewRegistry(appConfig, *loggerPkg.GetNopLogger(), tracing.InitNoopTracer())
+	registry.RestoreHeights(map[string]map[string]EndpointHeights{
+		"chain": {
+			"https://example.com": {
+				Height:    100,
+				UpdatedAt: now,
+				Keys:      map[string]int64{"balance:address": 90},
+				Updated:   map[string]time.Time{"balance:address": now},
+			},
+		},
+		"removed": {
+			"https://removed.com": {Height: 100, Keys: map[string]int64{"balance:address": 90}},
+		},
+	})
+
+	heights := registry.GetHeights()
+	assert.Len(t, heights, 1)
+	assert.Equal(t, int64(100), heights["chain"]["https://example.com"].Height)
+
+	changed := registry.Get(config.Chain{Name: "chain", LCDEndpoint: "https://changed.com"})
+	assert.Equal(t, int64(90), changed.Heights.Get("https://example.com", "balance:address"))
+	assert.Zero(t, changed.Heights.Get("https://changed.com", "balance:address"))
+}
+
+func TestRegistryHeightsSecretEndpoint(t *testing.T) {
+	t.Parallel()
+
+	chain := config.Chain{Name: "chain", LCDEndpoint: "https://example.com/api-key", SecretLCDEndpoint: true}
+	appConfig := &config.Config{Chains: []config.Chain{chain}}
+
+	registry := NewRegistry(appConfig, *loggerPkg.GetNopLogger(), tracing.InitNoopTracer())
+	registry.Get(chain).Heights.Update(registry.Get(chain).Endpoint, "balance:address", 100)
+
+	// heights are persisted, so they are stored by the redacted endpoint
+	heights := registry.GetHeights()
+	assert.Contains(t, heights["chain"], "https://example.com/<redacted>")
+	assert.NotContains(t, heights["chain"], "https://example.com/api-key")
+}
@@ -2,12 +2,15 @@ package tendermint
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"main/pkg/config"
 	"main/pkg/http"
 	"main/pkg/types"
-	"main/pkg/utils"
-	"sync"
+	neturl "net/url"
+	"strconv"
+	"strings"
 
 	"go.opentelemetry.io/otel/trace"
 
@@ -16,75 +19,210 @@ import (
 
 type RPC struct {
 	Client *http.Client
+	Chain  string
 	URL    string
-	Logger zerolog.Logger
-	Tracer trace.Tracer
+	// the redacted URL, heights are tracked by it, as they are persisted
+	Endpoint string
+	Logger   zerolog.Logger
+	Tracer   trace.Tracer
 
-	LastQueryHeight map[string]int64
-	Mutex           sync.Mutex
+	Heights *HeightTracker
 }
 
 func NewRPC(chain config.Chain, logger zerolog.Logger, tracer trace.Tracer) *RPC {
 	return &RPC{
-		Client:          http.NewClient(logger, chain.Name, tracer),
-		URL:             chain.LCDEndpoint,
-		Logger:          logger.With().Str("component", "rpc").Logger(),
-		LastQueryHeight: make(map[string]int64),
-		Tracer:          tracer,
+		Client:   http.NewClient(logger, chain.Name, tracer).WithRedaction(chain.LCDEndpoint, chain.GetDisplayLCDEndpoint()),
+		Chain:    chain.Name,
+		URL:      chain.LCDEndpoint,
+		Endpoint: chain.GetDisplayLCDEndpoint(),
+		Logger:   logger.With().Str("component", "rpc").Logger(),
+		Heights:  NewHeightTracker(),
+		Tracer:   tracer,
 	}
 }
 
 func (rpc *RPC) GetWalletBalances(address string, ctx context.Context) (*types.BalanceResponse, types.QueryInfo, error) {
-	lastHeight, _ := rpc.LastQueryHeight[address]
-
 	url := fmt.Sprintf(
 		"%s/cosmos/bank/v1beta1/balances/%s",
 		rpc.URL,
 		address,
 	)
 
-	var response *types.BalanceResponse
-	queryInfo, header, err := rpc.Client.Get(url, &response, types.HTTPPredicateCheckHeightAfter(lastHeight), ctx)
+	query := func() (interface{}, types.QueryInfo, error) {
+		var response *types.BalanceResponse
+		queryInfo, err := rpc.Get(url, "balance:"+address, &response, ctx)
+		return response, queryInfo, err
+	}
+
+	// the same address can be a wallet, an application, a supplier and a rev share
+	// address at the same time, so it's only queried once per scrape
+	value, queryInfo, err := rpc.DoCached(url, query, ctx)
+	if err != nil {
+		return nil, queryInfo, err
+	}
+
+	response, _ := value.(*types.BalanceResponse)
+	return response, queryInfo, nil
+}
+
+// DoCached runs the query only once per scrape for the same URL, if the context has a query cache.
+func (rpc *RPC) DoCached(
+	url string,
+	query func() (interface{}, types.QueryInfo, error),
+	ctx context.Context,
+) (interface{}, types.QueryInfo, error) {
+	if cache := QueryCacheFromContext(ctx); cache != nil {
+		return cache.Do(ctx, rpc.Chain+":"+url, query)
+	}
+
+	return query()
+}
+
+// GetCW20Balance queries a CW20 token balance of a wallet via the contract smart query.
+func (rpc *RPC) GetCW20Balance(
+	contract string,
+	address string,
+	ctx context.Context,
+) (*types.CW20BalanceResponse, types.QueryInfo, error) {
+	query, err := json.Marshal(map[string]interface{}{
+		"balance": map[string]string{"address": address},
+	})
+	if err != nil {
+		return nil, types.QueryInfo{}, err
+	}
+
+	url := fmt.Sprintf(
+		"%s/cosmwasm/wasm/v1/contract/%s/smart/%s",
+		rpc.URL,
+		contract,
+		base64.URLEncoding.EncodeToString(query),
+	)
+
+	var response *types.CW20BalanceResponse
+	queryInfo, err := rpc.Get(url, "cw20:"+contract+":"+address, &response, ctx)
 	if err != nil {
 		return nil, queryInfo, err
 	}
 
-	newLastHeight, _ := utils.GetBlockHeightFromHeader(header)
+	return response, queryInfo, nil
+}
+
+func (rpc *RPC) GetAccount(address string, ctx context.Context) (*types.AccountResponse, types.QueryInfo, error) {
+	url := fmt.Sprintf(
+		"%s/cosmos/auth/v1beta1/accounts/%s",
+		rpc.URL,
+		address,
+	)
+
+	query := func() (interface{}, types.QueryInfo, error) {
+		var response *types.AccountResponse
+		queryInfo, err := rpc.Get(url, "account:"+address, &response, ctx)
+		return response, queryInfo, err
+	}
 
-	rpc.Mutex.Lock()
-	rpc.LastQueryHeight[address] = newLastHeight
-	rpc.Mutex.Unlock()
+	// accounts are used by both vesting and activity queriers
+	value, queryInfo, err := rpc.DoCached(url, query, ctx)
+	if err != nil {
+		return nil, queryInfo, err
+	}
 
+	response, _ := value.(*types.AccountResponse)
 	return response, queryInfo, nil
 }
 
-func (rpc *RPC) GetApplicationStake(address string, ctx context.Context) (*types.ApplicationResponse, types.QueryInfo, error) {
-	lastHeight, _ := rpc.LastQueryHeight[address]
+// GetLastSentTx returns the latest transaction sent by the address, along with the total count
+// of them, if the node has the tx indexer enabled. Both events and query params are set,
+// as older versions only support the former, and newer ones only the latter.
+func (rpc *RPC) GetLastSentTx(address string, ctx context.Context) (*types.TxsResponse, types.QueryInfo, error) {
+	event := fmt.Sprintf("message.sender='%s'", address)
 
 	url := fmt.Sprintf(
-		"%s/pokt-network/poktroll/application/application/%s",
+		"%s/cosmos/tx/v1beta1/txs?events=%s&query=%s&order_by=ORDER_BY_DESC&limit=1&page=1",
+		rpc.URL,
+		neturl.QueryEscape(event),
+		neturl.QueryEscape(event),
+	)
+
+	var response *types.TxsResponse
+	queryInfo, err := rpc.Get(url, "txs:"+address, &response, ctx)
+	if err != nil {
+		return nil, queryInfo, err
+	}
+
+	return response, queryInfo, nil
+}
+
+// GetTransfers returns a page of transactions with transfer events where the address
+// has the given role (sender or recipient), within the given heights range, oldest first.
+func (rpc *RPC) GetTransfers(
+	address string,
+	role string,
+	fromHeight int64,
+	toHeight int64,
+	page int,
+	limit int,
+	ctx context.Context,
+) (*types.TxsResponse, types.QueryInfo, error) {
+	events := []string{
+		fmt.Sprintf("transfer.%s='%s'", role, address),
+		fmt.Sprintf("tx.height>=%d", fromHeight),
+		fmt.Sprintf("tx.height<=%d", toHeight),
+	}
+
+	params := neturl.Values{}
+	for _, event := range events {
+		params.Add("events", event)
+	}
+
+	params.Set("query", strings.Join(events, " AND "))
+	params.Set("order_by", "ORDER_BY_ASC")
+	params.Set("page", strconv.Itoa(page))
+	params.Set("limit", strconv.Itoa(limit))
+
+	url := rpc.URL + "/cosmos/tx/v1beta1/txs?" + params.Encode()
+
+	var response *types.TxsResponse
+	queryInfo, err := rpc.Get(url, "transfers:"+role+":"+address, &response, ctx)
+	if err != nil {
+		return nil, queryInfo, err
+	}
+
+	return response, queryInfo, nil
+}
+
+func (rpc *RPC) GetSpendableBalances(address string, ctx context.Context) (*types.BalanceResponse, types.QueryInfo, error) {
+	url := fmt.Sprintf(
+		"%s/cosmos/bank/v1beta1/spendable_balances/%s",
 		rpc.URL,
 		address,
 	)
 
-	var response *types.ApplicationResponse
-	queryInfo, header, err := rpc.Client.Get(url, &response, types.HTTPPredicateCheckHeightAfter(lastHeight), ctx)
+	var response *types.BalanceResponse
+	queryInfo, err := rpc.Get(url, "spendable_balances:"+address, &response, ctx)
 	if err != nil {
 		return nil, queryInfo, err
 	}
 
-	newLastHeight, _ := utils.GetBlockHeightFromHeader(header)
+	return response, queryInfo, nil
+}
+
+func (rpc *RPC) GetApplicationStake(address string, ctx context.Context) (*types.ApplicationResponse, types.QueryInfo, error) {
+	url := fmt.Sprintf(
+		"%s/pokt-network/poktroll/application/application/%s",
+		rpc.URL,
+		address,
+	)
 
-	rpc.Mutex.Lock()
-	rpc.LastQueryHeight[address] = newLastHeight
-	rpc.Mutex.Unlock()
+	var response *types.ApplicationResponse
+	queryInfo, err := rpc.Get(url, "application:"+address, &response, ctx)
+	if err != nil {
+		return nil, queryInfo, err
+	}
 
 	return response, queryInfo, nil
 }
 
 func (rpc *RPC) GetSupplierStake(address string, ctx context.Context) (*types.SupplierResponse, types.QueryInfo, error) {
-	lastHeight, _ := rpc.LastQueryHeight[address]
-
 	url := fmt.Sprintf(
 		"%s/pokt-network/poktroll/supplier/supplier/%s",
 		rpc.URL,
@@ -92,16 +230,190 @@ func (rpc *RPC) GetSupplierStake(address string, ctx context.Context) (*types.Su
 	)
 
 	var response *types.SupplierResponse
-	queryInfo, header, err := rpc.Client.Get(url, &response, types.HTTPPredicateCheckHeightAfter(lastHeight), ctx)
+	queryInfo, err := rpc.Get(url, "supplier:"+address, &response, ctx)
+	if err != nil {
+		return nil, queryInfo, err
+	}
+
+	return response, queryInfo, nil
+}
+
+func (rpc *RPC) GetSharedParams(ctx context.Context) (*types.SharedParamsResponse, types.QueryInfo, error) {
+	url := rpc.URL + "/pokt-network/poktroll/shared/params"
+
+	var response *types.SharedParamsResponse
+	queryInfo, err := rpc.Get(url, "shared_params", &response, ctx)
+	if err != nil {
+		return nil, queryInfo, err
+	}
+
+	return response, queryInfo, nil
+}
+
+func (rpc *RPC) GetSessionParams(ctx context.Context) (*types.SessionParamsResponse, types.QueryInfo, error) {
+	url := rpc.URL + "/pokt-network/poktroll/session/params"
+
+	var response *types.SessionParamsResponse
+	queryInfo, err := rpc.Get(url, "session_params", &response, ctx)
+	if err != nil {
+		return nil, queryInfo, err
+	}
+
+	return response, queryInfo, nil
+}
+
+func (rpc *RPC) GetProofParams(ctx context.Context) (*types.ProofParamsResponse, types.QueryInfo, error) {
+	url := rpc.URL + "/pokt-network/poktroll/proof/params"
+
+	var response *types.ProofParamsResponse
+	queryInfo, err := rpc.Get(url, "proof_params", &response, ctx)
+	if err != nil {
+		return nil, queryInfo, err
+	}
+
+	return response, queryInfo, nil
+}
+
+func (rpc *RPC) GetTokenomicsParams(ctx context.Context) (*types.TokenomicsParamsResponse, types.QueryInfo, error) {
+	url := rpc.URL + "/pokt-network/poktroll/tokenomics/params"
+
+	var response *types.TokenomicsParamsResponse
+	queryInfo, err := rpc.Get(url, "tokenomics_params", &response, ctx)
+	if err != nil {
+		return nil, queryInfo, err
+	}
+
+	return response, queryInfo, nil
+}
+
+func (rpc *RPC) GetRelayMiningDifficulty(serviceID string, ctx context.Context) (*types.RelayMiningDifficultyResponse, types.QueryInfo, error) {
+	url := fmt.Sprintf(
+		"%s/pokt-network/poktroll/service/relay_mining_difficulty/%s",
+		rpc.URL,
+		serviceID,
+	)
+
+	var response *types.RelayMiningDifficultyResponse
+	queryInfo, err := rpc.Get(url, "relay_mining_difficulty:"+serviceID, &response, ctx)
+	if err != nil {
+		return nil, queryInfo, err
+	}
+
+	return response, queryInfo, nil
+}
+
+// GetLatestBlock returns the latest block of the node, even if it's lower than one seen
+// before, so a node lagging behind can be told apart from one that is not responding.
+func (rpc *RPC) GetLatestBlock(ctx context.Context) (*types.LatestBlockResponse, types.QueryInfo, error) {
+	url := rpc.URL + "/cosmos/base/tendermint/v1beta1/blocks/latest"
+
+	var response *types.LatestBlockResponse
+	queryInfo, err := rpc.GetUnchecked(url, &response, ctx)
 	if err != nil {
 		return nil, queryInfo, err
 	}
 
-	newLastHeight, _ := utils.GetBlockHeightFromHeader(header)
+	return response, queryInfo, nil
+}
+
+func (rpc *RPC) GetSyncing(ctx context.Context) (*types.SyncingResponse, types.QueryInfo, error) {
+	url := rpc.URL + "/cosmos/base/tendermint/v1beta1/syncing"
 
-	rpc.Mutex.Lock()
-	rpc.LastQueryHeight[address] = newLastHeight
-	rpc.Mutex.Unlock()
+	var response *types.SyncingResponse
+	queryInfo, err := rpc.GetUnchecked(url, &response, ctx)
+	if err != nil {
+		return nil, queryInfo, err
+	}
 
 	return response, queryInfo, nil
 }
+
+func (rpc *RPC) GetSupplierClaims(address string, ctx context.Context) ([]types.Claim, []types.QueryInfo, error) {
+	var claims []types.Claim
+	var queryInfos []types.QueryInfo
+
+	nextKey := ""
+
+	for {
+		url := fmt.Sprintf(
+			"%s/pokt-network/poktroll/proof/claim?supplier_operator_address=%s",
+			rpc.URL,
+			address,
+		)
+		if nextKey != "" {
+			url += "&pagination.key=" + neturl.QueryEscape(nextKey)
+		}
+
+		var response *types.ClaimsResponse
+		queryInfo, err := rpc.Get(url, "claims:"+address, &response, ctx)
+		queryInfos = append(queryInfos, queryInfo)
+		if err != nil {
+			return nil, queryInfos, err
+		}
+
+		claims = append(claims, response.Claims...)
+
+		if response.Pagination.NextKey == "" {
+			return claims, queryInfos, nil
+		}
+
+		nextKey = response.Pagination.NextKey
+	}
+}
+
+func (rpc *RPC) GetSupplierProofs(address string, ctx context.Context) ([]types.Proof, []types.QueryInfo, error) {
+	var proofs []types.Proof
+	var queryInfos []types.QueryInfo
+
+	nextKey := ""
+
+	for {
+		url := fmt.Sprintf(
+			"%s/pokt-network/poktroll/proof/proof?supplier_operator_address=%s",
+			rpc.URL,
+			address,
+		)
+		if nextKey != "" {
+			url += "&pagination.key=" + neturl.QueryEscape(nextKey)
+		}
+
+		var response *types.ProofsResponse
+		queryInfo, err := rpc.Get(url, "proofs:"+address, &response, ctx)
+		queryInfos = append(queryInfos, queryInfo)
+		if err != nil {
+			return nil, queryInfos, err
+		}
+
+		proofs = append(proofs, response.Proofs...)
+
+		if response.Pagination.NextKey == "" {
+			return proofs, queryInfos, nil
+		}
+
+		nextKey = response.Pagination.NextKey
+	}
+}
+
+// GetUnchecked queries the node without rejecting responses with a height lower than
+// the previous one, for queries that are expected to report the node's own status.
+func (rpc *RPC) GetUnchecked(url string, target interface{}, ctx context.Context) (types.QueryInfo, error) {
+	queryInfo, _, err := rpc.Client.Get(url, target, types.HTTPPredicateAlwaysPass(), ctx)
+	return queryInfo, err
+}
+
+// Get queries the LCD and makes sure the response is not older than the previous
+// one for the same key, so a lagging node behind a load balancer won't make
+// the metrics jump back in time. Keys start with the query type, followed by
+// a colon and the address or other params, if any.
+func (rpc *RPC) Get(url string, key string, target interface{}, ctx context.Context) (types.QueryInfo, error) {
+	lastHeight := rpc.Heights.Get(rpc.Endpoint, key)
+
+	queryInfo, _, err := rpc.Client.Get(url, target, types.HTTPPredicateCheckHeightAfter(lastHeight), ctx)
+	if err != nil {
+		return queryInfo, err
+	}
+
+	rpc.Heights.Update(rpc.Endpoint, key, queryInfo.Height)
+
+	return queryInfo, nil
+}
@@ -0,0 +1,652 @@
+package transfers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"main/pkg/config"
+	"main/pkg/state"
+	"main/pkg/tendermint"
+	"main/pkg/types"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	cosmosMath "cosmossdk.io/math"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	RoleSender    = "sender"
+	RoleRecipient = "recipient"
+
+	// CounterpartyGroupExternal is the counterparty group of addresses not listed in the config.
+	CounterpartyGroupExternal = "external"
+
+	// StateKey is the state file key the cursors are stored under.
+	StateKey = "transfers"
+)
+
+var coinRegexp = regexp.MustCompile(`^([0-9]+(?:\.[0-9]+)?)([a-zA-Z][a-zA-Z0-9/:._-]*)$`)
+
+// Transfer is a single transfer from a transfer event.
+type Transfer struct {
+	Sender    string
+	Recipient string
+	Amount    types.Balances
+}
+
+// Key identifies a counter of tokens a wallet sent to or received from a counterparty group.
+type Key struct {
+	Chain             string
+	Address           string
+	Denom             string
+	CounterpartyGroup string
+}
+
+// Indexer pages through transfer events of wallets in the background and counts
+// tokens they sent and received since the exporter start. The last indexed height
+// of each wallet is kept as a cursor, which is saved to the state file after each run,
+// so transfers are not counted twice after a restart. On the first run for a wallet,
+// it starts from the latest height, without indexing the past transfers.
+type Indexer struct {
+	GetConfig func() *config.Config
+	RPCs      *tendermint.Registry
+	State     *state.Manager
+	Logger    zerolog.Logger
+	Tracer    trace.Tracer
+
+	// chain -> address -> last indexed height
+	Cursors  map[string]map[string]int64
+	Sent     map[Key]float64
+	Received map[Key]float64
+	Mutex    sync.Mutex
+	Stopped  chan struct{}
+}
+
+func NewIndexer(
+	getConfig func() *config.Config,
+	rpcs *tendermint.Registry,
+	stateManager *state.Manager,
+	logger zerolog.Logger,
+	tracer trace.Tracer,
+) *Indexer {
+	return &Indexer{
+		GetConfig: getConfig,
+		RPCs:      rpcs,
+		State:     stateManager,
+		Logger:    logger.With().Str("component", "transfers").Logger(),
+		Tracer:    tracer,
+		Cursors:   make(map[string]map[string]int64),
+		Sent:      make(map[Key]float64),
+		Received:  make(map[Key]float64),
+		Stopped:   make(chan struct{}),
+	}
+}
+
+// Start indexes transfers every interval until Stop is called.
+// Does nothing if no chain has transfer metrics enabled.
+func (i *Indexer) Start() {
+	appConfig := i.GetConfig()
+	if !appConfig.HasTransferMetrics() {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(appConfig.TransfersConfig.GetInterval())
+		defer ticker.Stop()
+
+		i.Run(context.Background())
+
+		for {
+			select {
+			case <-i.Stopped:
+				return
+			case <-ticker.C:
+				i.Run(context.Background())
+			}
+		}
+	}()
+}
+
+func (i *Indexer) Stop() {
+	close(i.Stopped)
+}
+
+// LoadCursors restores the cursors from the state file.
+func (i *Indexer) LoadCursors() error {
+	var cursors map[string]map[string]int64
+	if _, err := i.State.Get(StateKey, &cursors); err != nil {
+		return err
+	}
+
+	i.RestoreCursors(cursors)
+	return nil
+}
+
+// SaveCursors writes the cursors to the state file right away, so the heights
+// indexed since the previous save are not counted again if the app crashes.
+func (i *Indexer) SaveCursors() {
+	if err := i.State.Set(StateKey, i.GetCursors()); err != nil {
+		i.Logger.Error().Err(err).Msg("Could not serialize transfers cursors")
+		return
+	}
+
+	if err := i.State.Save(); err != nil {
+		i.Logger.Error().Err(err).Msg("Could not save transfers cursors")
+	}
+}
+
+// RestoreCursors sets the cursors loaded from the state file.
+func (i *Indexer) RestoreCursors(cursors map[string]map[string]int64) {
+	i.Mutex.Lock()
+	defer i.Mutex.Unlock()
+
+	for chain, addresses := range cursors {
+		i.Cursors[chain] = make(map[string]int64, len(addresses))
+		for address, height := range addresses {
+			i.Cursors[chain][address] = height
+		}
+	}
+}
+
+// GetCursors returns a copy of the cursors to be saved in the state file.
+func (i *Indexer) GetCursors() map[string]map[string]int64 {
+	i.Mutex.Lock()
+	defer i.Mutex.Unlock()
+
+	cursors := make(map[string]map[string]int64, len(i.Cursors))
+	for chain, addresses := range i.Cursors {
+		cursors[chain] = make(map[string]int64, len(addresses))
+		for address, height := range addresses {
+			cursors[chain][address] = height
+		}
+	}
+
+	return cursors
+}
+
+// Run indexes transfers of all wallets of chains with transfer metrics enabled
+// up to the latest height, or as many heights as allowed per run, once, and saves the cursors.
+func (i *Indexer) Run(ctx context.Context) {
+	appConfig := i.GetConfig()
+
+	var wg sync.WaitGroup
+
+	for _, chain := range appConfig.Chains {
+		if !chain.TransferMetrics {
+			continue
+		}
+
+		wg.Add(1)
+		go func(chain config.Chain) {
+			defer wg.Done()
+			i.RunChain(ctx, chain, appConfig.TransfersConfig)
+		}(chain)
+	}
+
+	wg.Wait()
+
+	i.SaveCursors()
+}
+
+func (i *Indexer) RunChain(ctx context.Context, chain config.Chain, transfersConfig config.TransfersConfig) {
+	chainCtx, span := i.Tracer.Start(ctx, "Indexing transfers")
+	span.SetAttributes(attribute.String("chain", chain.Name))
+	defer span.End()
+
+	rpc := i.RPCs.Get(chain)
+
+	latestBlock, _, err := rpc.GetLatestBlock(chainCtx)
+	if err != nil {
+		i.Logger.Error().Err(err).Str("chain", chain.Name).Msg("Error querying latest block")
+		return
+	}
+
+	latestHeight, err := strconv.ParseInt(latestBlock.Block.Header.Height, 10, 64)
+	if err != nil {
+		i.Logger.Error().Err(err).Str("chain", chain.Name).Msg("Error parsing latest block height")
+		return
+	}
+
+	groups := getGroups(chain)
+
+	var wg sync.WaitGroup
+
+	for _, wallet := range chain.Wallets {
+		i.Mutex.Lock()
+		cursor, found := i.Cursors[chain.Name][wallet.Address]
+		if !found {
+			if i.Cursors[chain.Name] == nil {
+				i.Cursors[chain.Name] = make(map[string]int64)
+			}
+
+			i.Cursors[chain.Name][wallet.Address] = latestHeight
+		}
+		i.Mutex.Unlock()
+
+		if !found || cursor >= latestHeight {
+			continue
+		}
+
+		toHeight := min(latestHeight, cursor+transfersConfig.MaxHeightsPerRun)
+
+		wg.Add(1)
+		go func(wallet config.Wallet, cursor int64) {
+			defer wg.Done()
+
+			sent, received, err := i.indexWallet(
+				chainCtx,
+				rpc,
+				chain,
+				groups,
+				wallet.Address,
+				cursor+1,
+				toHeight,
+				transfersConfig.PageSize,
+			)
+			if err != nil {
+				// the cursor is not moved, so the same heights are indexed again next time
+				i.Logger.Error().
+					Err(err).
+					Str("chain", chain.Name).
+					Str("wallet", wallet.Address).
+					Msg("Error indexing transfers")
+				return
+			}
+
+			i.Mutex.Lock()
+			defer i.Mutex.Unlock()
+
+			for key, amount := range sent {
+				i.Sent[key] += amount
+			}
+
+			for key, amount := range received {
+				i.Received[key] += amount
+			}
+
+			i.Cursors[chain.Name][wallet.Address] = toHeight
+		}(wallet, cursor)
+	}
+
+	wg.Wait()
+}
+
+// indexWallet returns tokens the address sent and received within the heights range.
+// Both directions should succeed to be counted, so a failure of either one
+// won't make the other one be counted twice when retried.
+func (i *Indexer) indexWallet(
+	ctx context.Context,
+	rpc *tendermint.RPC,
+	chain config.Chain,
+	groups map[string]string,
+	address string,
+	fromHeight int64,
+	toHeight int64,
+	pageSize int,
+) (map[Key]float64, map[Key]float64, error) {
+	totals := map[string]map[Key]float64{
+		RoleSender:    make(map[Key]float64),
+		RoleRecipient: make(map[Key]float64),
+	}
+
+	for role, roleTotals := range totals {
+		transfers, err := i.getTransfers(ctx, rpc, address, role, fromHeight, toHeight, pageSize)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, transfer := range transfers {
+			counterparty := transfer.Recipient
+			if role == RoleRecipient {
+				counterparty = transfer.Sender
+			}
+
+			counterpartyGroup, ok := groups[counterparty]
+			if !ok {
+				counterpartyGroup = CounterpartyGroupExternal
+			}
+
+			for _, coin := range transfer.Amount {
+				denom := coin.Denom
+				amount := coin.Amount.MustFloat64()
+
+				if denomInfo, found := chain.FindDenomByName(coin.Denom); found {
+					denom = denomInfo.GetName()
+					amount /= math.Pow10(denomInfo.DenomExponent)
+				}
+
+				roleTotals[Key{
+					Chain:             chain.Name,
+					Address:           address,
+					Denom:             denom,
+					CounterpartyGroup: counterpartyGroup,
+				}] += amount
+			}
+		}
+	}
+
+	return totals[RoleSender], totals[RoleRecipient], nil
+}
+
+// getTransfers pages through transactions with transfer events where the address has the given role,
+// and returns transfers from these events where it has this role. Multi-send outputs have no sender
+// attribute, so tokens the address sends with a multi-send are not found, only the ones it receives.
+func (i *Indexer) getTransfers(
+	ctx context.Context,
+	rpc *tendermint.RPC,
+	address string,
+	role string,
+	fromHeight int64,
+	toHeight int64,
+	pageSize int,
+) ([]Transfer, error) {
+	transfers := []Transfer{}
+	seen := make(map[string]bool)
+
+	for page := 1; ; page++ {
+		response, _, err := rpc.GetTransfers(address, role, fromHeight, toHeight, page, pageSize, ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tx := range response.TxResponses {
+			// nodes not supporting paging return the same page every time
+			if seen[tx.TxHash] {
+				return nil, fmt.Errorf("transaction %s is returned twice, paging is not supported", tx.TxHash)
+			}
+
+			seen[tx.TxHash] = true
+
+			txTransfers, err := ParseTransfers(tx.GetEvents())
+			if err != nil {
+				return nil, fmt.Errorf("error parsing transfers of transaction %s: %s", tx.TxHash, err)
+			}
+
+			for _, transfer := range txTransfers {
+				if role == RoleSender && transfer.Sender == address ||
+					role == RoleRecipient && transfer.Recipient == address {
+					transfers = append(transfers, transfer)
+				}
+			}
+		}
+
+		// nodes cap the page size, so a page can be shorter than requested
+		// and only the total or an empty page tells that all of them are read
+		total, totalErr := strconv.Atoi(response.GetTotal())
+
+		if len(response.TxResponses) == 0 {
+			if totalErr == nil && len(seen) < total {
+				return nil, fmt.Errorf("page %d is empty, but only %d of %d transactions are read", page, len(seen), total)
+			}
+
+			return transfers, nil
+		}
+
+		if totalErr == nil && len(seen) >= total {
+			return transfers, nil
+		}
+	}
+}
+
+// ParseTransfers returns transfers from the transfer events of a transaction. Older versions
+// merge all the transfers of a message into one event with repeated attributes,
+// so a transfer is complete once it has its sender, recipient and amount set.
+// Multi-send outputs have no sender, they are sent by the sender of the message.
+// The transaction fee is paid with a transfer too, it's left out, as it's not sent to anyone.
+func ParseTransfers(events []types.Event) ([]Transfer, error) {
+	transfers := []Transfer{}
+	fees := getFees(events)
+
+	var messageSender string
+
+	for _, event := range events {
+		if event.Type == "message" {
+			for _, attribute := range event.Attributes {
+				if attribute.Key == "sender" {
+					messageSender = attribute.Value
+				}
+			}
+
+			continue
+		}
+
+		if event.Type != "transfer" {
+			continue
+		}
+
+		var transfer Transfer
+		var amount string
+
+		complete := func() {
+			if transfer.Sender == "" {
+				transfer.Sender = messageSender
+			}
+
+			if fee, ok := fees[transfer.Sender]; ok && fee == amount {
+				delete(fees, transfer.Sender)
+			} else {
+				transfers = append(transfers, transfer)
+			}
+
+			transfer = Transfer{}
+			amount = ""
+		}
+
+		for _, attribute := range event.Attributes {
+			switch attribute.Key {
+			case "sender":
+				transfer.Sender = attribute.Value
+			case "recipient":
+				// a new recipient after a complete one without a sender is the next multi-send output
+				if transfer.Recipient != "" && amount != "" {
+					complete()
+				}
+
+				transfer.Recipient = attribute.Value
+			case "amount":
+				coins, err := ParseCoins(attribute.Value)
+				if err != nil {
+					return nil, err
+				}
+
+				transfer.Amount = coins
+				amount = attribute.Value
+			default:
+				continue
+			}
+
+			if transfer.Sender != "" && transfer.Recipient != "" && amount != "" {
+				complete()
+			}
+		}
+
+		if transfer.Recipient != "" && amount != "" {
+			complete()
+		}
+	}
+
+	return transfers, nil
+}
+
+// getFees returns the fees of a transaction per fee payer, from the tx events
+// the ante handler emits. Older versions don't return these events, nor the fee transfers.
+func getFees(events []types.Event) map[string]string {
+	fees := make(map[string]string)
+
+	for _, event := range events {
+		if event.Type != "tx" {
+			continue
+		}
+
+		var fee, feePayer string
+
+		for _, attribute := range event.Attributes {
+			switch attribute.Key {
+			case "fee":
+				fee = attribute.Value
+			case "fee_payer":
+				feePayer = attribute.Value
+			}
+		}
+
+		if fee != "" && feePayer != "" {
+			fees[feePayer] = fee
+		}
+	}
+
+	return fees
+}
+
+// ParseCoins parses a list of coins like "100uatom,5ibc/27394FB0".
+func ParseCoins(value string) (types.Balances, error) {
+	coins := types.Balances{}
+
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		matches := coinRegexp.FindStringSubmatch(part)
+		if matches == nil {
+			return nil, fmt.Errorf("invalid coin: %s", part)
+		}
+
+		amount, err := cosmosMath.LegacyNewDecFromStr(matches[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid coin amount: %s", part)
+		}
+
+		coins = append(coins, types.Balance{Denom: matches[2], Amount: amount})
+	}
+
+	if len(coins) == 0 {
+		return nil, errors.New("no coins")
+	}
+
+	return coins, nil
+}
+
+// getGroups returns groups of the addresses listed in the chain config.
+func getGroups(chain config.Chain) map[string]string {
+	groups := make(map[string]string)
+
+	for _, wallet := range chain.Wallets {
+		groups[wallet.Address] = wallet.Group
+	}
+
+	for _, application := range chain.Applications {
+		if _, ok := groups[application.Address]; !ok {
+			groups[application.Address] = application.Group
+		}
+	}
+
+	for _, supplier := range chain.Suppliers {
+		if _, ok := groups[supplier.Address]; !ok {
+			groups[supplier.Address] = supplier.Group
+		}
+	}
+
+	return groups
+}
+
+// Querier exports the counters of the indexer for the chains and wallets of a config,
+// which can be a filtered copy of the app config, so the indexer state is shared
+// by all the scrapes, but each of them only gets its own wallets counters.
+type Querier struct {
+	Config  *config.Config
+	Indexer *Indexer
+	Tracer  trace.Tracer
+}
+
+func NewQuerier(appConfig *config.Config, indexer *Indexer, tracer trace.Tracer) *Querier {
+	return &Querier{
+		Config:  appConfig,
+		Indexer: indexer,
+		Tracer:  tracer,
+	}
+}
+
+func (q *Querier) Name() string {
+	return "transfers"
+}
+
+func (q *Querier) GetMetrics(ctx context.Context) ([]prometheus.Collector, []types.QueryInfo) {
+	_, span := q.Tracer.Start(ctx, "Getting transfers metrics")
+	defer span.End()
+
+	appConfig := q.Config
+	customLabelNames := appConfig.GetLabelNames()
+
+	labelNames := append(slices.Clone(config.TransferLabelNames), customLabelNames...)
+
+	sentCounter := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cosmos_wallets_exporter_transfers_sent_total",
+			Help: "Tokens a wallet sent since the exporter start, per counterparty group (in tokens)",
+		},
+		labelNames,
+	)
+
+	receivedCounter := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cosmos_wallets_exporter_transfers_received_total",
+			Help: "Tokens a wallet received since the exporter start, per counterparty group (in tokens)",
+		},
+		labelNames,
+	)
+
+	q.Indexer.Mutex.Lock()
+	defer q.Indexer.Mutex.Unlock()
+
+	for _, chain := range appConfig.Chains {
+		if !chain.TransferMetrics {
+			continue
+		}
+
+		wallets := make(map[string]config.Wallet, len(chain.Wallets))
+		for _, wallet := range chain.Wallets {
+			wallets[wallet.Address] = wallet
+		}
+
+		// wallets removed from the config are not exported anymore
+		setCounters := func(counter *prometheus.CounterVec, totals map[Key]float64) {
+			for key, amount := range totals {
+				wallet, ok := wallets[key.Address]
+				if key.Chain != chain.Name || !ok {
+					continue
+				}
+
+				labels := prometheus.Labels{
+					"chain":              chain.Name,
+					"address":            wallet.Address,
+					"name":               wallet.Name,
+					"group":              wallet.Group,
+					"denom":              key.Denom,
+					"counterparty_group": key.CounterpartyGroup,
+				}
+
+				customLabels := config.MergeLabels(chain.Labels, wallet.Labels)
+				for _, name := range customLabelNames {
+					labels[name] = customLabels[name]
+				}
+
+				counter.With(labels).Add(amount)
+			}
+		}
+
+		setCounters(sentCounter, q.Indexer.Sent)
+		setCounters(receivedCounter, q.Indexer.Received)
+	}
+
+	return []prometheus.Collector{sentCounter, receivedCounter}, []types.QueryInfo{}
+}
@@ -0,0 +1,437 @@
+package transfers
+
+import (
+	"context"
+	"errors"
+	"main/assets"
+	configPkg "main/pkg/config"
+	loggerPkg "main/pkg/logger"
+	"main/pkg/state"
+	"main/pkg/tendermint"
+	"main/pkg/tracing"
+	"main/pkg/types"
+	"path/filepath"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	sentURL     = `=~^https://example.com/cosmos/tx/v1beta1/txs\?events=transfer\.sender%3D%27address%27`
+	receivedURL = `=~^https://example.com/cosmos/tx/v1beta1/txs\?events=transfer\.recipient%3D%27address%27`
+)
+
+func TestParseCoins(t *testing.T) {
+	t.Parallel()
+
+	coins, err := ParseCoins("100uatom,5.5ibc/27394FB0")
+	require.NoError(t, err)
+	require.Len(t, coins, 2)
+	assert.Equal(t, "uatom", coins[0].Denom)
+	assert.InDelta(t, 100, coins[0].Amount.MustFloat64(), 0.001)
+	assert.Equal(t, "ibc/27394FB0", coins[1].Denom)
+	assert.InDelta(t, 5.5, coins[1].Amount.MustFloat64(), 0.001)
+
+	_, err = ParseCoins("uatom")
+	require.ErrorContains(t, err, "invalid coin: uatom")
+
+	_, err = ParseCoins("")
+	require.ErrorContains(t, err, "no coins")
+}
+
+func TestParseTransfers(t *testing.T) {
+	t.Parallel()
+
+	transfers, err := ParseTransfers([]types.Event{
+		{Type: "message", Attributes: []types.EventAttribute{{Key: "sender", Value: "a"}}},
+		{Type: "transfer", Attributes: []types.EventAttribute{
+			{Key: "recipient", Value: "b"},
+			{Key: "sender", Value: "a"},
+			{Key: "amount", Value: "1uatom"},
+			{Key: "recipient", Value: "c"},
+			{Key: "sender", Value: "a"},
+			{Key: "amount", Value: "2uatom"},
+		}},
+	})
+	require.NoError(t, err)
+	require.Len(t, transfers, 2)
+	assert.Equal(t, "b", transfers[0].Recipient)
+	assert.Equal(t, "c", transfers[1].Recipient)
+	assert.InDelta(t, 2, transfers[1].Amount[0].Amount.MustFloat64(), 0.001)
+
+	_, err = ParseTransfers([]types.Event{{Type: "transfer", Attributes: []types.EventAttribute{
+		{Key: "amount", Value: "invalid"},
+	}}})
+	require.Error(t, err)
+}
+
+func TestParseTransfersMultiSend(t *testing.T) {
+	t.Parallel()
+
+	// older versions merge the multi-send outputs into one event without senders
+	transfers, err := ParseTransfers([]types.Event{
+		{Type: "message", Attributes: []types.EventAttribute{{Key: "sender", Value: "a"}}},
+		{Type: "transfer", Attributes: []types.EventAttribute{
+			{Key: "recipient", Value: "b"},
+			{Key: "amount", Value: "1uatom"},
+			{Key: "recipient", Value: "c"},
+			{Key: "amount", Value: "2uatom"},
+		}},
+	})
+	require.NoError(t, err)
+	require.Len(t, transfers, 2)
+	assert.Equal(t, Transfer{Sender: "a", Recipient: "b", Amount: transfers[0].Amount}, transfers[0])
+	assert.Equal(t, Transfer{Sender: "a", Recipient: "c", Amount: transfers[1].Amount}, transfers[1])
+	assert.InDelta(t, 2, transfers[1].Amount[0].Amount.MustFloat64(), 0.001)
+}
+
+func TestParseTransfersFee(t *testing.T) {
+	t.Parallel()
+
+	transfers, err := ParseTransfers([]types.Event{
+		{Type: "transfer", Attributes: []types.EventAttribute{
+			{Key: "recipient", Value: "fee_collector"},
+			{Key: "sender", Value: "a"},
+			{Key: "amount", Value: "5uatom"},
+		}},
+		{Type: "tx", Attributes: []types.EventAttribute{
+			{Key: "fee", Value: "5uatom"},
+			{Key: "fee_payer", Value: "a"},
+		}},
+		{Type: "transfer", Attributes: []types.EventAttribute{
+			{Key: "recipient", Value: "b"},
+			{Key: "sender", Value: "a"},
+			{Key: "amount", Value: "5uatom"},
+		}},
+	})
+	require.NoError(t, err)
+
+	// only the first transfer of the fee amount from the fee payer is the fee
+	require.Len(t, transfers, 1)
+	assert.Equal(t, "b", transfers[0].Recipient)
+}
+
+func getIndexer(config *configPkg.Config) *Indexer {
+	return getIndexerWithState(config, state.NewManager(""))
+}
+
+func getIndexerWithState(config *configPkg.Config, stateManager *state.Manager) *Indexer {
+	tracer := tracing.InitNoopTracer()
+	logger := loggerPkg.GetNopLogger()
+
+	return NewIndexer(
+		func() *configPkg.Config { return config },
+		tendermint.NewRegistry(config, *logger, tracer),
+		stateManager,
+		*logger,
+		tracer,
+	)
+}
+
+func getTransfersConfig() *configPkg.Config {
+	return &configPkg.Config{
+		TransfersConfig: configPkg.TransfersConfig{Interval: "1m", PageSize: 100, MaxHeightsPerRun: 10000},
+		Chains: []configPkg.Chain{{
+			Name:        "chain",
+			LCDEndpoint: "https://example.com",
+			Denoms:      []configPkg.DenomInfo{{Denom: "uatom", DisplayDenom: "atom", DenomExponent: 6}},
+			Wallets: []configPkg.Wallet{
+				{Address: "address", Name: "treasury", Group: "treasury"},
+				{Address: "relayer", Name: "relayer", Group: "relayers"},
+			},
+			TransferMetrics: true,
+		}},
+	}
+}
+
+//nolint:paralleltest // disabled due to httpmock usage
+func TestIndexerFirstRun(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder(
+		"GET",
+		"https://example.com/cosmos/base/tendermint/v1beta1/blocks/latest",
+		httpmock.NewBytesResponder(200, assets.GetBytesOrPanic("latest-block.json")),
+	)
+
+	indexer := getIndexer(getTransfersConfig())
+	indexer.Run(context.Background())
+
+	// without cursors, it starts from the latest height
+	assert.Equal(t, 1, httpmock.GetTotalCallCount())
+	assert.Equal(t, map[string]map[string]int64{
+		"chain": {"address": 12345, "relayer": 12345},
+	}, indexer.GetCursors())
+	assert.Empty(t, indexer.Sent)
+	assert.Empty(t, indexer.Received)
+}
+
+//nolint:paralleltest // disabled due to httpmock usage
+func TestIndexerOk(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder(
+		"GET",
+		"https://example.com/cosmos/base/tendermint/v1beta1/blocks/latest",
+		httpmock.NewBytesResponder(200, assets.GetBytesOrPanic("latest-block.json")),
+	)
+	httpmock.RegisterResponder(
+		"GET",
+		sentURL,
+		httpmock.NewBytesResponder(200, assets.GetBytesOrPanic("transfers-sent.json")),
+	)
+	httpmock.RegisterResponder(
+		"GET",
+		receivedURL,
+		httpmock.NewBytesResponder(200, assets.GetBytesOrPanic("transfers-received.json")),
+	)
+
+	indexer := getIndexer(getTransfersConfig())
+	indexer.RestoreCursors(map[string]map[string]int64{"chain": {"address": 12000}})
+
+	indexer.Run(context.Background())
+	// running again up to the same height does not count anything twice
+	indexer.Run(context.Background())
+
+	assert.Equal(t, 4, httpmock.GetTotalCallCount())
+	assert.Equal(t, map[string]map[string]int64{
+		"chain": {"address": 12345, "relayer": 12345},
+	}, indexer.GetCursors())
+
+	querier := NewQuerier(indexer.GetConfig(), indexer, tracing.InitNoopTracer())
+	assert.Equal(t, "transfers", querier.Name())
+
+	metrics, queries := querier.GetMetrics(context.Background())
+	assert.Empty(t, queries)
+	require.Len(t, metrics, 2)
+
+	sentCounter, ok := metrics[0].(*prometheus.CounterVec)
+	require.True(t, ok)
+	receivedCounter, ok := metrics[1].(*prometheus.CounterVec)
+	require.True(t, ok)
+
+	getLabels := func(denom string, counterpartyGroup string) prometheus.Labels {
+		return prometheus.Labels{
+			"chain":              "chain",
+			"address":            "address",
+			"name":               "treasury",
+			"group":              "treasury",
+			"denom":              denom,
+			"counterparty_group": counterpartyGroup,
+		}
+	}
+
+	// the fee is not counted as sent
+	assert.Equal(t, 2, testutil.CollectAndCount(sentCounter))
+	assert.InDelta(t, 1, testutil.ToFloat64(sentCounter.With(getLabels("atom", "relayers"))), 0.0001)
+	assert.InDelta(t, 10, testutil.ToFloat64(sentCounter.With(getLabels("ibc/27394FB0", "relayers"))), 0.0001)
+
+	// transfers to the wallet are only counted from the recipient query results
+	assert.Equal(t, 1, testutil.CollectAndCount(receivedCounter))
+	assert.InDelta(t, 5, testutil.ToFloat64(receivedCounter.With(getLabels("atom", "external"))), 0.0001)
+
+	// a scrape of other chains or groups does not get the counters of these wallets
+	filtered := indexer.GetConfig().Filter(nil, []string{"relayers"})
+	metrics, _ = NewQuerier(filtered, indexer, tracing.InitNoopTracer()).GetMetrics(context.Background())
+	require.Len(t, metrics, 2)
+	assert.Equal(t, 0, testutil.CollectAndCount(metrics[0]))
+	assert.Equal(t, 0, testutil.CollectAndCount(metrics[1]))
+}
+
+//nolint:paralleltest // disabled due to httpmock usage
+func TestIndexerSavesCursors(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder(
+		"GET",
+		"https://example.com/cosmos/base/tendermint/v1beta1/blocks/latest",
+		httpmock.NewBytesResponder(200, assets.GetBytesOrPanic("latest-block.json")),
+	)
+	httpmock.RegisterResponder(
+		"GET",
+		sentURL,
+		httpmock.NewBytesResponder(200, assets.GetBytesOrPanic("transfers-sent.json")),
+	)
+	httpmock.RegisterResponder(
+		"GET",
+		receivedURL,
+		httpmock.NewBytesResponder(200, assets.GetBytesOrPanic("transfers-received.json")),
+	)
+
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	config := getTransfersConfig()
+	config.TransfersConfig.MaxHeightsPerRun = 100
+
+	indexer := getIndexerWithState(config, state.NewManager(path))
+	indexer.RestoreCursors(map[string]map[string]int64{"chain": {"address": 12000}})
+	indexer.Run(context.Background())
+
+	// only up to max-heights-per-run heights are indexed at once
+	assert.Equal(t, int64(12100), indexer.GetCursors()["chain"]["address"])
+
+	// the cursors are saved right after the run
+	stateManager := state.NewManager(path)
+	require.NoError(t, stateManager.Load())
+
+	restored := getIndexerWithState(config, stateManager)
+	require.NoError(t, restored.LoadCursors())
+	assert.Equal(t, indexer.GetCursors(), restored.GetCursors())
+
+	indexer.Run(context.Background())
+	assert.Equal(t, int64(12200), indexer.GetCursors()["chain"]["address"])
+}
+
+//nolint:paralleltest // disabled due to httpmock usage
+func TestIndexerFail(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder(
+		"GET",
+		"https://example.com/cosmos/base/tendermint/v1beta1/blocks/latest",
+		httpmock.NewBytesResponder(200, assets.GetBytesOrPanic("latest-block.json")),
+	)
+	httpmock.RegisterResponder(
+		"GET",
+		sentURL,
+		httpmock.NewBytesResponder(200, assets.GetBytesOrPanic("transfers-sent.json")),
+	)
+	httpmock.RegisterResponder(
+		"GET",
+		receivedURL,
+		httpmock.NewErrorResponder(errors.New("custom error")),
+	)
+
+	indexer := getIndexer(getTransfersConfig())
+	indexer.RestoreCursors(map[string]map[string]int64{"chain": {"address": 12000}})
+	indexer.Run(context.Background())
+
+	// nothing is counted and the cursor is not moved, so it's retried next time
+	assert.Empty(t, indexer.Sent)
+	assert.Empty(t, indexer.Received)
+	assert.Equal(t, int64(12000), indexer.GetCursors()["chain"]["address"])
+}
+
+//nolint:paralleltest // disabled due to httpmock usage
+func TestIndexerNoPaging(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder(
+		"GET",
+		"https://example.com/cosmos/base/tendermint/v1beta1/blocks/latest",
+		httpmock.NewBytesResponder(200, assets.GetBytesOrPanic("latest-block.json")),
+	)
+	// a node ignoring the page param returns the same full page every time
+	httpmock.RegisterResponder(
+		"GET",
+		sentURL,
+		httpmock.NewStringResponder(200, `{"tx_responses":[{"txhash":"SAME","events":[]}],"total":"5"}`),
+	)
+	httpmock.RegisterResponder(
+		"GET",
+		receivedURL,
+		httpmock.NewBytesResponder(200, assets.GetBytesOrPanic("transfers-received.json")),
+	)
+
+	config := getTransfersConfig()
+	config.TransfersConfig.PageSize = 1
+
+	indexer := getIndexer(config)
+	indexer.RestoreCursors(map[string]map[string]int64{"chain": {"address": 12000}})
+	indexer.Run(context.Background())
+
+	assert.Empty(t, indexer.Sent)
+	assert.Empty(t, indexer.Received)
+	assert.Equal(t, int64(12000), indexer.GetCursors()["chain"]["address"])
+}
+
+//nolint:paralleltest // disabled due to httpmock usage
+func TestIndexerPaging(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder(
+		"GET",
+		"https://example.com/cosmos/base/tendermint/v1beta1/blocks/latest",
+		httpmock.NewBytesResponder(200, assets.GetBytesOrPanic("latest-block.json")),
+	)
+	httpmock.RegisterResponder(
+		"GET",
+		sentURL,
+		httpmock.NewStringResponder(200, `{"tx_responses":[],"total":"0"}`),
+	)
+
+	// the node caps the page size, so pages are shorter than requested
+	transfer := func(hash string) string {
+		return `{"txhash":"` + hash + `","events":[{"type":"transfer","attributes":[` +
+			`{"key":"recipient","value":"address"},{"key":"sender","value":"cosmos1external"},` +
+			`{"key":"amount","value":"1000000uatom"}]}]}`
+	}
+
+	httpmock.RegisterResponder(
+		"GET",
+		receivedURL+`.*&page=1&`,
+		httpmock.NewStringResponder(200, `{"tx_responses":[`+transfer("FIRST")+`],"total":"2"}`),
+	)
+	httpmock.RegisterResponder(
+		"GET",
+		receivedURL+`.*&page=2&`,
+		httpmock.NewStringResponder(200, `{"tx_responses":[`+transfer("SECOND")+`],"total":"2"}`),
+	)
+
+	indexer := getIndexer(getTransfersConfig())
+	indexer.RestoreCursors(map[string]map[string]int64{"chain": {"address": 12000}})
+	indexer.Run(context.Background())
+
+	assert.InDelta(t, 2, indexer.Received[Key{
+		Chain:             "chain",
+		Address:           "address",
+		Denom:             "atom",
+		CounterpartyGroup: CounterpartyGroupExternal,
+	}], 0.0001)
+	assert.Equal(t, int64(12345), indexer.GetCursors()["chain"]["address"])
+}
+
+//nolint:paralleltest // disabled due to httpmock usage
+func TestIndexerPageMissing(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	httpmock.RegisterResponder(
+		"GET",
+		"https://example.com/cosmos/base/tendermint/v1beta1/blocks/latest",
+		httpmock.NewBytesResponder(200, assets.GetBytesOrPanic("latest-block.json")),
+	)
+	httpmock.RegisterResponder(
+		"GET",
+		sentURL,
+		httpmock.NewStringResponder(200, `{"tx_responses":[],"total":"0"}`),
+	)
+	httpmock.RegisterResponder(
+		"GET",
+		receivedURL+`.*&page=1&`,
+		httpmock.NewStringResponder(200, `{"tx_responses":[{"txhash":"FIRST","events":[]}],"total":"2"}`),
+	)
+	httpmock.RegisterResponder(
+		"GET",
+		receivedURL+`.*&page=2&`,
+		httpmock.NewStringResponder(200, `{"tx_responses":[],"total":"2"}`),
+	)
+
+	indexer := getIndexer(getTransfersConfig())
+	indexer.RestoreCursors(map[string]map[string]int64{"chain": {"address": 12000}})
+	indexer.Run(context.Background())
+
+	// the range is not fully read, so the cursor is not moved
+	assert.Empty(t, indexer.Received)
+	assert.Equal(t, int64(12000), indexer.GetCursors()["chain"]["address"])
+}
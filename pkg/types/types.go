@@ -20,6 +20,14 @@ type BalanceResponse struct {
 	Balances Balances `json:"balances"`
 }
 
+type CW20Balance struct {
+	Balance math.LegacyDec `json:"balance"`
+}
+
+type CW20BalanceResponse struct {
+	Data CW20Balance `json:"data"`
+}
+
 type WalletBalanceEntry struct {
 	Chain    string
 	Success  bool
@@ -116,8 +124,8 @@ type RevShareMetadata struct {
 	SupplierOwnerAddr    string
 	SupplierName         string
 	ServiceID            string
-	RevSharePercentage   string
 	DetailedMetrics      bool
+	Labels               map[string]string
 }
 
 type RevShareAggregateKey struct {
@@ -128,13 +136,260 @@ type RevShareAggregateKey struct {
 	RevShareAddress      string
 }
 
+type Coin struct {
+	Amount string `json:"amount"`
+	Denom  string `json:"denom"`
+}
+
+type SharedParams struct {
+	NumBlocksPerSession                string `json:"num_blocks_per_session"`
+	GracePeriodEndOffsetBlocks         string `json:"grace_period_end_offset_blocks"`
+	ClaimWindowOpenOffsetBlocks        string `json:"claim_window_open_offset_blocks"`
+	ClaimWindowCloseOffsetBlocks       string `json:"claim_window_close_offset_blocks"`
+	ProofWindowOpenOffsetBlocks        string `json:"proof_window_open_offset_blocks"`
+	ProofWindowCloseOffsetBlocks       string `json:"proof_window_close_offset_blocks"`
+	SupplierUnbondingPeriodSessions    string `json:"supplier_unbonding_period_sessions"`
+	ApplicationUnbondingPeriodSessions string `json:"application_unbonding_period_sessions"`
+	GatewayUnbondingPeriodSessions     string `json:"gateway_unbonding_period_sessions"`
+	ComputeUnitsToTokensMultiplier     string `json:"compute_units_to_tokens_multiplier"`
+	ComputeUnitCostGranularity         string `json:"compute_unit_cost_granularity"`
+}
+
+type SharedParamsResponse struct {
+	Params SharedParams `json:"params"`
+}
+
+type SessionParams struct {
+	NumSuppliersPerSession string `json:"num_suppliers_per_session"`
+}
+
+type SessionParamsResponse struct {
+	Params SessionParams `json:"params"`
+}
+
+type ProofParams struct {
+	ProofRequestProbability   float64 `json:"proof_request_probability"`
+	ProofRequirementThreshold *Coin   `json:"proof_requirement_threshold"`
+	ProofMissingPenalty       *Coin   `json:"proof_missing_penalty"`
+	ProofSubmissionFee        *Coin   `json:"proof_submission_fee"`
+}
+
+type ProofParamsResponse struct {
+	Params ProofParams `json:"params"`
+}
+
+type MintAllocationPercentages struct {
+	Dao         float64 `json:"dao"`
+	Proposer    float64 `json:"proposer"`
+	Supplier    float64 `json:"supplier"`
+	SourceOwner float64 `json:"source_owner"`
+	Application float64 `json:"application"`
+}
+
+type TokenomicsParams struct {
+	MintAllocationPercentages MintAllocationPercentages `json:"mint_allocation_percentages"`
+	DaoRewardAddress          string                    `json:"dao_reward_address"`
+	GlobalInflationPerClaim   float64                   `json:"global_inflation_per_claim"`
+}
+
+type TokenomicsParamsResponse struct {
+	Params TokenomicsParams `json:"params"`
+}
+
+type RelayMiningDifficulty struct {
+	ServiceID    string `json:"service_id"`
+	BlockHeight  string `json:"block_height"`
+	NumRelaysEma string `json:"num_relays_ema"`
+	TargetHash   []byte `json:"target_hash"`
+}
+
+type RelayMiningDifficultyResponse struct {
+	RelayMiningDifficulty RelayMiningDifficulty `json:"relay_mining_difficulty"`
+}
+
+type BlockHeader struct {
+	ChainID string    `json:"chain_id"`
+	Height  string    `json:"height"`
+	Time    time.Time `json:"time"`
+}
+
+type Block struct {
+	Header BlockHeader `json:"header"`
+}
+
+type LatestBlockResponse struct {
+	Block Block `json:"block"`
+}
+
+type SyncingResponse struct {
+	Syncing bool `json:"syncing"`
+}
+
+type Pagination struct {
+	NextKey string `json:"next_key"`
+	Total   string `json:"total"`
+}
+
+type SessionHeader struct {
+	ApplicationAddress      string `json:"application_address"`
+	ServiceID               string `json:"service_id"`
+	SessionID               string `json:"session_id"`
+	SessionStartBlockHeight string `json:"session_start_block_height"`
+	SessionEndBlockHeight   string `json:"session_end_block_height"`
+}
+
+type Claim struct {
+	SupplierOperatorAddress string        `json:"supplier_operator_address"`
+	SessionHeader           SessionHeader `json:"session_header"`
+	RootHash                []byte        `json:"root_hash"`
+}
+
+type ClaimsResponse struct {
+	Claims     []Claim    `json:"claims"`
+	Pagination Pagination `json:"pagination"`
+}
+
+type Proof struct {
+	SupplierOperatorAddress string        `json:"supplier_operator_address"`
+	SessionHeader           SessionHeader `json:"session_header"`
+}
+
+type ProofsResponse struct {
+	Proofs     []Proof    `json:"proofs"`
+	Pagination Pagination `json:"pagination"`
+}
+
+const (
+	AccountTypeContinuousVesting = "/cosmos.vesting.v1beta1.ContinuousVestingAccount"
+	AccountTypeDelayedVesting    = "/cosmos.vesting.v1beta1.DelayedVestingAccount"
+	AccountTypePeriodicVesting   = "/cosmos.vesting.v1beta1.PeriodicVestingAccount"
+	AccountTypePermanentLocked   = "/cosmos.vesting.v1beta1.PermanentLockedAccount"
+)
+
+type BaseAccount struct {
+	Address  string `json:"address"`
+	Sequence string `json:"sequence"`
+}
+
+type BaseVestingAccount struct {
+	BaseAccount      *BaseAccount `json:"base_account"`
+	OriginalVesting  Balances     `json:"original_vesting"`
+	DelegatedFree    Balances     `json:"delegated_free"`
+	DelegatedVesting Balances     `json:"delegated_vesting"`
+	EndTime          string       `json:"end_time"`
+}
+
+type VestingPeriod struct {
+	Length string   `json:"length"`
+	Amount Balances `json:"amount"`
+}
+
+// Account is an account returned by the auth module. Only the sequence and vesting
+// accounts fields are parsed, as other fields do not have anything to export.
+type Account struct {
+	Type string `json:"@type"`
+	// base accounts have their fields at the top level, other accounts embed a base account
+	Sequence           string              `json:"sequence"`
+	BaseAccount        *BaseAccount        `json:"base_account"`
+	BaseVestingAccount *BaseVestingAccount `json:"base_vesting_account"`
+	StartTime          string              `json:"start_time"`
+	VestingPeriods     []VestingPeriod     `json:"vesting_periods"`
+}
+
+// GetSequence returns the account sequence, wherever it is for this account type.
+func (a Account) GetSequence() (string, bool) {
+	switch {
+	case a.BaseAccount != nil:
+		return a.BaseAccount.Sequence, true
+	case a.BaseVestingAccount != nil && a.BaseVestingAccount.BaseAccount != nil:
+		return a.BaseVestingAccount.BaseAccount.Sequence, true
+	case a.Sequence != "":
+		return a.Sequence, true
+	default:
+		return "", false
+	}
+}
+
+func (a Account) IsVesting() bool {
+	switch a.Type {
+	case AccountTypeContinuousVesting,
+		AccountTypeDelayedVesting,
+		AccountTypePeriodicVesting,
+		AccountTypePermanentLocked:
+		return a.BaseVestingAccount != nil
+	default:
+		return false
+	}
+}
+
+type AccountResponse struct {
+	Account Account `json:"account"`
+}
+
+type EventAttribute struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type Event struct {
+	Type       string           `json:"type"`
+	Attributes []EventAttribute `json:"attributes"`
+}
+
+type TxLog struct {
+	Events []Event `json:"events"`
+}
+
+type TxResponse struct {
+	Height    string  `json:"height"`
+	TxHash    string  `json:"txhash"`
+	Timestamp string  `json:"timestamp"`
+	Logs      []TxLog `json:"logs"`
+	Events    []Event `json:"events"`
+}
+
+// GetEvents returns the tx events. Older versions have them in logs, as the events field
+// has base64-encoded attributes there, and newer versions have no logs at all.
+func (r TxResponse) GetEvents() []Event {
+	events := []Event{}
+	for _, log := range r.Logs {
+		events = append(events, log.Events...)
+	}
+
+	if len(events) > 0 {
+		return events
+	}
+
+	return r.Events
+}
+
+type TxsResponse struct {
+	TxResponses []TxResponse `json:"tx_responses"`
+	Pagination  *Pagination  `json:"pagination"`
+	// the total count of matching transactions, older versions only return it in pagination
+	Total string `json:"total"`
+}
+
+func (r TxsResponse) GetTotal() string {
+	if r.Total == "" && r.Pagination != nil {
+		return r.Pagination.Total
+	}
+
+	return r.Total
+}
+
 type QueryInfo struct {
 	Chain    string
 	Success  bool
 	URL      string
 	Duration time.Duration
+	Height   int64
+	Cached   bool
+	TimedOut bool
+	Error    string
 }
 
 type Querier interface {
+	Name() string
 	GetMetrics(ctx context.Context) ([]prometheus.Collector, []QueryInfo)
 }
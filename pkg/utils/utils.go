@@ -1,11 +1,21 @@
 package utils
 
 import (
+	"errors"
+	"fmt"
 	"main/pkg/constants"
+	"math/big"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 )
 
+// RedactedURLPath replaces everything after the host in redacted URLs.
+const RedactedURLPath = "/<redacted>"
+
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
 func BoolToFloat64(b bool) float64 {
 	if b {
 		return 1
@@ -14,6 +24,92 @@ func BoolToFloat64(b bool) float64 {
 	return 0
 }
 
+// RedactURL returns the URL scheme and host only, dropping the credentials, path and query,
+// which can have API keys in them. Values that are not absolute URLs are redacted fully.
+func RedactURL(value string) string {
+	parsed, err := url.Parse(value)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return RedactedURLPath
+	}
+
+	return parsed.Scheme + "://" + parsed.Host + RedactedURLPath
+}
+
+// RedactURLCredentials returns the URL without its user info and query, which can have
+// credentials in them. Values that are not valid URLs are redacted fully.
+func RedactURLCredentials(value string) string {
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return RedactedURLPath
+	}
+
+	parsed.User = nil
+	parsed.RawQuery = ""
+	parsed.Fragment = ""
+
+	return parsed.String()
+}
+
+// GetBech32Prefix returns the prefix of a bech32 address, like cosmos for cosmos1...,
+// after checking its checksum. Only lowercase letters and digits are allowed in the prefix,
+// so a valid address is always safe to put into a URL path.
+func GetBech32Prefix(address string) (string, error) {
+	separator := strings.LastIndexByte(address, '1')
+	if separator < 1 || separator+7 > len(address) {
+		return "", errors.New("invalid bech32 address")
+	}
+
+	prefix := address[:separator]
+	values := make([]int, 0, len(prefix)*2+1+len(address)-separator-1)
+
+	for _, char := range prefix {
+		if (char < 'a' || char > 'z') && (char < '0' || char > '9') {
+			return "", fmt.Errorf("invalid bech32 prefix character: %q", char)
+		}
+
+		values = append(values, int(char>>5))
+	}
+
+	values = append(values, 0)
+
+	for _, char := range prefix {
+		values = append(values, int(char&31))
+	}
+
+	for _, char := range address[separator+1:] {
+		index := strings.IndexRune(bech32Charset, char)
+		if index < 0 {
+			return "", fmt.Errorf("invalid bech32 character: %q", char)
+		}
+
+		values = append(values, index)
+	}
+
+	if bech32Polymod(values) != 1 {
+		return "", errors.New("invalid bech32 checksum")
+	}
+
+	return prefix, nil
+}
+
+func bech32Polymod(values []int) int {
+	generator := [5]int{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	checksum := 1
+
+	for _, value := range values {
+		top := checksum >> 25
+		checksum = (checksum&0x1ffffff)<<5 ^ value
+
+		for index := range generator {
+			if (top>>index)&1 == 1 {
+				checksum ^= generator[index]
+			}
+		}
+	}
+
+	return checksum
+}
+
 func GetBlockHeightFromHeader(header http.Header) (int64, error) {
 	valueStr := header.Get(constants.HeaderBlockHeight)
 	if valueStr == "" {
@@ -27,3 +123,28 @@ func GetBlockHeightFromHeader(header http.Header) (int64, error) {
 
 	return value, nil
 }
+
+// GetRelayDifficultyMultiplier returns how many times harder it is to mine a relay
+// with the given target hash compared to the base difficulty (all bits set).
+func GetRelayDifficultyMultiplier(targetHash []byte) float64 {
+	if len(targetHash) == 0 {
+		return 0
+	}
+
+	target := new(big.Int).SetBytes(targetHash)
+	if target.Sign() == 0 {
+		return 0
+	}
+
+	baseline := new(big.Int).Sub(
+		new(big.Int).Lsh(big.NewInt(1), uint(len(targetHash)*8)),
+		big.NewInt(1),
+	)
+
+	multiplier, _ := new(big.Float).Quo(
+		new(big.Float).SetInt(baseline),
+		new(big.Float).SetInt(target),
+	).Float64()
+
+	return multiplier
+}